@@ -0,0 +1,80 @@
+package main
+
+import (
+	"application/migrations"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+)
+
+const usage = "usage: migrate [-driver postgres|sqlite] [-dsn connection-string] up|down|status"
+
+func main() {
+	driver := flag.String("driver", "postgres", "database driver: postgres or sqlite")
+	dsn := flag.String("dsn", os.Getenv("DATABASE_URL"), "connection string, or the database file for sqlite")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		log.Fatal(usage)
+	}
+
+	db, err := sqlx.Connect(*driver, *dsn)
+	if err != nil {
+		log.Fatalf("problem connecting to database, %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := run(migrator, flag.Arg(0)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(migrator *migrations.Migrator, command string) error {
+	switch command {
+	case "up":
+		applied, err := migrator.Up()
+		for _, m := range applied {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("database is up to date")
+		}
+		return err
+	case "down":
+		reverted, err := migrator.Down()
+		if err != nil {
+			return err
+		}
+		if reverted == nil {
+			fmt.Println("no migrations to revert")
+			return nil
+		}
+		fmt.Printf("reverted %04d_%s\n", reverted.Version, reverted.Name)
+		return nil
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, state)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q\n%s", command, usage)
+	}
+}
@@ -0,0 +1,376 @@
+package main
+
+import (
+	"application/poker"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	dbFileName     = "game.db.json"
+	sqliteFileName = "game.db"
+	eventsFileName = "game.events.jsonl"
+	auditFileName  = "game.audit.jsonl"
+)
+
+const usage = `usage: poker [flags] [command]
+
+commands:
+  play       play a game and record the winner (default)
+  earnings   print the money leaderboard
+  settle ID  print who owes whom after game ID
+  league     print the all-time standings
+  seasons    list the seasons
+  season ID  print the standings of season ID next to the all-time wins
+  wins NAME  list the wins of a player, reverted ones included
+  undo NAME  revert the last win of a player, needs -reason
+  revoke ID  revert win ID, needs -reason
+  deleted    list the deleted players
+  restore P  bring deleted player P back
+  purge      remove players deleted longer than -retention ago for good
+  audit      export the audit log as JSON lines
+
+Players are given by name or id. Every store keeps games, but only the
+sqlite and postgres stores keep money and seasons, so earnings, the buy-ins
+settle shares out and the seasons need one of them.
+
+flags:
+`
+
+func main() {
+	config := poker.StoreConfig{FilePath: dbFileName}
+	flag.StringVar(&config.Type, "store", "file", "player store to use: file, events, sqlite or postgres")
+	flag.StringVar(&config.DSN, "dsn", os.Getenv("DATABASE_URL"), "postgres connection string")
+	flag.StringVar(&config.SQLitePath, "sqlite", sqliteFileName, "sqlite database file")
+	flag.StringVar(&config.EventsPath, "events", eventsFileName, "event log for the events store")
+	scoring := flag.String("scoring", "wins", "league scoring rules: wins, top3, league, bounty or a JSON file")
+	var reversion poker.Reversion
+	flag.StringVar(&reversion.By, "by", os.Getenv("USER"), "who is making the change, kept with reverted wins and in the audit log")
+	flag.StringVar(&reversion.Reason, "reason", "", "why a win is reverted")
+	audit := flag.String("audit", auditFileName, "audit log of every change, empty to keep none")
+	retention := flag.Duration("retention", poker.DefaultRetention, "how long deleted players are kept before purge removes them")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	store, closeStore, err := poker.OpenPlayerStore(config)
+
+	if err != nil {
+		log.Fatalf("problem creating %s player store, %v ", config.Type, err)
+	}
+	defer closeStore()
+
+	switch command := flag.Arg(0); command {
+	case "", "play":
+		err = play(audited(store, *audit, reversion.By))
+	case "earnings":
+		err = earnings(store)
+	case "settle":
+		err = settle(store, flag.Arg(1))
+	case "league":
+		err = league(store, *scoring)
+	case "seasons":
+		err = seasons(store)
+	case "season":
+		err = season(store, flag.Arg(1))
+	case "wins":
+		err = wins(store, flag.Arg(1))
+	case "undo":
+		err = undo(store, flag.Arg(1), reversion)
+	case "revoke":
+		err = revoke(store, flag.Arg(1), reversion)
+	case "deleted":
+		err = deleted(store)
+	case "restore":
+		err = restore(store, flag.Arg(1), *audit, reversion.By)
+	case "purge":
+		err = purge(store, *retention, *audit, reversion.By)
+	case "audit":
+		err = exportAudit(*audit)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func play(store poker.PlayerStore) error {
+	game := poker.NewTexasHoldem(poker.BlindAlerterFunc(poker.StdOutAlerter), store)
+	cli := poker.NewCLI(store, os.Stdin, os.Stdout, game)
+
+	fmt.Println("Let's play poker")
+	fmt.Println("Type {Name} wins to record a win")
+
+	return cli.PlayPoker()
+}
+
+// audited records the changes made through store in the audit log at path,
+// if there is one, as made by actor from the CLI.
+func audited(store poker.PlayerStore, path, actor string) poker.PlayerStore {
+	if path == "" {
+		return store
+	}
+	return poker.BindContext(cliContext(actor), poker.NewAuditedStore(poker.AdaptPlayerStore(store), poker.NewFileAuditLog(path)))
+}
+
+// cliContext tells the audit log that actor made a change from the CLI.
+func cliContext(actor string) context.Context {
+	return poker.WithSource(poker.WithActor(context.Background(), poker.Actor{Name: actor}), poker.SourceCLI)
+}
+
+func exportAudit(path string) error {
+	if path == "" {
+		return fmt.Errorf("audit needs the -audit log to export")
+	}
+	return poker.NewFileAuditLog(path).Export(context.Background(), os.Stdout)
+}
+
+// moneyStore returns the store's MoneyStore, if it keeps one.
+func moneyStore(store poker.PlayerStore) (poker.MoneyStore, error) {
+	money, ok := poker.StoreFeature[poker.MoneyStore](poker.AdaptPlayerStore(store))
+	if !ok {
+		return nil, fmt.Errorf("money tracking needs the sqlite or postgres store")
+	}
+	return money, nil
+}
+
+func earnings(store poker.PlayerStore) error {
+	money, err := moneyStore(store)
+	if err != nil {
+		return err
+	}
+	earnings, err := money.GetEarnings(context.Background())
+	if err != nil {
+		return err
+	}
+	return poker.PrintEarnings(os.Stdout, earnings)
+}
+
+func settle(store poker.PlayerStore, gameID string) error {
+	id, err := strconv.Atoi(gameID)
+	if err != nil {
+		return fmt.Errorf("settle needs a game id, got %q", gameID)
+	}
+	games, ok := poker.StoreFeature[poker.GameStore](poker.AdaptPlayerStore(store))
+	if !ok {
+		return fmt.Errorf("this store keeps no game history")
+	}
+	game, err := games.GetGame(context.Background(), id)
+	if err != nil {
+		return err
+	}
+	settlement, err := poker.SettleGame(game)
+	if err != nil {
+		return err
+	}
+	return poker.PrintSettlement(os.Stdout, settlement)
+}
+
+func seasonStore(store poker.PlayerStore) (poker.SeasonStore, error) {
+	seasons, ok := poker.StoreFeature[poker.SeasonStore](poker.AdaptPlayerStore(store))
+	if !ok {
+		return nil, fmt.Errorf("seasons need the sqlite or postgres store")
+	}
+	return seasons, nil
+}
+
+func seasons(store poker.PlayerStore) error {
+	seasons, err := seasonStore(store)
+	if err != nil {
+		return err
+	}
+	found, err := seasons.ListSeasons(context.Background())
+	if err != nil {
+		return err
+	}
+	return poker.PrintSeasons(os.Stdout, found)
+}
+
+func season(store poker.PlayerStore, seasonID string) error {
+	id, err := strconv.Atoi(seasonID)
+	if err != nil {
+		return fmt.Errorf("season needs a season id, got %q", seasonID)
+	}
+	seasons, err := seasonStore(store)
+	if err != nil {
+		return err
+	}
+	season, err := seasons.GetSeason(context.Background(), id)
+	if err != nil {
+		return err
+	}
+	allTime, err := poker.AdaptPlayerStore(store).GetLeague(context.Background())
+	if err != nil {
+		return err
+	}
+	return poker.PrintSeasonTable(os.Stdout, poker.SeasonTable{Season: season, AllTime: allTime})
+}
+
+func league(store poker.PlayerStore, scoring string) error {
+	rules, err := poker.LoadRuleSet(scoring)
+	if err != nil {
+		return err
+	}
+	standings, err := poker.ScoreLeague(context.Background(), poker.AdaptPlayerStore(store), rules)
+	if err != nil {
+		return err
+	}
+	return poker.PrintStandings(os.Stdout, standings)
+}
+
+func winReverter(store poker.PlayerStore) (poker.WinReverter, error) {
+	reverter, ok := store.(poker.WinReverter)
+	if !ok {
+		return nil, fmt.Errorf("this store does not keep a win history")
+	}
+	return reverter, nil
+}
+
+// findPlayer looks a player up by id or, failing that, by name.
+func findPlayer(store poker.PlayerStore, player string) (int, error) {
+	if id, err := strconv.Atoi(player); err == nil {
+		return id, nil
+	}
+	if player == "" {
+		return 0, fmt.Errorf("name a player by name or id")
+	}
+	player, err := poker.ValidateName(player)
+	if err != nil {
+		return 0, err
+	}
+	found, err := store.FindByName(player)
+	if err != nil {
+		return 0, err
+	}
+	return found.ID, nil
+}
+
+func wins(store poker.PlayerStore, player string) error {
+	reverter, err := winReverter(store)
+	if err != nil {
+		return err
+	}
+	id, err := findPlayer(store, player)
+	if err != nil {
+		return err
+	}
+	found, err := reverter.Wins(context.Background(), id)
+	if err != nil {
+		return err
+	}
+	return poker.PrintWins(os.Stdout, found)
+}
+
+func undo(store poker.PlayerStore, player string, reversion poker.Reversion) error {
+	reverter, err := winReverter(store)
+	if err != nil {
+		return err
+	}
+	id, err := findPlayer(store, player)
+	if err != nil {
+		return err
+	}
+	if err := poker.Validate(&reversion, poker.ErrInvalidReversion); err != nil {
+		return err
+	}
+	win, err := reverter.UndoLastWin(context.Background(), id, reversion)
+	if err != nil {
+		return err
+	}
+	return poker.PrintWins(os.Stdout, []poker.Win{win})
+}
+
+func revoke(store poker.PlayerStore, winID string, reversion poker.Reversion) error {
+	id, err := strconv.Atoi(winID)
+	if err != nil {
+		return fmt.Errorf("revoke needs a win id, got %q", winID)
+	}
+	reverter, err := winReverter(store)
+	if err != nil {
+		return err
+	}
+	if err := poker.Validate(&reversion, poker.ErrInvalidReversion); err != nil {
+		return err
+	}
+	win, err := reverter.RevokeWin(context.Background(), id, reversion)
+	if err != nil {
+		return err
+	}
+	return poker.PrintWins(os.Stdout, []poker.Win{win})
+}
+
+// deletedStore returns the store's DeletedPlayerStore, with its changes
+// recorded in the audit log at path if there is one.
+func deletedStore(store poker.PlayerStore, path string) (poker.DeletedPlayerStore, error) {
+	deleted, ok := poker.StoreFeature[poker.DeletedPlayerStore](poker.AdaptPlayerStore(store))
+	if !ok {
+		return nil, fmt.Errorf("this store does not keep deleted players")
+	}
+	if path != "" {
+		deleted = poker.AuditDeleted(deleted, poker.NewFileAuditLog(path))
+	}
+	return deleted, nil
+}
+
+func deleted(store poker.PlayerStore) error {
+	deleted, err := deletedStore(store, "")
+	if err != nil {
+		return err
+	}
+	found, err := deleted.DeletedPlayers(context.Background())
+	if err != nil {
+		return err
+	}
+	return poker.PrintDeleted(os.Stdout, found)
+}
+
+// restore brings back a deleted player, given by id or by name.
+func restore(store poker.PlayerStore, player, audit, actor string) error {
+	deleted, err := deletedStore(store, audit)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.Atoi(player)
+	if err != nil {
+		player, err := poker.ValidateName(player)
+		if err != nil {
+			return err
+		}
+		found, err := deleted.DeletedPlayers(context.Background())
+		if err != nil {
+			return err
+		}
+		match := found.FindByName(player)
+		if match == nil {
+			return fmt.Errorf("no deleted player named %q", player)
+		}
+		id = match.ID
+	}
+	restored, err := deleted.RestorePlayer(cliContext(actor), id)
+	if err != nil {
+		return err
+	}
+	return poker.PrintLeague(os.Stdout, poker.League{restored})
+}
+
+func purge(store poker.PlayerStore, retention time.Duration, audit, actor string) error {
+	deleted, err := deletedStore(store, audit)
+	if err != nil {
+		return err
+	}
+	purged, err := deleted.PurgePlayers(cliContext(actor), time.Now().Add(-retention))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("purged %d players deleted more than %v ago\n", purged, retention)
+	return nil
+}
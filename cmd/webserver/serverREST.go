@@ -2,27 +2,48 @@ package main
 
 import (
 	"application/poker"
+	"flag"
 	"log"
 	"net/http"
 	"os"
 )
 
-const dbFileName = "game.db.json"
+const (
+	dbFileName     = "game.db.json"
+	sqliteFileName = "game.db"
+	eventsFileName = "game.events.jsonl"
+	auditFileName  = "game.audit.jsonl"
+)
 
 func main() {
-	db, err := os.OpenFile(dbFileName, os.O_RDWR|os.O_CREATE, 0666)
+	config := poker.StoreConfig{FilePath: dbFileName}
+	flag.StringVar(&config.Type, "store", "file", "player store to use: file, events, sqlite or postgres")
+	flag.StringVar(&config.DSN, "dsn", os.Getenv("DATABASE_URL"), "postgres connection string")
+	flag.StringVar(&config.SQLitePath, "sqlite", sqliteFileName, "sqlite database file")
+	flag.StringVar(&config.EventsPath, "events", eventsFileName, "event log for the events store")
+	scoring := flag.String("scoring", "wins", "league scoring rules: wins, top3, league, bounty or a JSON file")
+	flag.BoolVar(&config.Backup, "backup", false, "keep the previous league file as "+dbFileName+".bak")
+	audit := flag.String("audit", auditFileName, "audit log of every change, empty to keep none")
+	flag.Parse()
+
+	store, closeStore, err := poker.OpenPlayerStore(config)
 
 	if err != nil {
-		log.Fatalf("problem opening %s %v", dbFileName, err)
+		log.Fatalf("problem creating %s player store, %v ", config.Type, err)
 	}
+	defer closeStore()
 
-	store, err := poker.NewFileSystemPlayerStore(db)
-
+	rules, err := poker.LoadRuleSet(*scoring)
 	if err != nil {
-		log.Fatalf("problem creating file system player store, %v ", err)
+		log.Fatal(err)
 	}
 
-	server := poker.NewPlayerServer(store)
+	options := []poker.ServerOption{poker.WithScoring(rules)}
+	if *audit != "" {
+		options = append(options, poker.WithAuditLog(poker.NewFileAuditLog(*audit)))
+	}
+	server := poker.NewPlayerServer(store, options...)
 
-	log.Fatal(http.ListenAndServe(":5000", server))
+	handler := poker.Authenticate(server, os.Getenv("POKER_ADMIN_TOKEN"), []byte(os.Getenv("POKER_JWT_KEY")))
+	log.Fatal(http.ListenAndServe(":5000", handler))
 }
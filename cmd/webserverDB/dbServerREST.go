@@ -1,63 +1,31 @@
 package main
 
 import (
+	"application/poker"
 	"context"
-	"errors"
 	"fmt"
-	"github.com/golang-jwt/jwt/v5"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 )
 
+// jwtKey signs the tokens; set POKER_JWT_KEY to the key the poker servers
+// check them with, so the tokens made here identify actors there too.
 var jwtKey = []byte("H9jm6Ybs/lAtN3BgoKwsvXcm10sDKb1Ipdgd8CCU5dk")
 
-type Claims struct {
-	Username string `json:"username"`
-	Role     string `json:"role"`
-	jwt.RegisteredClaims
+func init() {
+	if key := os.Getenv("POKER_JWT_KEY"); key != "" {
+		jwtKey = []byte(key)
+	}
 }
 
 func GenerateJWT(username, role string) (string, error) {
-	expirationTime := time.Now().Add(1 * time.Hour)
-
-	claims := &Claims{
-		Username: username,
-		Role:     role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-		},
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	tokenString, err := token.SignedString(jwtKey)
-	if err != nil {
-		return "", err
-	}
-
-	return tokenString, nil
+	return poker.NewActorToken(poker.Actor{Name: username, Role: role}, jwtKey, 1*time.Hour)
 }
 
-func ParseJWT(tokenString string) (*Claims, error) {
-	claims := &Claims{}
-
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
-	})
-
-	if err != nil {
-		if errors.Is(err, jwt.ErrSignatureInvalid) {
-			return nil, fmt.Errorf("invalid signature")
-		}
-		return nil, err
-	}
-
-	if !token.Valid {
-		return nil, fmt.Errorf("invalid token")
-	}
-
-	return claims, nil
+func ParseJWT(tokenString string) (poker.Actor, error) {
+	return poker.ParseActorToken(tokenString, jwtKey)
 }
 
 func JWTAuthMiddleware(next http.Handler) http.Handler {
@@ -70,13 +38,14 @@ func JWTAuthMiddleware(next http.Handler) http.Handler {
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-		claims, err := ParseJWT(tokenString)
+		actor, err := ParseJWT(tokenString)
 		if err != nil {
 			http.Error(w, "Invalid token: "+err.Error(), http.StatusUnauthorized)
 			return
 		}
 
-		ctx := context.WithValue(r.Context(), "username", claims.Username)
+		ctx := context.WithValue(r.Context(), "username", actor.Name)
+		ctx = poker.WithActor(ctx, actor)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -90,13 +59,13 @@ func main() {
 
 	fmt.Println("Generated JWT:", token)
 
-	claims, err := ParseJWT(token)
+	actor, err := ParseJWT(token)
 	if err != nil {
 		fmt.Println("Error parsing token:", err)
 		return
 	}
-	fmt.Println("Username:", claims.Username)
-	fmt.Println("Role:", claims.Role)
+	fmt.Println("Username:", actor.Name)
+	fmt.Println("Role:", actor.Role)
 
 	http.Handle("/protected", JWTAuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("This is a protected route"))
@@ -5,9 +5,11 @@ import (
 	"application/graph/model"
 	"application/poker"
 	"context"
+	"flag"
 	"log"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
@@ -15,6 +17,9 @@ import (
 
 const defaultPort = "8080"
 const dbFileName = "game.db.json"
+const sqliteFileName = "game.db"
+const eventsFileName = "game.events.jsonl"
+const auditFileName = "game.audit.jsonl"
 
 func main() {
 	port := os.Getenv("PORT")
@@ -22,36 +27,67 @@ func main() {
 		port = defaultPort
 	}
 
-	db, err := os.OpenFile(dbFileName, os.O_RDWR|os.O_CREATE, 0666)
+	config := poker.StoreConfig{FilePath: dbFileName}
+	flag.StringVar(&config.Type, "store", "file", "player store to use: file, events, sqlite or postgres")
+	flag.StringVar(&config.DSN, "dsn", os.Getenv("DATABASE_URL"), "postgres connection string")
+	flag.StringVar(&config.SQLitePath, "sqlite", sqliteFileName, "sqlite database file")
+	flag.StringVar(&config.EventsPath, "events", eventsFileName, "event log for the events store")
+	scoring := flag.String("scoring", "wins", "league scoring rules: wins, top3, league, bounty or a JSON file")
+	audit := flag.String("audit", auditFileName, "audit log of every change, empty to keep none")
+	flag.Parse()
+
+	store, closeStore, err := poker.OpenPlayerStore(config)
 
 	if err != nil {
-		log.Fatalf("problem opening %s %v", dbFileName, err)
+		log.Fatal(err)
 	}
+	defer closeStore()
 
-	store, err := poker.NewFileSystemPlayerStore(db)
-
-	resolver := &graph.Resolver{
-		Store: store,
+	served := poker.AdaptPlayerStore(store)
+	var auditLog poker.AuditLog
+	if *audit != "" {
+		auditLog = poker.NewFileAuditLog(*audit)
+		served = poker.NewAuditedStore(served, auditLog)
+	}
+	resolver := graph.NewResolver(served)
+	if auditLog != nil {
+		resolver.AuditFeatures(auditLog)
+	}
+	if resolver.Scoring, err = poker.LoadRuleSet(*scoring); err != nil {
+		log.Fatal(err)
 	}
 
-	//router.Use(RoleMiddleware)
+	http.Handle("/", playground.Handler("GraphQL playground", "/query"))
+	http.Handle("/query", queryHandler(resolver, os.Getenv("POKER_ADMIN_TOKEN"), []byte(os.Getenv("POKER_JWT_KEY"))))
 
+	log.Printf("connect to http://localhost:%s/ for GraphQL playground", port)
+	log.Fatal(http.ListenAndServe(":"+port, nil))
+}
+
+// queryHandler serves resolver on /query to the actors poker.Authenticate
+// identifies with adminToken and key, in the role RoleMiddleware gives them.
+func queryHandler(resolver *graph.Resolver, adminToken string, key []byte) http.Handler {
 	srv := handler.NewDefaultServer(graph.NewExecutableSchema(graph.Config{
 		Resolvers: resolver,
 		Directives: graph.DirectiveRoot{
 			Role: graph.RoleDirective,
 		}}))
 
-	http.Handle("/", playground.Handler("GraphQL playground", "/query"))
-	http.Handle("/query", RoleMiddleware(srv))
-
-	log.Printf("connect to http://localhost:%s/ for GraphQL playground", port)
-	log.Fatal(http.ListenAndServe(":"+port, nil))
+	query := poker.Authenticate(RoleMiddleware(srv), adminToken, key)
+	return poker.RequestContextHandler(query, poker.SourceGraphQL)
 }
 
+// RoleMiddleware gives the @role directive the role of the actor making the
+// request, see poker.Authenticate: admins and writers may write, everybody
+// else only reads.
 func RoleMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := context.WithValue(r.Context(), "role", model.RoleReader)
+		role := model.RoleReader
+		switch strings.ToUpper(poker.ActorFrom(r.Context()).Role) {
+		case strings.ToUpper(poker.AdminRole), model.RoleWriter.String():
+			role = model.RoleWriter
+		}
+		ctx := context.WithValue(r.Context(), "role", role)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"application/graph"
+	"application/poker"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQueryRoles(t *testing.T) {
+	key := []byte("test key")
+	store := &poker.StubPlayerStore{League: []poker.Player{{ID: 1, Name: "Cleo", Wins: 2}}}
+	server := queryHandler(graph.NewResolver(poker.AdaptPlayerStore(store)), "", key)
+
+	query := func(t *testing.T, role, body string) []string {
+		t.Helper()
+		token, err := poker.NewActorToken(poker.Actor{Name: "Chris", Role: role}, key, time.Minute)
+		if err != nil {
+			t.Fatalf("could not mint a token, %v", err)
+		}
+		payload, _ := json.Marshal(map[string]string{"query": body})
+		request := httptest.NewRequest(http.MethodPost, "/query", bytes.NewReader(payload))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "Bearer "+token)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		var got struct {
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("could not decode %q, %v", response.Body.String(), err)
+		}
+		var messages []string
+		for _, e := range got.Errors {
+			messages = append(messages, e.Message)
+		}
+		return messages
+	}
+
+	t.Run("writers read READER fields", func(t *testing.T) {
+		if errs := query(t, "writer", `{ league { totalCount } }`); len(errs) > 0 {
+			t.Errorf("writer could not read the league, %v", errs)
+		}
+	})
+
+	t.Run("readers are refused WRITER fields", func(t *testing.T) {
+		errs := query(t, "reader", `{ player(id: "1") { name } }`)
+		if len(errs) == 0 || !strings.Contains(errs[0], "access denied") {
+			t.Errorf("got errors %v, want access denied", errs)
+		}
+	})
+
+	t.Run("readers read READER fields", func(t *testing.T) {
+		if errs := query(t, "reader", `{ league { totalCount } }`); len(errs) > 0 {
+			t.Errorf("reader could not read the league, %v", errs)
+		}
+	})
+}
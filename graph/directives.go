@@ -8,13 +8,22 @@ import (
 	"github.com/99designs/gqlgen/graphql"
 )
 
+// roleRanks orders the roles: a role may do everything the roles ranked
+// below it may.
+var roleRanks = map[model.Role]int{
+	model.RoleReader: 1,
+	model.RoleWriter: 2,
+}
+
+// RoleDirective lets a field resolve when the role in the context is the
+// role it requires or one that outranks it, so writers also read.
 func RoleDirective(ctx context.Context, obj interface{}, next graphql.Resolver, requiredRole model.Role) (res interface{}, err error) {
 	userRole, ok := ctx.Value("role").(model.Role)
 	if !ok {
 		return nil, errors.New("no role found in context")
 	}
 
-	if userRole != requiredRole {
+	if roleRanks[userRole] < roleRanks[requiredRole] {
 		return nil, fmt.Errorf("access denied, requires %s role", requiredRole)
 	}
 
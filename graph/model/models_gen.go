@@ -6,20 +6,187 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"time"
 )
 
+// Amounts are in the smallest unit of the currency, cents for example.
+type Earning struct {
+	PlayerID  string  `json:"playerId"`
+	Name      string  `json:"name"`
+	Games     int     `json:"games"`
+	Invested  int     `json:"invested"`
+	TotalWon  int     `json:"totalWon"`
+	NetProfit int     `json:"netProfit"`
+	Roi       float64 `json:"roi"`
+}
+
+type Game struct {
+	ID           string         `json:"id"`
+	Date         time.Time      `json:"date"`
+	Location     string         `json:"location"`
+	Notes        string         `json:"notes"`
+	WinnerID     *string        `json:"winnerId,omitempty"`
+	Participants []*Participant `json:"participants"`
+}
+
+// A player's record against one opponent over the games they both played.
+type HeadToHead struct {
+	OpponentID   string `json:"opponentId"`
+	OpponentName string `json:"opponentName"`
+	Games        int    `json:"games"`
+	Wins         int    `json:"wins"`
+	Losses       int    `json:"losses"`
+	Draws        int    `json:"draws"`
+}
+
+// A page of the league. Players are only ranked when it is sorted by RANK.
+type LeagueConnection struct {
+	TotalCount int           `json:"totalCount"`
+	Edges      []*LeagueEdge `json:"edges"`
+	PageInfo   *PageInfo     `json:"pageInfo"`
+}
+
+type LeagueEdge struct {
+	Cursor string  `json:"cursor"`
+	Node   *Player `json:"node"`
+}
+
+// The direction defaults to best first by rank, most wins first, names from A and ids from 1.
+type LeagueOrder struct {
+	Field     LeagueSortField `json:"field"`
+	Direction *SortDirection  `json:"direction,omitempty"`
+}
+
 type Mutation struct {
 }
 
+type PageInfo struct {
+	HasNextPage bool    `json:"hasNextPage"`
+	EndCursor   *string `json:"endCursor,omitempty"`
+}
+
+type Participant struct {
+	PlayerID string `json:"playerId"`
+	Name     string `json:"name"`
+	// Where the player finished, 1 for the winner. Null until the result is in.
+	Position *int `json:"position,omitempty"`
+	BuyIn    int  `json:"buyIn"`
+	Rebuys   int  `json:"rebuys"`
+	Payout   int  `json:"payout"`
+}
+
 type Player struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
 	Wins int    `json:"wins"`
+	// Points under the league's scoring rules. Only set in the league.
+	Points *int `json:"points,omitempty"`
+	// Place in the league. Players tied on everything share a rank.
+	Rank *int `json:"rank,omitempty"`
+}
+
+// Stats over the finished games a player took part in.
+type PlayerStats struct {
+	Player      *Player `json:"player"`
+	GamesPlayed int     `json:"gamesPlayed"`
+	Wins        int     `json:"wins"`
+	// Percentage of games won.
+	WinRate       float64 `json:"winRate"`
+	CurrentStreak int     `json:"currentStreak"`
+	LongestStreak int     `json:"longestStreak"`
+	// Average over the games the player was placed in, 0 when there are none.
+	AveragePosition float64       `json:"averagePosition"`
+	HeadToHead      []*HeadToHead `json:"headToHead"`
 }
 
 type Query struct {
 }
 
+// Who took a win back and why.
+type Reversion struct {
+	At     time.Time `json:"at"`
+	By     string    `json:"by"`
+	Reason string    `json:"reason"`
+}
+
+type ScoringRules struct {
+	Name        string   `json:"name"`
+	Rules       []string `json:"rules"`
+	TieBreakers []string `json:"tieBreakers"`
+}
+
+// A closed season keeps its standings as they were when it was closed.
+type Season struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	StartsAt  time.Time  `json:"startsAt"`
+	EndsAt    *time.Time `json:"endsAt,omitempty"`
+	ClosedAt  *time.Time `json:"closedAt,omitempty"`
+	Standings []*Player  `json:"standings"`
+}
+
+type StakeInput struct {
+	PlayerID string `json:"playerId"`
+	BuyIn    int    `json:"buyIn"`
+	Rebuys   int    `json:"rebuys"`
+	Payout   int    `json:"payout"`
+}
+
+// A recorded win. A reverted win no longer counts but stays in the history.
+type Win struct {
+	ID       string     `json:"id"`
+	PlayerID string     `json:"playerId"`
+	GameID   *string    `json:"gameId,omitempty"`
+	At       time.Time  `json:"at"`
+	Reverted *Reversion `json:"reverted,omitempty"`
+}
+
+// How the league is sorted. RANK is the order of the scoring rules.
+type LeagueSortField string
+
+const (
+	LeagueSortFieldRank LeagueSortField = "RANK"
+	LeagueSortFieldWins LeagueSortField = "WINS"
+	LeagueSortFieldName LeagueSortField = "NAME"
+	LeagueSortFieldID   LeagueSortField = "ID"
+)
+
+var AllLeagueSortField = []LeagueSortField{
+	LeagueSortFieldRank,
+	LeagueSortFieldWins,
+	LeagueSortFieldName,
+	LeagueSortFieldID,
+}
+
+func (e LeagueSortField) IsValid() bool {
+	switch e {
+	case LeagueSortFieldRank, LeagueSortFieldWins, LeagueSortFieldName, LeagueSortFieldID:
+		return true
+	}
+	return false
+}
+
+func (e LeagueSortField) String() string {
+	return string(e)
+}
+
+func (e *LeagueSortField) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = LeagueSortField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid LeagueSortField", str)
+	}
+	return nil
+}
+
+func (e LeagueSortField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type Role string
 
 const (
@@ -60,3 +227,44 @@ func (e *Role) UnmarshalGQL(v interface{}) error {
 func (e Role) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
+
+type SortDirection string
+
+const (
+	SortDirectionAsc  SortDirection = "ASC"
+	SortDirectionDesc SortDirection = "DESC"
+)
+
+var AllSortDirection = []SortDirection{
+	SortDirectionAsc,
+	SortDirectionDesc,
+}
+
+func (e SortDirection) IsValid() bool {
+	switch e {
+	case SortDirectionAsc, SortDirectionDesc:
+		return true
+	}
+	return false
+}
+
+func (e SortDirection) String() string {
+	return string(e)
+}
+
+func (e *SortDirection) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SortDirection(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SortDirection", str)
+	}
+	return nil
+}
+
+func (e SortDirection) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
@@ -3,6 +3,13 @@ package graph
 import (
 	"application/graph/model"
 	"application/poker"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
 // This file will not be regenerated automatically.
@@ -15,13 +22,291 @@ const (
 )
 
 type Resolver struct {
-	Store poker.PlayerStore
-	Role  model.Role
+	Store poker.PlayerStoreV2
+	// Games, Money, Seasons and Wins are nil when the store does not support
+	// them.
+	Games   poker.GameStore
+	Money   poker.MoneyStore
+	Seasons poker.SeasonStore
+	Wins    poker.WinReverter
+	// Scoring ranks the league, poker.DefaultRuleSet when it has no rules.
+	Scoring poker.RuleSet
+	Role    model.Role
+}
+
+// NewResolver serves store, along with the optional store interfaces it
+// implements.
+func NewResolver(store poker.PlayerStoreV2) *Resolver {
+	resolver := &Resolver{Store: store, Scoring: poker.DefaultRuleSet}
+	resolver.Games, _ = poker.StoreFeature[poker.GameStore](store)
+	resolver.Money, _ = poker.StoreFeature[poker.MoneyStore](store)
+	resolver.Seasons, _ = poker.StoreFeature[poker.SeasonStore](store)
+	resolver.Wins, _ = poker.StoreFeature[poker.WinReverter](store)
+	return resolver
+}
+
+// AuditFeatures records the changes made through the optional store
+// interfaces in log, the way a poker.AuditedStore Store records the others.
+func (r *Resolver) AuditFeatures(log poker.AuditLog) {
+	if r.Games != nil {
+		r.Games = poker.AuditGames(r.Store, r.Games, log)
+	}
+	if r.Money != nil {
+		r.Money = poker.AuditMoney(r.Money, log)
+	}
+	if r.Seasons != nil {
+		r.Seasons = poker.AuditSeasons(r.Seasons, log)
+	}
+	if r.Wins != nil {
+		r.Wins = poker.AuditWins(r.Store, r.Wins, log)
+	}
+}
+
+var errNotSupported = poker.ErrNotSupported
+
+func (r *Resolver) gameStore() (poker.GameStore, error) {
+	if r.Games == nil {
+		return nil, fmt.Errorf("game history is %w", errNotSupported)
+	}
+	return r.Games, nil
+}
+
+func (r *Resolver) moneyStore() (poker.MoneyStore, error) {
+	if r.Money == nil {
+		return nil, fmt.Errorf("money tracking is %w, use the sqlite or postgres store", errNotSupported)
+	}
+	return r.Money, nil
 }
 
 func Convert(player poker.Player) *model.Player {
 	return &model.Player{
+		ID:   strconv.Itoa(player.ID),
 		Name: player.Name,
 		Wins: player.Wins,
 	}
 }
+
+func ConvertStanding(standing poker.Standing) *model.Player {
+	points, rank := standing.Points, standing.Rank
+	player := &model.Player{
+		ID:     strconv.Itoa(standing.ID),
+		Name:   standing.Name,
+		Wins:   standing.Wins,
+		Points: &points,
+	}
+	if rank != 0 {
+		player.Rank = &rank
+	}
+	return player
+}
+
+// ConvertLeaguePage turns a page into a connection whose edges have the
+// cursor of the standing after them, so after: continues past the edge.
+func ConvertLeaguePage(page poker.LeaguePage) *model.LeagueConnection {
+	connection := &model.LeagueConnection{
+		TotalCount: page.Total,
+		Edges:      make([]*model.LeagueEdge, 0, len(page.Standings)),
+		PageInfo:   &model.PageInfo{},
+	}
+	for i, standing := range page.Standings {
+		connection.Edges = append(connection.Edges, &model.LeagueEdge{
+			Cursor: poker.EncodeCursor(page.Offset + i + 1),
+			Node:   ConvertStanding(standing),
+		})
+	}
+	if n := len(connection.Edges); n > 0 {
+		connection.PageInfo.EndCursor = &connection.Edges[n-1].Cursor
+	}
+	_, connection.PageInfo.HasNextPage = page.Next()
+	return connection
+}
+
+// leagueQuery builds the LeagueQuery the arguments of the league field ask
+// for. Arguments that are left out keep the defaults of LeagueQuery.
+func leagueQuery(first *int, after *string, orderBy *model.LeagueOrder, minWins *int, name *string) (poker.LeagueQuery, error) {
+	var query poker.LeagueQuery
+	if first != nil {
+		query.Limit = *first
+	}
+	if after != nil {
+		offset, err := poker.DecodeCursor(*after)
+		if err != nil {
+			return poker.LeagueQuery{}, err
+		}
+		query.Offset = offset
+	}
+	if orderBy != nil {
+		query.Sort = strings.ToLower(string(orderBy.Field))
+		if orderBy.Direction != nil {
+			query.Order = strings.ToLower(string(*orderBy.Direction))
+		}
+	}
+	if minWins != nil {
+		query.MinWins = *minWins
+	}
+	if name != nil {
+		query.Name = *name
+	}
+	return query, nil
+}
+
+func ConvertGame(game poker.GameRecord) *model.Game {
+	result := &model.Game{
+		ID:           strconv.Itoa(game.ID),
+		Date:         game.Date,
+		Location:     game.Location,
+		Notes:        game.Notes,
+		Participants: make([]*model.Participant, 0, len(game.Participants)),
+	}
+	if game.WinnerID != 0 {
+		winnerID := strconv.Itoa(game.WinnerID)
+		result.WinnerID = &winnerID
+	}
+	for _, participant := range game.Participants {
+		converted := &model.Participant{
+			PlayerID: strconv.Itoa(participant.PlayerID),
+			Name:     participant.Name,
+		}
+		if participant.Position > 0 {
+			position := participant.Position
+			converted.Position = &position
+		}
+		converted.BuyIn = participant.BuyIn
+		converted.Rebuys = participant.Rebuys
+		converted.Payout = participant.Payout
+		result.Participants = append(result.Participants, converted)
+	}
+	return result
+}
+
+func (r *Resolver) scoring() poker.RuleSet {
+	if len(r.Scoring.Rules) == 0 {
+		return poker.DefaultRuleSet
+	}
+	return r.Scoring
+}
+
+func (r *Resolver) seasonStore() (poker.SeasonStore, error) {
+	if r.Seasons == nil {
+		return nil, fmt.Errorf("seasons are %w, use the sqlite or postgres store", errNotSupported)
+	}
+	return r.Seasons, nil
+}
+
+func (r *Resolver) winReverter() (poker.WinReverter, error) {
+	if r.Wins == nil {
+		return nil, fmt.Errorf("win history is %w", errNotSupported)
+	}
+	return r.Wins, nil
+}
+
+func ConvertWin(win poker.Win) *model.Win {
+	result := &model.Win{
+		ID:       strconv.Itoa(win.ID),
+		PlayerID: strconv.Itoa(win.PlayerID),
+		At:       win.At,
+	}
+	if win.GameID != 0 {
+		gameID := strconv.Itoa(win.GameID)
+		result.GameID = &gameID
+	}
+	if win.Reverted != nil {
+		result.Reverted = &model.Reversion{At: win.Reverted.At, By: win.Reverted.By, Reason: win.Reverted.Reason}
+	}
+	return result
+}
+
+func ConvertSeason(season poker.Season) *model.Season {
+	result := &model.Season{
+		ID:        strconv.Itoa(season.ID),
+		Name:      season.Name,
+		StartsAt:  season.StartsAt,
+		EndsAt:    season.EndsAt,
+		ClosedAt:  season.ClosedAt,
+		Standings: make([]*model.Player, 0, len(season.Standings)),
+	}
+	for _, player := range season.Standings {
+		result.Standings = append(result.Standings, Convert(player))
+	}
+	return result
+}
+
+func ConvertEarning(earning poker.Earning) *model.Earning {
+	return &model.Earning{
+		PlayerID:  strconv.Itoa(earning.ID),
+		Name:      earning.Name,
+		Games:     earning.Games,
+		Invested:  earning.Invested,
+		TotalWon:  earning.TotalWon,
+		NetProfit: earning.NetProfit,
+		Roi:       earning.ROI,
+	}
+}
+
+func ConvertStats(stats poker.PlayerStats) *model.PlayerStats {
+	result := &model.PlayerStats{
+		Player:          Convert(poker.Player{ID: stats.ID, Name: stats.Name, Wins: stats.Wins}),
+		GamesPlayed:     stats.GamesPlayed,
+		Wins:            stats.Wins,
+		WinRate:         stats.WinRate,
+		CurrentStreak:   stats.CurrentStreak,
+		LongestStreak:   stats.LongestStreak,
+		AveragePosition: stats.AveragePosition,
+		HeadToHead:      make([]*model.HeadToHead, 0, len(stats.HeadToHead)),
+	}
+	for _, record := range stats.HeadToHead {
+		result.HeadToHead = append(result.HeadToHead, &model.HeadToHead{
+			OpponentID:   strconv.Itoa(record.OpponentID),
+			OpponentName: record.OpponentName,
+			Games:        record.Games,
+			Wins:         record.Wins,
+			Losses:       record.Losses,
+			Draws:        record.Draws,
+		})
+	}
+	return result
+}
+
+func parseIDs(ids []string) ([]int, error) {
+	nums := make([]int, len(ids))
+	for i, id := range ids {
+		num, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, err
+		}
+		nums[i] = num
+	}
+	return nums, nil
+}
+
+// storeError adds a code extension to store errors so clients can tell a
+// missing player from a failing store without parsing the message. Input
+// that did not validate also gets a fields extension listing what is wrong.
+func storeError(ctx context.Context, err error) error {
+	code := "INTERNAL"
+	switch {
+	case errors.Is(err, poker.ErrPlayerNotFound), errors.Is(err, poker.ErrGameNotFound), errors.Is(err, poker.ErrSeasonNotFound),
+		errors.Is(err, poker.ErrWinNotFound):
+		code = "NOT_FOUND"
+	case errors.Is(err, poker.ErrDuplicatePlayer), errors.Is(err, poker.ErrGameFinished), errors.Is(err, poker.ErrSeasonClosed),
+		errors.Is(err, poker.ErrWinReverted):
+		code = "CONFLICT"
+	case errors.Is(err, poker.ErrInvalidPlayer), errors.Is(err, poker.ErrInvalidGame), errors.Is(err, poker.ErrInvalidSeason),
+		errors.Is(err, poker.ErrInvalidReversion), errors.Is(err, poker.ErrInvalidQuery):
+		code = "BAD_USER_INPUT"
+	case errors.Is(err, errNotSupported):
+		code = "NOT_IMPLEMENTED"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		code = "CANCELLED"
+	}
+	extensions := map[string]interface{}{"code": code}
+	var invalid *poker.ValidationError
+	if errors.As(err, &invalid) {
+		extensions["fields"] = invalid.Fields
+	}
+	return &gqlerror.Error{
+		Err:        err,
+		Message:    err.Error(),
+		Extensions: extensions,
+	}
+}
@@ -8,22 +8,31 @@ import (
 	"application/graph/model"
 	"application/poker"
 	"context"
-	"fmt"
 	"strconv"
+	"time"
 )
 
 // AddPlayer is the resolver for the addPlayer field.
-func (r *mutationResolver) AddPlayer(ctx context.Context, id string, name string, wins int) (*model.Player, error) {
-	player := &poker.Player{
-		Name: name,
-		Wins: wins,
+func (r *mutationResolver) AddPlayer(ctx context.Context, id *string, name string, wins int) (*model.Player, error) {
+	input := poker.PlayerInput{Name: name, Wins: wins}
+	if id != nil {
+		num, err := strconv.Atoi(*id)
+		if err != nil {
+			return nil, storeError(ctx, &poker.ValidationError{
+				Err:    poker.ErrInvalidPlayer,
+				Fields: []poker.FieldError{{Field: "id", Message: "must be a number"}},
+			})
+		}
+		input.ID = num
 	}
-	r.Store.AddPlayer(player)
-	modelPlayer := &model.Player{
-		Name: player.Name,
-		Wins: player.Wins,
+	if err := poker.Validate(&input, poker.ErrInvalidPlayer); err != nil {
+		return nil, storeError(ctx, err)
 	}
-	return modelPlayer, nil
+	player := input.Player()
+	if err := r.Store.AddPlayer(ctx, &player); err != nil {
+		return nil, storeError(ctx, err)
+	}
+	return Convert(player), nil
 }
 
 // RecordWin is the resolver for the recordWin field.
@@ -32,21 +41,185 @@ func (r *mutationResolver) RecordWin(ctx context.Context, id string) (*model.Pla
 	if err != nil {
 		return nil, err
 	}
-	r.Store.RecordWin(num)
-	player := &model.Player{
-		ID: id,
+	if err := r.Store.RecordWin(ctx, num); err != nil {
+		return nil, storeError(ctx, err)
+	}
+	player, err := r.Store.GetPlayer(ctx, num)
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	return Convert(player), nil
+}
+
+// CreateGame is the resolver for the createGame field.
+func (r *mutationResolver) CreateGame(ctx context.Context, date *time.Time, location *string, notes *string, participants []string) (*model.Game, error) {
+	games, err := r.gameStore()
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	playerIDs, err := parseIDs(participants)
+	if err != nil {
+		return nil, err
+	}
+	game := poker.GameRecord{}
+	if date != nil {
+		game.Date = *date
+	}
+	if location != nil {
+		game.Location = *location
+	}
+	if notes != nil {
+		game.Notes = *notes
+	}
+	for _, playerID := range playerIDs {
+		game.Participants = append(game.Participants, poker.Participant{PlayerID: playerID})
+	}
+	if err := games.CreateGame(ctx, &game); err != nil {
+		return nil, storeError(ctx, err)
+	}
+	return r.Query().Game(ctx, strconv.Itoa(game.ID))
+}
+
+// RecordGameResult is the resolver for the recordGameResult field.
+func (r *mutationResolver) RecordGameResult(ctx context.Context, id string, finishingOrder []string) (*model.Game, error) {
+	games, err := r.gameStore()
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	num, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+	order, err := parseIDs(finishingOrder)
+	if err != nil {
+		return nil, err
+	}
+	if err := games.RecordResult(ctx, num, order); err != nil {
+		return nil, storeError(ctx, err)
 	}
-	return player, nil
+	return r.Query().Game(ctx, id)
+}
+
+// RecordMoney is the resolver for the recordMoney field.
+func (r *mutationResolver) RecordMoney(ctx context.Context, gameID string, stakes []*model.StakeInput) (*model.Game, error) {
+	money, err := r.moneyStore()
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	num, err := strconv.Atoi(gameID)
+	if err != nil {
+		return nil, err
+	}
+	converted := make([]poker.Stake, len(stakes))
+	for i, stake := range stakes {
+		playerID, err := strconv.Atoi(stake.PlayerID)
+		if err != nil {
+			return nil, err
+		}
+		converted[i] = poker.Stake{PlayerID: playerID, BuyIn: stake.BuyIn, Rebuys: stake.Rebuys, Payout: stake.Payout}
+	}
+	if err := money.RecordMoney(ctx, num, converted); err != nil {
+		return nil, storeError(ctx, err)
+	}
+	return r.Query().Game(ctx, gameID)
+}
+
+// CreateSeason is the resolver for the createSeason field.
+func (r *mutationResolver) CreateSeason(ctx context.Context, name string, startsAt *time.Time, endsAt *time.Time) (*model.Season, error) {
+	seasons, err := r.seasonStore()
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	season := poker.Season{Name: name, EndsAt: endsAt}
+	if startsAt != nil {
+		season.StartsAt = *startsAt
+	}
+	if err := seasons.CreateSeason(ctx, &season); err != nil {
+		return nil, storeError(ctx, err)
+	}
+	return r.Query().Season(ctx, strconv.Itoa(season.ID))
+}
+
+// CloseSeason is the resolver for the closeSeason field.
+func (r *mutationResolver) CloseSeason(ctx context.Context, id string) (*model.Season, error) {
+	seasons, err := r.seasonStore()
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	num, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+	season, err := seasons.CloseSeason(ctx, num)
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	return ConvertSeason(season), nil
+}
+
+// UndoLastWin is the resolver for the undoLastWin field.
+func (r *mutationResolver) UndoLastWin(ctx context.Context, playerID string, by string, reason string) (*model.Win, error) {
+	reverter, err := r.winReverter()
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	num, err := strconv.Atoi(playerID)
+	if err != nil {
+		return nil, err
+	}
+	reversion := poker.Reversion{By: by, Reason: reason}
+	if err := poker.Validate(&reversion, poker.ErrInvalidReversion); err != nil {
+		return nil, storeError(ctx, err)
+	}
+	win, err := reverter.UndoLastWin(ctx, num, reversion)
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	return ConvertWin(win), nil
+}
+
+// RevokeWin is the resolver for the revokeWin field.
+func (r *mutationResolver) RevokeWin(ctx context.Context, id string, by string, reason string) (*model.Win, error) {
+	reverter, err := r.winReverter()
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	num, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+	reversion := poker.Reversion{By: by, Reason: reason}
+	if err := poker.Validate(&reversion, poker.ErrInvalidReversion); err != nil {
+		return nil, storeError(ctx, err)
+	}
+	win, err := reverter.RevokeWin(ctx, num, reversion)
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	return ConvertWin(win), nil
 }
 
 // League is the resolver for the league field.
-func (r *queryResolver) League(ctx context.Context) ([]*model.Player, error) {
-	result := make([]*model.Player, 0)
-	league := r.Store.GetLeague()
-	for _, player := range league {
-		result = append(result, Convert(player))
+func (r *queryResolver) League(ctx context.Context, first *int, after *string, orderBy *model.LeagueOrder, minWins *int, name *string) (*model.LeagueConnection, error) {
+	query, err := leagueQuery(first, after, orderBy, minWins, name)
+	if err != nil {
+		return nil, storeError(ctx, err)
 	}
-	return result, nil
+	page, err := poker.QueryLeague(ctx, r.Store, r.scoring(), query)
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	return ConvertLeaguePage(page), nil
+}
+
+// ScoringRules is the resolver for the scoringRules field.
+func (r *queryResolver) ScoringRules(ctx context.Context) (*model.ScoringRules, error) {
+	rules := r.scoring()
+	return &model.ScoringRules{
+		Name:        rules.Name,
+		Rules:       rules.Describe(),
+		TieBreakers: rules.TieBreakers(),
+	}, nil
 }
 
 // Player is the resolver for the player field.
@@ -55,11 +228,160 @@ func (r *queryResolver) Player(ctx context.Context, id string) (*model.Player, e
 	if err != nil {
 		return nil, err
 	}
-	player := r.Store.GetLeague().Find(num)
-	if player == nil {
-		return nil, fmt.Errorf("player not found")
+	player, err := r.Store.GetPlayer(ctx, num)
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	return Convert(player), nil
+}
+
+// Games is the resolver for the games field.
+func (r *queryResolver) Games(ctx context.Context, playerID *string, since *time.Time, until *time.Time, limit *int) ([]*model.Game, error) {
+	games, err := r.gameStore()
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	var filter poker.GameFilter
+	if playerID != nil {
+		if filter.PlayerID, err = strconv.Atoi(*playerID); err != nil {
+			return nil, err
+		}
+	}
+	if since != nil {
+		filter.Since = *since
+	}
+	if until != nil {
+		filter.Until = *until
+	}
+	if limit != nil {
+		filter.Limit = *limit
+	}
+	found, err := games.ListGames(ctx, filter)
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	result := make([]*model.Game, 0, len(found))
+	for _, game := range found {
+		result = append(result, ConvertGame(game))
+	}
+	return result, nil
+}
+
+// Game is the resolver for the game field.
+func (r *queryResolver) Game(ctx context.Context, id string) (*model.Game, error) {
+	games, err := r.gameStore()
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	num, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+	game, err := games.GetGame(ctx, num)
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	return ConvertGame(game), nil
+}
+
+// Earnings is the resolver for the earnings field.
+func (r *queryResolver) Earnings(ctx context.Context) ([]*model.Earning, error) {
+	money, err := r.moneyStore()
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	earnings, err := money.GetEarnings(ctx)
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	result := make([]*model.Earning, 0, len(earnings))
+	for _, earning := range earnings {
+		result = append(result, ConvertEarning(earning))
+	}
+	return result, nil
+}
+
+// Seasons is the resolver for the seasons field.
+func (r *queryResolver) Seasons(ctx context.Context) ([]*model.Season, error) {
+	seasons, err := r.seasonStore()
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	found, err := seasons.ListSeasons(ctx)
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	// The list comes without standings, so each season is loaded in full.
+	result := make([]*model.Season, 0, len(found))
+	for _, season := range found {
+		withStandings, err := seasons.GetSeason(ctx, season.ID)
+		if err != nil {
+			return nil, storeError(ctx, err)
+		}
+		result = append(result, ConvertSeason(withStandings))
+	}
+	return result, nil
+}
+
+// Season is the resolver for the season field.
+func (r *queryResolver) Season(ctx context.Context, id string) (*model.Season, error) {
+	seasons, err := r.seasonStore()
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	num, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+	season, err := seasons.GetSeason(ctx, num)
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	return ConvertSeason(season), nil
+}
+
+// PlayerStats is the resolver for the playerStats field.
+func (r *queryResolver) PlayerStats(ctx context.Context, id string, opponent *string) (*model.PlayerStats, error) {
+	if _, err := r.gameStore(); err != nil {
+		return nil, storeError(ctx, err)
 	}
-	return Convert(*player), nil
+	num, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := poker.GetPlayerStats(ctx, r.Store, num)
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	if opponent != nil {
+		opponentID, err := strconv.Atoi(*opponent)
+		if err != nil {
+			return nil, err
+		}
+		stats.HeadToHead = []poker.HeadToHead{stats.Against(opponentID)}
+	}
+	return ConvertStats(stats), nil
+}
+
+// Wins is the resolver for the wins field.
+func (r *queryResolver) Wins(ctx context.Context, playerID string) ([]*model.Win, error) {
+	reverter, err := r.winReverter()
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	num, err := strconv.Atoi(playerID)
+	if err != nil {
+		return nil, err
+	}
+	wins, err := reverter.Wins(ctx, num)
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+	result := make([]*model.Win, 0, len(wins))
+	for _, win := range wins {
+		result = append(result, ConvertWin(win))
+	}
+	return result, nil
 }
 
 // Mutation returns MutationResolver implementation.
@@ -0,0 +1,240 @@
+// Package migrations holds the versioned SQL schema used by poker.DatabaseStore
+// and applies it to a database, recording progress in a schema_version table.
+//
+// The migrations are written for Postgres. The same files are applied to
+// SQLite after rewriting the few types the two databases spell differently.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+const createVersionTable = `CREATE TABLE IF NOT EXISTS schema_version (
+    version    INTEGER PRIMARY KEY,
+    name       TEXT      NOT NULL,
+    applied_at TIMESTAMP NOT NULL
+)`
+
+// Migration is one schema change, read from a pair of files named
+// NNNN_name.up.sql and NNNN_name.down.sql.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status reports whether a migration has been applied to the database.
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration
+}
+
+// sqliteReplacer turns Postgres-only column types into their SQLite
+// equivalents.
+var sqliteReplacer = strings.NewReplacer(
+	"SERIAL PRIMARY KEY", "INTEGER PRIMARY KEY AUTOINCREMENT",
+)
+
+func New(db *sqlx.DB) (*Migrator, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Load returns the embedded migrations ordered by version.
+func Load() ([]Migration, error) {
+	entries, err := fs.ReadDir(files, "sql")
+	if err != nil {
+		return nil, fmt.Errorf("problem reading migrations, %v", err)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		name := entry.Name()
+		version, title, direction, err := parseFileName(name)
+		if err != nil {
+			return nil, err
+		}
+		body, err := fs.ReadFile(files, path.Join("sql", name))
+		if err != nil {
+			return nil, fmt.Errorf("problem reading migration %s, %v", name, err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: title}
+			byVersion[version] = m
+		} else if m.Name != title {
+			return nil, fmt.Errorf("migration version %d is used by both %q and %q", version, m.Name, title)
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+func parseFileName(name string) (version int, title, direction string, err error) {
+	base, ok := strings.CutSuffix(name, ".sql")
+	if !ok {
+		return 0, "", "", fmt.Errorf("unexpected migration file %s", name)
+	}
+	direction = strings.TrimPrefix(path.Ext(base), ".")
+	base = strings.TrimSuffix(base, path.Ext(base))
+	if direction != "up" && direction != "down" {
+		return 0, "", "", fmt.Errorf("migration file %s must end in .up.sql or .down.sql", name)
+	}
+	number, title, ok := strings.Cut(base, "_")
+	if !ok {
+		return 0, "", "", fmt.Errorf("migration file %s must be named NNNN_name", name)
+	}
+	version, err = strconv.Atoi(number)
+	if err != nil {
+		return 0, "", "", fmt.Errorf("migration file %s has no version number, %v", name, err)
+	}
+	return version, title, direction, nil
+}
+
+// Up applies every pending migration in order and returns the ones applied.
+func (m *Migrator) Up() ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		err := m.inTx(func(tx *sqlx.Tx) error {
+			if _, err := tx.Exec(m.forDriver(migration.Up)); err != nil {
+				return err
+			}
+			_, err := tx.Exec("INSERT INTO schema_version (version, name, applied_at) VALUES ($1, $2, $3)",
+				migration.Version, migration.Name, time.Now().UTC())
+			return err
+		})
+		if err != nil {
+			return done, fmt.Errorf("problem applying migration %04d_%s, %v", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down reverts the most recently applied migration. It returns nil when
+// there is nothing left to revert.
+func (m *Migrator) Down() (*Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		err := m.inTx(func(tx *sqlx.Tx) error {
+			if _, err := tx.Exec(m.forDriver(migration.Down)); err != nil {
+				return err
+			}
+			_, err := tx.Exec("DELETE FROM schema_version WHERE version = $1", migration.Version)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("problem reverting migration %04d_%s, %v", migration.Version, migration.Name, err)
+		}
+		return &migration, nil
+	}
+	return nil, nil
+}
+
+// Status lists every known migration and whether it has been applied.
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		at, ok := applied[migration.Version]
+		statuses = append(statuses, Status{Migration: migration, Applied: ok, AppliedAt: at})
+	}
+	return statuses, nil
+}
+
+func (m *Migrator) forDriver(statement string) string {
+	if m.db.DriverName() == "sqlite" {
+		return sqliteReplacer.Replace(statement)
+	}
+	return statement
+}
+
+func (m *Migrator) applied() (map[int]time.Time, error) {
+	if _, err := m.db.Exec(createVersionTable); err != nil {
+		return nil, fmt.Errorf("problem creating schema_version table, %v", err)
+	}
+
+	var rows []struct {
+		Version   int       `db:"version"`
+		AppliedAt time.Time `db:"applied_at"`
+	}
+	if err := m.db.Select(&rows, "SELECT version, applied_at FROM schema_version"); err != nil {
+		return nil, fmt.Errorf("problem reading schema_version, %v", err)
+	}
+
+	applied := make(map[int]time.Time, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row.AppliedAt
+	}
+	return applied, nil
+}
+
+func (m *Migrator) inTx(fn func(tx *sqlx.Tx) error) error {
+	tx, err := m.db.Beginx()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,132 @@
+package migrations
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "modernc.org/sqlite"
+)
+
+func createTestMigrator(t testing.TB) (*Migrator, *sqlx.DB) {
+	t.Helper()
+
+	db, err := sqlx.Open("sqlite", filepath.Join(t.TempDir(), "game.db"))
+	if err != nil {
+		t.Fatalf("could not open sqlite database %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := New(db)
+	if err != nil {
+		t.Fatalf("could not load migrations %v", err)
+	}
+	return migrator, db
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(migrations) == 0 {
+		t.Fatal("expected embedded migrations")
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("migration %q has version %d, want %d", m.Name, m.Version, i+1)
+		}
+	}
+}
+
+func TestMigrator(t *testing.T) {
+
+	t.Run("up creates the league tables", func(t *testing.T) {
+		migrator, db := createTestMigrator(t)
+
+		applied, err := migrator.Up()
+		assertNoError(t, err)
+
+		all, _ := Load()
+		if len(applied) != len(all) {
+			t.Errorf("applied %d migrations, want %d", len(applied), len(all))
+		}
+		for _, table := range []string{"players", "games", "game_results"} {
+			assertTableExists(t, db, table, true)
+		}
+	})
+
+	t.Run("up twice is a no-op", func(t *testing.T) {
+		migrator, _ := createTestMigrator(t)
+
+		_, err := migrator.Up()
+		assertNoError(t, err)
+		applied, err := migrator.Up()
+		assertNoError(t, err)
+
+		if len(applied) != 0 {
+			t.Errorf("expected nothing to apply, got %d migrations", len(applied))
+		}
+	})
+
+	t.Run("down reverts the latest migration", func(t *testing.T) {
+		migrator, db := createTestMigrator(t)
+		_, err := migrator.Up()
+		assertNoError(t, err)
+
+		all, _ := Load()
+		for range all {
+			_, err := migrator.Down()
+			assertNoError(t, err)
+		}
+
+		assertTableExists(t, db, "players", false)
+		reverted, err := migrator.Down()
+		assertNoError(t, err)
+		if reverted != nil {
+			t.Errorf("expected nothing left to revert, got %04d", reverted.Version)
+		}
+	})
+
+	t.Run("status reports applied migrations", func(t *testing.T) {
+		migrator, _ := createTestMigrator(t)
+
+		statuses, err := migrator.Status()
+		assertNoError(t, err)
+		for _, s := range statuses {
+			if s.Applied {
+				t.Errorf("migration %04d should not be applied yet", s.Version)
+			}
+		}
+
+		_, err = migrator.Up()
+		assertNoError(t, err)
+
+		statuses, err = migrator.Status()
+		assertNoError(t, err)
+		for _, s := range statuses {
+			if !s.Applied || s.AppliedAt.IsZero() {
+				t.Errorf("migration %04d should be applied, got %+v", s.Version, s)
+			}
+		}
+	})
+}
+
+func assertTableExists(t testing.TB, db *sqlx.DB, table string, want bool) {
+	t.Helper()
+	var count int
+	if err := db.Get(&count, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = $1", table); err != nil {
+		t.Fatal(err)
+	}
+	if got := count == 1; got != want {
+		t.Errorf("table %s exists: got %v want %v", table, got, want)
+	}
+}
+
+func assertNoError(t testing.TB, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("didn't expect an error but got one, %v", err)
+	}
+}
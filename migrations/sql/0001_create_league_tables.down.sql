@@ -0,0 +1,3 @@
+DROP TABLE game_results;
+DROP TABLE games;
+DROP TABLE players;
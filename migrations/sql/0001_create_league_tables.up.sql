@@ -0,0 +1,23 @@
+CREATE TABLE players (
+    id         SERIAL PRIMARY KEY,
+    username   TEXT      NOT NULL UNIQUE,
+    email      TEXT      NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE games (
+    id        SERIAL PRIMARY KEY,
+    game_date TIMESTAMP NOT NULL,
+    location  TEXT      NOT NULL DEFAULT '',
+    notes     TEXT      NOT NULL DEFAULT ''
+);
+
+CREATE TABLE game_results (
+    id         SERIAL PRIMARY KEY,
+    game_id    INTEGER NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+    winner_id  INTEGER NOT NULL REFERENCES players (id) ON DELETE CASCADE,
+    amount_won INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX game_results_winner_id_idx ON game_results (winner_id);
+CREATE INDEX game_results_game_id_idx ON game_results (game_id);
@@ -0,0 +1 @@
+DROP INDEX players_username_lower_idx;
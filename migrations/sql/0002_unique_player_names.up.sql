@@ -0,0 +1 @@
+CREATE UNIQUE INDEX players_username_lower_idx ON players (LOWER(username));
@@ -0,0 +1,11 @@
+CREATE TABLE game_participants (
+    game_id   INTEGER NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+    player_id INTEGER NOT NULL REFERENCES players (id) ON DELETE CASCADE,
+    position  INTEGER,
+    PRIMARY KEY (game_id, player_id)
+);
+
+CREATE INDEX game_participants_player_id_idx ON game_participants (player_id);
+
+INSERT INTO game_participants (game_id, player_id, position)
+SELECT game_id, winner_id, 1 FROM game_results;
@@ -0,0 +1,3 @@
+ALTER TABLE game_participants DROP COLUMN payout;
+ALTER TABLE game_participants DROP COLUMN rebuys;
+ALTER TABLE game_participants DROP COLUMN buy_in;
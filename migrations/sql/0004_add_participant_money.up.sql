@@ -0,0 +1,8 @@
+ALTER TABLE game_participants ADD COLUMN buy_in INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE game_participants ADD COLUMN rebuys INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE game_participants ADD COLUMN payout INTEGER NOT NULL DEFAULT 0;
+
+UPDATE game_participants SET payout = COALESCE((
+    SELECT gr.amount_won FROM game_results AS gr
+    WHERE gr.game_id = game_participants.game_id AND gr.winner_id = game_participants.player_id
+), 0) WHERE position = 1;
@@ -0,0 +1,2 @@
+DROP TABLE season_standings;
+DROP TABLE seasons;
@@ -0,0 +1,16 @@
+CREATE TABLE seasons (
+    id        SERIAL PRIMARY KEY,
+    name      TEXT      NOT NULL UNIQUE,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at   TIMESTAMP,
+    closed_at TIMESTAMP
+);
+
+CREATE TABLE season_standings (
+    season_id INTEGER NOT NULL REFERENCES seasons (id) ON DELETE CASCADE,
+    player_id INTEGER NOT NULL,
+    name      TEXT    NOT NULL,
+    wins      INTEGER NOT NULL,
+    rank      INTEGER NOT NULL,
+    PRIMARY KEY (season_id, player_id)
+);
@@ -0,0 +1 @@
+DROP TABLE reverted_wins;
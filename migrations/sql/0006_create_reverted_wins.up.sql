@@ -0,0 +1,14 @@
+-- A reverted win moves out of game_results, so every query that counts wins
+-- keeps working unchanged, and is kept here with who reverted it and why.
+-- id is the id the row had in game_results.
+CREATE TABLE reverted_wins (
+    id          INTEGER   PRIMARY KEY,
+    game_id     INTEGER   NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+    winner_id   INTEGER   NOT NULL REFERENCES players (id) ON DELETE CASCADE,
+    amount_won  INTEGER   NOT NULL DEFAULT 0,
+    reverted_at TIMESTAMP NOT NULL,
+    reverted_by TEXT      NOT NULL,
+    reason      TEXT      NOT NULL
+);
+
+CREATE INDEX reverted_wins_winner_id_idx ON reverted_wins (winner_id);
@@ -0,0 +1,3 @@
+-- Deleted players are only marked deleted until they are purged, so they can
+-- be restored with their wins.
+ALTER TABLE players ADD COLUMN deleted_at TIMESTAMP;
@@ -0,0 +1,3 @@
+ALTER TABLE players DROP COLUMN revision;
+
+DROP TABLE store_revision;
@@ -0,0 +1,10 @@
+-- Every change to the store takes the next store revision, and the players
+-- it changes remember it, so clients can tell whether what they read is
+-- still current. Players from before this migration are at revision 0.
+CREATE TABLE store_revision (
+    revision BIGINT NOT NULL
+);
+
+INSERT INTO store_revision (revision) VALUES (0);
+
+ALTER TABLE players ADD COLUMN revision BIGINT NOT NULL DEFAULT 0;
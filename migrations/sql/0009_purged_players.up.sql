@@ -0,0 +1,4 @@
+-- Purging a player keeps the results of the games they played. Their row
+-- stays for the results to point at, stripped of their name and email, and
+-- purged_at tells it apart from a deleted player that can be restored.
+ALTER TABLE players ADD COLUMN purged_at TIMESTAMP;
@@ -0,0 +1,2 @@
+-- The starting wins are not turned back into games, so they are lost.
+ALTER TABLE players DROP COLUMN starting_wins;
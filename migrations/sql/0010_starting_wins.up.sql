@@ -0,0 +1,19 @@
+-- A player may join with wins already on the board. They are kept as a count
+-- of their own rather than as games nobody played, so they stay out of the
+-- game history, the win history and the seasons. Earlier versions carried
+-- them over as single player games; those are folded into the count.
+ALTER TABLE players ADD COLUMN starting_wins INTEGER NOT NULL DEFAULT 0;
+
+UPDATE players SET starting_wins = (
+    SELECT COUNT(*)
+    FROM game_results AS gr
+    JOIN games AS g ON g.id = gr.game_id
+    WHERE gr.winner_id = players.id AND g.notes = 'carried over on player creation'
+);
+
+-- Not every connection enforces ON DELETE CASCADE, so the rows that point at
+-- the games go first.
+DELETE FROM game_results WHERE game_id IN (SELECT id FROM games WHERE notes = 'carried over on player creation');
+DELETE FROM reverted_wins WHERE game_id IN (SELECT id FROM games WHERE notes = 'carried over on player creation');
+DELETE FROM game_participants WHERE game_id IN (SELECT id FROM games WHERE notes = 'carried over on player creation');
+DELETE FROM games WHERE notes = 'carried over on player creation';
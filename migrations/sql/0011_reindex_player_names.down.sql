@@ -0,0 +1,2 @@
+-- The index is the same as before, so there is nothing to undo.
+SELECT 1;
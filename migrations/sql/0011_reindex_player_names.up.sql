@@ -0,0 +1,6 @@
+-- The SQLite stores now fold names beyond ASCII in LOWER, so an index built
+-- with SQLite's own LOWER no longer matches the names it holds. Building it
+-- again keeps "Élodie" and "ÉLODIE" from both being taken; it fails on a
+-- database that already has both, which has to be fixed by hand.
+DROP INDEX players_username_lower_idx;
+CREATE UNIQUE INDEX players_username_lower_idx ON players (LOWER(username));
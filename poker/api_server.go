@@ -0,0 +1,213 @@
+package poker
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// apiPrefix is where the resource API lives. The verb-named routes the
+// server started out with stay as deprecated aliases of it.
+const apiPrefix = "/api/v1"
+
+// PlayerPatch is the body of PATCH /api/v1/players/{id}. Fields left out
+// are kept as they are.
+type PlayerPatch struct {
+	Name *string `json:"name" validate:"normalize,required,max=64,charset=name"`
+}
+
+// apiRoutes adds the resource API to router:
+//
+//	GET    /api/v1/players             list the players by id
+//	POST   /api/v1/players             add a player
+//	GET    /api/v1/players/{id}        get a player
+//	PATCH  /api/v1/players/{id}        rename a player, see PlayerPatch
+//	DELETE /api/v1/players/{id}        delete a player
+//	POST   /api/v1/players/{id}/wins   record a win
+//	GET    /api/v1/league              the league, paged like /league/
+//
+// Every answer is JSON, errors are problems, see Problem. When the store is
+// a Revisioner, reads carry an ETag and answer If-None-Match with 304 Not
+// Modified, and changes to a player honour If-Match, answering 412 when the
+// player has moved on from the tag given.
+func (p *PlayerServer) apiRoutes(router *mux.Router) {
+	api := router.PathPrefix(apiPrefix).Subrouter()
+	handleMethods(api, "/players", map[string]http.HandlerFunc{
+		http.MethodGet:  p.listPlayers,
+		http.MethodPost: p.createPlayer,
+	})
+	handleMethods(api, "/players/{id}", map[string]http.HandlerFunc{
+		http.MethodGet:    p.getPlayer,
+		http.MethodPatch:  p.updatePlayer,
+		http.MethodDelete: p.removePlayer,
+	})
+	handleMethods(api, "/players/{id}/wins", map[string]http.HandlerFunc{
+		http.MethodPost: p.addWin,
+	})
+	handleMethods(api, "/league", map[string]http.HandlerFunc{
+		http.MethodGet: p.apiLeague,
+	})
+
+	api.NotFoundHandler = http.HandlerFunc(notFound)
+}
+
+// handleMethods routes the methods of handlers on path and answers any other
+// method with 405 and the methods that are allowed.
+func handleMethods(router *mux.Router, path string, handlers map[string]http.HandlerFunc) {
+	allowed := make([]string, 0, len(handlers))
+	for method, handler := range handlers {
+		router.HandleFunc(path, handler).Methods(method)
+		allowed = append(allowed, method)
+	}
+	sort.Strings(allowed)
+	router.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		methodNotAllowed(w, r, allowed...)
+	})
+}
+
+// deprecated marks the answers of the legacy route at prefix with a
+// Deprecation header and links to the route of the resource API that
+// replaces it. An id at the end of the legacy path is carried over.
+func deprecated(prefix, successor string, next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		link := apiPrefix + successor
+		if id := strings.Trim(strings.TrimPrefix(r.URL.Path, prefix), "/"); id != "" {
+			link += "/" + id
+		}
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, link))
+		next(w, r)
+	})
+}
+
+// playerID reads the {id} of the route, answering with 400 when it is not a
+// number.
+func playerID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		badRequest(w, "player id must be a number, got %q", mux.Vars(r)["id"])
+		return 0, false
+	}
+	return id, true
+}
+
+func (p *PlayerServer) listPlayers(w http.ResponseWriter, r *http.Request) {
+	if p.storeNotModified(w, r, playerTag) {
+		return
+	}
+	league, err := p.store.GetLeague(r.Context())
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	sort.Slice(league, func(i, j int) bool {
+		return league[i].ID < league[j].ID
+	})
+	writeJSON(w, http.StatusOK, league)
+}
+
+func (p *PlayerServer) createPlayer(w http.ResponseWriter, r *http.Request) {
+	var input PlayerInput
+	if !validRequest(w, r, &input, ErrInvalidPlayer) {
+		return
+	}
+	player := input.Player()
+	if err := p.store.AddPlayer(r.Context(), &player); err != nil {
+		storeError(w, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("%s/players/%d", apiPrefix, player.ID))
+	writeJSON(w, http.StatusCreated, player)
+}
+
+func (p *PlayerServer) getPlayer(w http.ResponseWriter, r *http.Request) {
+	id, ok := playerID(w, r)
+	if !ok {
+		return
+	}
+	if p.playerNotModified(w, r, id) {
+		return
+	}
+	player, err := p.store.GetPlayer(r.Context(), id)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, player)
+}
+
+func (p *PlayerServer) updatePlayer(w http.ResponseWriter, r *http.Request) {
+	id, ok := playerID(w, r)
+	if !ok {
+		return
+	}
+	var patch PlayerPatch
+	if !validRequest(w, r, &patch, ErrInvalidPlayer) {
+		return
+	}
+	r, ok = p.ifMatch(w, r, id)
+	if !ok {
+		return
+	}
+	if patch.Name == nil {
+		p.getPlayer(w, r)
+		return
+	}
+	if p.renamer == nil {
+		notSupported(w, "renaming players is not supported by this store")
+		return
+	}
+	player, err := p.renamer.RenamePlayer(r.Context(), id, *patch.Name)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	p.tagPlayer(w, r, id)
+	writeJSON(w, http.StatusOK, player)
+}
+
+func (p *PlayerServer) removePlayer(w http.ResponseWriter, r *http.Request) {
+	id, ok := playerID(w, r)
+	if !ok {
+		return
+	}
+	r, ok = p.ifMatch(w, r, id)
+	if !ok {
+		return
+	}
+	if err := p.store.DeletePlayer(r.Context(), id); err != nil {
+		storeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (p *PlayerServer) addWin(w http.ResponseWriter, r *http.Request) {
+	id, ok := playerID(w, r)
+	if !ok {
+		return
+	}
+	r, ok = p.ifMatch(w, r, id)
+	if !ok {
+		return
+	}
+	if err := p.store.RecordWin(r.Context(), id); err != nil {
+		storeError(w, err)
+		return
+	}
+	player, err := p.store.GetPlayer(r.Context(), id)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	p.tagPlayer(w, r, id)
+	writeJSON(w, http.StatusOK, player)
+}
+
+func (p *PlayerServer) apiLeague(w http.ResponseWriter, r *http.Request) {
+	p.serveLeague(w, r)
+}
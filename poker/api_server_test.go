@@ -0,0 +1,121 @@
+package poker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestPlayerResourceAPI(t *testing.T) {
+	server := NewPlayerServer(createTestDatabase(t))
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest(method, path, body))
+		return response
+	}
+	decodePlayer := func(t *testing.T, response *httptest.ResponseRecorder) Player {
+		t.Helper()
+		var player Player
+		if err := json.NewDecoder(response.Body).Decode(&player); err != nil {
+			t.Fatalf("unable to parse player from response %q, %v", response.Body, err)
+		}
+		return player
+	}
+
+	t.Run("creates players", func(t *testing.T) {
+		for _, name := range []string{"Cleo", "Chris"} {
+			response := serve(http.MethodPost, "/api/v1/players", `{"name": "`+name+`"}`)
+			assertStatus(t, response.Code, http.StatusCreated)
+			player := decodePlayer(t, response)
+			if want := "/api/v1/players/" + strconv.Itoa(player.ID); response.Header().Get("Location") != want {
+				t.Errorf("got location %q want %q", response.Header().Get("Location"), want)
+			}
+		}
+		assertStatus(t, serve(http.MethodPost, "/api/v1/players", `{"name": "cleo"}`).Code, http.StatusConflict)
+	})
+
+	t.Run("records a win and gets the player", func(t *testing.T) {
+		response := serve(http.MethodPost, "/api/v1/players/2/wins", "")
+		assertStatus(t, response.Code, http.StatusOK)
+		if player := decodePlayer(t, response); player.Wins != 1 {
+			t.Errorf("expected 1 win, got %+v", player)
+		}
+
+		response = serve(http.MethodGet, "/api/v1/players/2", "")
+		assertStatus(t, response.Code, http.StatusOK)
+		if player := decodePlayer(t, response); player != (Player{ID: 2, Name: "Chris", Wins: 1}) {
+			t.Errorf("got player %+v", player)
+		}
+	})
+
+	t.Run("lists players by id", func(t *testing.T) {
+		response := serve(http.MethodGet, "/api/v1/players", "")
+		assertStatus(t, response.Code, http.StatusOK)
+		var players League
+		if err := json.NewDecoder(response.Body).Decode(&players); err != nil {
+			t.Fatalf("unable to parse players from response %q, %v", response.Body, err)
+		}
+		if len(players) != 2 || players[0].ID != 1 || players[1].ID != 2 {
+			t.Errorf("expected players 1 and 2 in order, got %+v", players)
+		}
+	})
+
+	t.Run("renames a player", func(t *testing.T) {
+		response := serve(http.MethodPatch, "/api/v1/players/1", `{"name": "Cleopatra"}`)
+		assertStatus(t, response.Code, http.StatusOK)
+		if player := decodePlayer(t, response); player.Name != "Cleopatra" {
+			t.Errorf("expected the new name, got %+v", player)
+		}
+		assertStatus(t, serve(http.MethodPatch, "/api/v1/players/1", `{"name": "Chris"}`).Code, http.StatusConflict)
+	})
+
+	t.Run("serves the league", func(t *testing.T) {
+		response := serve(http.MethodGet, "/api/v1/league", "")
+		assertStatus(t, response.Code, http.StatusOK)
+		if got := response.Header().Get(scoringRulesHeader); got != DefaultRuleSet.Name {
+			t.Errorf("got scoring rules %q want %q", got, DefaultRuleSet.Name)
+		}
+	})
+
+	t.Run("deletes a player", func(t *testing.T) {
+		assertStatus(t, serve(http.MethodDelete, "/api/v1/players/1", "").Code, http.StatusNoContent)
+		assertStatus(t, serve(http.MethodGet, "/api/v1/players/1", "").Code, http.StatusNotFound)
+	})
+
+	t.Run("answers errors as problems", func(t *testing.T) {
+		for _, tt := range []struct {
+			method, path string
+			want         int
+			code         string
+		}{
+			{http.MethodGet, "/api/v1/players/99", http.StatusNotFound, CodePlayerNotFound},
+			{http.MethodGet, "/api/v1/players/abc", http.StatusBadRequest, CodeBadRequest},
+			{http.MethodGet, "/api/v1/nothing", http.StatusNotFound, CodeNotFound},
+			{http.MethodPut, "/api/v1/players/2", http.StatusMethodNotAllowed, CodeMethodNotAllowed},
+		} {
+			response := serve(tt.method, tt.path, "")
+			assertStatus(t, response.Code, tt.want)
+			assertProblem(t, response, tt.want, tt.code)
+		}
+	})
+
+	t.Run("keeps the legacy routes as deprecated aliases", func(t *testing.T) {
+		response := serve(http.MethodGet, "/info/2", "")
+		assertStatus(t, response.Code, http.StatusOK)
+		if response.Header().Get("Deprecation") != "true" {
+			t.Error("expected the legacy route to be marked deprecated")
+		}
+		if got, want := response.Header().Get("Link"), `</api/v1/players/2>; rel="successor-version"`; got != want {
+			t.Errorf("got link %q want %q", got, want)
+		}
+	})
+}
+
+func TestRenamingNeedsAStoreThatRenames(t *testing.T) {
+	server := NewPlayerServer(&StubPlayerStore{Scores: map[int]int{1: 0}})
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, newGameRequest(http.MethodPatch, "/api/v1/players/1", `{"name": "Cleo"}`))
+	assertStatus(t, response.Code, http.StatusNotImplemented)
+}
@@ -0,0 +1,487 @@
+package poker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+// Source is the surface a change came in through.
+type Source string
+
+const (
+	SourceREST    Source = "REST"
+	SourceGraphQL Source = "GraphQL"
+	SourceCLI     Source = "CLI"
+)
+
+// AdminRole is the role an Actor needs to read the audit log. It matches the
+// role the JWT and GitHub OAuth logins hand out to admins.
+const AdminRole = "admin"
+
+// Actor is who made a change, as an authentication middleware identified
+// them.
+type Actor struct {
+	Name string `json:"name"`
+	Role string `json:"role,omitempty"`
+}
+
+type actorKey struct{}
+type sourceKey struct{}
+type requestIDKey struct{}
+
+// WithActor returns a copy of ctx that carries the actor making the request.
+// Authentication middlewares call it once they know who is calling.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor carried by ctx, which has an empty name when
+// nobody was identified.
+func ActorFrom(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorKey{}).(Actor)
+	return actor
+}
+
+// WithSource returns a copy of ctx that records which surface a request came
+// in through.
+func WithSource(ctx context.Context, source Source) context.Context {
+	return context.WithValue(ctx, sourceKey{}, source)
+}
+
+// WithRequestID returns a copy of ctx that carries the id of the request, so
+// audit entries can be matched with logs.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// AuditEntry is one change recorded in the audit log. Before is nil for an
+// added player and After for a deleted one. Changes to games and seasons
+// name them by GameID and SeasonID and keep what was recorded in Detail:
+// the game, the stakes, the finishing order, the season or the reverted win.
+type AuditEntry struct {
+	At        time.Time       `json:"at"`
+	Actor     Actor           `json:"actor"`
+	Source    Source          `json:"source,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+	Action    string          `json:"action"`
+	PlayerID  int             `json:"player_id"`
+	GameID    int             `json:"game_id,omitempty"`
+	SeasonID  int             `json:"season_id,omitempty"`
+	Before    *Player         `json:"before"`
+	After     *Player         `json:"after"`
+	Detail    json.RawMessage `json:"detail,omitempty"`
+}
+
+// AuditLog keeps audit entries. List returns a page of entries, newest
+// first, along with the number of entries in the log.
+type AuditLog interface {
+	Append(ctx context.Context, entry AuditEntry) error
+	List(ctx context.Context, offset, limit int) ([]AuditEntry, int, error)
+	// Export writes every entry as a JSON line, oldest first.
+	Export(ctx context.Context, out io.Writer) error
+}
+
+// AuditedStore records every AddPlayer, RecordWin and DeletePlayer that
+// succeeds in its log, with the actor, source and request id carried by the
+// context. Reads go straight to the store underneath. The optional store
+// interfaces that change the store are audited by AuditGames, AuditMoney,
+// AuditSeasons, AuditWins, AuditDeleted and AuditRenames.
+//
+// A change that was made but could not be logged is reported as an error,
+// so it does not go unnoticed.
+type AuditedStore struct {
+	PlayerStoreV2
+	log AuditLog
+}
+
+func NewAuditedStore(store PlayerStoreV2, log AuditLog) *AuditedStore {
+	return &AuditedStore{PlayerStoreV2: store, log: log}
+}
+
+// Unwrap returns the store underneath, so StoreFeature can find the
+// optional interfaces it implements.
+func (a *AuditedStore) Unwrap() PlayerStoreV2 {
+	return a.PlayerStoreV2
+}
+
+func (a *AuditedStore) AddPlayer(ctx context.Context, player *Player) error {
+	if err := a.PlayerStoreV2.AddPlayer(ctx, player); err != nil {
+		return err
+	}
+	after := *player
+	return a.record(ctx, "AddPlayer", player.ID, nil, &after)
+}
+
+func (a *AuditedStore) RecordWin(ctx context.Context, id int) error {
+	before, err := a.PlayerStoreV2.GetPlayer(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := a.PlayerStoreV2.RecordWin(ctx, id); err != nil {
+		return err
+	}
+	after, err := a.PlayerStoreV2.GetPlayer(ctx, id)
+	if err != nil {
+		return err
+	}
+	return a.record(ctx, "RecordWin", id, &before, &after)
+}
+
+func (a *AuditedStore) DeletePlayer(ctx context.Context, id int) error {
+	before, err := a.PlayerStoreV2.GetPlayer(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := a.PlayerStoreV2.DeletePlayer(ctx, id); err != nil {
+		return err
+	}
+	return a.record(ctx, "DeletePlayer", id, &before, nil)
+}
+
+func (a *AuditedStore) record(ctx context.Context, action string, playerID int, before, after *Player) error {
+	return a.recordEntry(ctx, AuditEntry{Action: action, PlayerID: playerID, Before: before, After: after}, nil)
+}
+
+// recordEntry fills in when, by whom and through what entry was made, along
+// with detail, and logs it.
+func (a *AuditedStore) recordEntry(ctx context.Context, entry AuditEntry, detail any) error {
+	entry.At = time.Now().UTC()
+	entry.Actor = ActorFrom(ctx)
+	entry.Source, _ = ctx.Value(sourceKey{}).(Source)
+	entry.RequestID, _ = ctx.Value(requestIDKey{}).(string)
+	if detail != nil {
+		data, err := json.Marshal(detail)
+		if err != nil {
+			return fmt.Errorf("%s was saved but not audited, %w", entry.subject(), err)
+		}
+		entry.Detail = data
+	}
+
+	// The change is made, so it is logged even if the caller has given up.
+	if err := a.log.Append(context.WithoutCancel(ctx), entry); err != nil {
+		return fmt.Errorf("%s was saved but not audited, %w", entry.subject(), err)
+	}
+	return nil
+}
+
+// subject names the change entry records in errors.
+func (entry AuditEntry) subject() string {
+	switch {
+	case entry.GameID != 0:
+		return fmt.Sprintf("%s of game %d", entry.Action, entry.GameID)
+	case entry.SeasonID != 0:
+		return fmt.Sprintf("%s of season %d", entry.Action, entry.SeasonID)
+	}
+	return fmt.Sprintf("%s of player %d", entry.Action, entry.PlayerID)
+}
+
+// FileAuditLog appends audit entries to a JSON lines file, which makes the
+// file itself the export.
+type FileAuditLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileAuditLog(path string) *FileAuditLog {
+	return &FileAuditLog{path: path}
+}
+
+func (f *FileAuditLog) Append(ctx context.Context, entry AuditEntry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := trimPartialLine(f.path); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return fmt.Errorf("problem opening audit log %s, %v", f.path, err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("problem appending to audit log %s, %v", f.path, err)
+	}
+	return file.Sync()
+}
+
+func (f *FileAuditLog) List(ctx context.Context, offset, limit int) ([]AuditEntry, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	f.mu.Lock()
+	entries, err := f.read()
+	f.mu.Unlock()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := len(entries)
+	page := []AuditEntry{}
+	for i := total - 1 - offset; i >= 0 && len(page) < limit; i-- {
+		page = append(page, entries[i])
+	}
+	return page, total, nil
+}
+
+func (f *FileAuditLog) Export(ctx context.Context, out io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	entries, err := f.read()
+	f.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(out)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *FileAuditLog) read() ([]AuditEntry, error) {
+	file, err := os.Open(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("problem opening audit log %s, %v", f.path, err)
+	}
+	defer file.Close()
+
+	var entries []AuditEntry
+	reader := bufio.NewReader(file)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// A line without its newline is a write that never finished.
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("problem reading audit log %s, %v", f.path, err)
+		}
+		var entry AuditEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("problem parsing audit log %s line %d, %v", f.path, line, err)
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// AuditDeleted records the players restored and purged through store in log,
+// the way AuditedStore records the other changes.
+func AuditDeleted(store DeletedPlayerStore, log AuditLog) DeletedPlayerStore {
+	return auditedDeletedStore{DeletedPlayerStore: store, audited: &AuditedStore{log: log}}
+}
+
+type auditedDeletedStore struct {
+	DeletedPlayerStore
+	audited *AuditedStore
+}
+
+func (a auditedDeletedStore) RestorePlayer(ctx context.Context, id int) (Player, error) {
+	before, err := a.deletedPlayer(ctx, id)
+	if err != nil {
+		return Player{}, err
+	}
+	after, err := a.DeletedPlayerStore.RestorePlayer(ctx, id)
+	if err != nil {
+		return Player{}, err
+	}
+	return after, a.audited.record(ctx, "RestorePlayer", id, &before, &after)
+}
+
+func (a auditedDeletedStore) PurgePlayers(ctx context.Context, cutoff time.Time) (int, error) {
+	deleted, err := a.DeletedPlayers(ctx)
+	if err != nil {
+		return 0, err
+	}
+	purged, err := a.DeletedPlayerStore.PurgePlayers(ctx, cutoff)
+	if err != nil {
+		return purged, err
+	}
+	for _, player := range deleted {
+		if !player.DeletedAt.Before(cutoff) {
+			continue
+		}
+		before := player
+		if err := a.audited.record(ctx, "PurgePlayer", player.ID, &before, nil); err != nil {
+			return purged, err
+		}
+	}
+	return purged, nil
+}
+
+func (a auditedDeletedStore) deletedPlayer(ctx context.Context, id int) (Player, error) {
+	deleted, err := a.DeletedPlayers(ctx)
+	if err != nil {
+		return Player{}, err
+	}
+	if player := deleted.Find(id); player != nil {
+		return *player, nil
+	}
+	return Player{}, noDeletedPlayer(id)
+}
+
+// AuditRenames records the players renamed through renamer, which renames
+// the players of store, in log.
+func AuditRenames(store PlayerStoreV2, renamer PlayerRenamer, log AuditLog) PlayerRenamer {
+	return auditedRenamer{PlayerRenamer: renamer, audited: NewAuditedStore(store, log)}
+}
+
+type auditedRenamer struct {
+	PlayerRenamer
+	audited *AuditedStore
+}
+
+func (a auditedRenamer) RenamePlayer(ctx context.Context, id int, name string) (Player, error) {
+	before, err := a.audited.GetPlayer(ctx, id)
+	if err != nil {
+		return Player{}, err
+	}
+	after, err := a.PlayerRenamer.RenamePlayer(ctx, id, name)
+	if err != nil {
+		return Player{}, err
+	}
+	return after, a.audited.record(ctx, "RenamePlayer", id, &before, &after)
+}
+
+// AuditGames records the games created and finished through games, which
+// keeps the games of store, in log. A finished game is logged as a win
+// recorded for its winner.
+func AuditGames(store PlayerStoreV2, games GameStore, log AuditLog) GameStore {
+	return auditedGameStore{GameStore: games, audited: NewAuditedStore(store, log)}
+}
+
+type auditedGameStore struct {
+	GameStore
+	audited *AuditedStore
+}
+
+func (a auditedGameStore) CreateGame(ctx context.Context, game *GameRecord) error {
+	if err := a.GameStore.CreateGame(ctx, game); err != nil {
+		return err
+	}
+	return a.audited.recordEntry(ctx, AuditEntry{Action: "CreateGame", GameID: game.ID}, game)
+}
+
+func (a auditedGameStore) RecordResult(ctx context.Context, gameID int, finishingOrder []int) error {
+	if len(finishingOrder) == 0 {
+		return a.GameStore.RecordResult(ctx, gameID, finishingOrder)
+	}
+	// A winner the store does not know is the store's to report.
+	entry := AuditEntry{Action: "RecordWin", PlayerID: finishingOrder[0], GameID: gameID}
+	if before, err := a.audited.GetPlayer(ctx, entry.PlayerID); err == nil {
+		entry.Before = &before
+	}
+	if err := a.GameStore.RecordResult(ctx, gameID, finishingOrder); err != nil {
+		return err
+	}
+	after, err := a.audited.GetPlayer(ctx, entry.PlayerID)
+	if err != nil {
+		return err
+	}
+	entry.After = &after
+	return a.audited.recordEntry(ctx, entry, finishingOrder)
+}
+
+// AuditMoney records the stakes recorded through money in log.
+func AuditMoney(money MoneyStore, log AuditLog) MoneyStore {
+	return auditedMoneyStore{MoneyStore: money, audited: &AuditedStore{log: log}}
+}
+
+type auditedMoneyStore struct {
+	MoneyStore
+	audited *AuditedStore
+}
+
+func (a auditedMoneyStore) RecordMoney(ctx context.Context, gameID int, stakes []Stake) error {
+	if err := a.MoneyStore.RecordMoney(ctx, gameID, stakes); err != nil {
+		return err
+	}
+	return a.audited.recordEntry(ctx, AuditEntry{Action: "RecordMoney", GameID: gameID}, stakes)
+}
+
+// AuditSeasons records the seasons created and closed through seasons in
+// log.
+func AuditSeasons(seasons SeasonStore, log AuditLog) SeasonStore {
+	return auditedSeasonStore{SeasonStore: seasons, audited: &AuditedStore{log: log}}
+}
+
+type auditedSeasonStore struct {
+	SeasonStore
+	audited *AuditedStore
+}
+
+func (a auditedSeasonStore) CreateSeason(ctx context.Context, season *Season) error {
+	if err := a.SeasonStore.CreateSeason(ctx, season); err != nil {
+		return err
+	}
+	return a.audited.recordEntry(ctx, AuditEntry{Action: "CreateSeason", SeasonID: season.ID}, season)
+}
+
+func (a auditedSeasonStore) CloseSeason(ctx context.Context, id int) (Season, error) {
+	season, err := a.SeasonStore.CloseSeason(ctx, id)
+	if err != nil {
+		return Season{}, err
+	}
+	return season, a.audited.recordEntry(ctx, AuditEntry{Action: "CloseSeason", SeasonID: id}, season)
+}
+
+// AuditWins records the wins reverted through wins, which keeps the wins of
+// store, in log, along with who reverted them and why.
+func AuditWins(store PlayerStoreV2, wins WinReverter, log AuditLog) WinReverter {
+	return auditedWinReverter{WinReverter: wins, audited: NewAuditedStore(store, log)}
+}
+
+type auditedWinReverter struct {
+	WinReverter
+	audited *AuditedStore
+}
+
+func (a auditedWinReverter) UndoLastWin(ctx context.Context, playerID int, reversion Reversion) (Win, error) {
+	win, err := a.WinReverter.UndoLastWin(ctx, playerID, reversion)
+	if err != nil {
+		return Win{}, err
+	}
+	return win, a.revertedWin(ctx, "UndoLastWin", win)
+}
+
+func (a auditedWinReverter) RevokeWin(ctx context.Context, winID int, reversion Reversion) (Win, error) {
+	win, err := a.WinReverter.RevokeWin(ctx, winID, reversion)
+	if err != nil {
+		return Win{}, err
+	}
+	return win, a.revertedWin(ctx, "RevokeWin", win)
+}
+
+// revertedWin logs win, just reverted, with the player it was taken from as
+// they are now. Their wins before it were one more.
+func (a auditedWinReverter) revertedWin(ctx context.Context, action string, win Win) error {
+	entry := AuditEntry{Action: action, PlayerID: win.PlayerID, GameID: win.GameID}
+	if after, err := a.audited.GetPlayer(ctx, win.PlayerID); err == nil {
+		before := after
+		before.Wins++
+		entry.Before, entry.After = &before, &after
+	}
+	return a.audited.recordEntry(ctx, entry, win)
+}
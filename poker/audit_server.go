@@ -0,0 +1,117 @@
+package poker
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// requestIDHeader carries the request id. A client or proxy may set it; the
+// server makes one up otherwise and always sends it back.
+const requestIDHeader = "X-Request-ID"
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// AuditPage is a page of the audit log, newest entries first. Next links to
+// the following page and is left out on the last one.
+type AuditPage struct {
+	Entries []AuditEntry `json:"entries"`
+	Total   int          `json:"total"`
+	Offset  int          `json:"offset"`
+	Limit   int          `json:"limit"`
+	Next    string       `json:"next,omitempty"`
+}
+
+// RequestContextHandler tags every request with the surface it came in
+// through and its request id, for the audit log.
+func RequestContextHandler(next http.Handler, source Source) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if id == "" {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+		ctx := WithRequestID(WithSource(r.Context(), source), id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func newRequestID() string {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// GET /audit?offset=&limit=
+func (p *PlayerServer) auditHandler(w http.ResponseWriter, r *http.Request) {
+	if !p.auditAllowed(w, r) {
+		return
+	}
+	page := AuditPage{Limit: defaultAuditLimit}
+	query := r.URL.Query()
+	for name, target := range map[string]*int{"offset": &page.Offset, "limit": &page.Limit} {
+		if value := query.Get(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				badRequest(w, "%s must be a non-negative number, got %q", name, value)
+				return
+			}
+			*target = n
+		}
+	}
+	page.Limit = min(page.Limit, maxAuditLimit)
+
+	var err error
+	page.Entries, page.Total, err = p.audit.List(r.Context(), page.Offset, page.Limit)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	if next := page.Offset + len(page.Entries); len(page.Entries) > 0 && next < page.Total {
+		page.Next = fmt.Sprintf("/audit?offset=%d&limit=%d", next, page.Limit)
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// GET /audit/export
+func (p *PlayerServer) auditExportHandler(w http.ResponseWriter, r *http.Request) {
+	if !p.auditAllowed(w, r) {
+		return
+	}
+	var export bytes.Buffer
+	if err := p.audit.Export(r.Context(), &export); err != nil {
+		storeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit.jsonl"`)
+	w.Write(export.Bytes())
+}
+
+// auditAllowed answers the request itself unless it is a GET from an admin
+// on a server that keeps an audit log.
+func (p *PlayerServer) auditAllowed(w http.ResponseWriter, r *http.Request) bool {
+	if !allowMethod(w, r, http.MethodGet) {
+		return false
+	}
+	if p.audit == nil {
+		notSupported(w, "this server keeps no audit log")
+		return false
+	}
+	return requireAdmin(w, r, "the audit log")
+}
+
+// requireAdmin answers with 403 unless the request comes from an admin.
+func requireAdmin(w http.ResponseWriter, r *http.Request, what string) bool {
+	if ActorFrom(r.Context()).Role != AdminRole {
+		writeProblem(w, http.StatusForbidden, CodeForbidden, what+" is only open to admins")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,240 @@
+package poker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditedStore(t *testing.T) {
+	log := NewFileAuditLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	store := NewAuditedStore(createTestDatabase(t).V2(), log)
+
+	ctx := WithRequestID(WithSource(WithActor(context.Background(), Actor{Name: "cleo"}), SourceREST), "req-1")
+	cleo := &Player{Name: "Cleo"}
+	assertNoError(t, store.AddPlayer(ctx, cleo))
+	assertNoError(t, store.RecordWin(ctx, cleo.ID))
+	assertError(t, store.RecordWin(ctx, 99))
+	assertNoError(t, store.DeletePlayer(ctx, cleo.ID))
+
+	entries, total, err := log.List(context.Background(), 0, 10)
+	assertNoError(t, err)
+	if total != 3 || len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d of %d", len(entries), total)
+	}
+
+	deleted, won, added := entries[0], entries[1], entries[2]
+	if added.Action != "AddPlayer" || added.Before != nil || added.After == nil || added.After.ID != cleo.ID {
+		t.Errorf("unexpected AddPlayer entry %+v", added)
+	}
+	if won.Action != "RecordWin" || won.Before.Wins != 0 || won.After.Wins != 1 {
+		t.Errorf("unexpected RecordWin entry %+v", won)
+	}
+	if deleted.Action != "DeletePlayer" || deleted.Before.Wins != 1 || deleted.After != nil {
+		t.Errorf("unexpected DeletePlayer entry %+v", deleted)
+	}
+	for _, entry := range entries {
+		if entry.Actor.Name != "cleo" || entry.Source != SourceREST || entry.RequestID != "req-1" || entry.At.IsZero() {
+			t.Errorf("expected who, where and when on %+v", entry)
+		}
+	}
+
+	if _, ok := StoreFeature[GameStore](store); !ok {
+		t.Error("expected the audited store to keep the game history of the store underneath")
+	}
+}
+
+func TestFileAuditLogPages(t *testing.T) {
+	log := NewFileAuditLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	for id := 1; id <= 5; id++ {
+		assertNoError(t, log.Append(context.Background(), AuditEntry{Action: "RecordWin", PlayerID: id}))
+	}
+
+	page, total, err := log.List(context.Background(), 1, 2)
+	assertNoError(t, err)
+	if total != 5 || len(page) != 2 || page[0].PlayerID != 4 || page[1].PlayerID != 3 {
+		t.Errorf("expected entries 4 and 3 of 5, got %+v of %d", page, total)
+	}
+
+	page, _, err = log.List(context.Background(), 10, 2)
+	assertNoError(t, err)
+	if len(page) != 0 {
+		t.Errorf("expected no entries past the end, got %+v", page)
+	}
+}
+
+func TestAuditEndpoints(t *testing.T) {
+	log := NewFileAuditLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	server := NewPlayerServer(createTestDatabase(t), WithAuditLog(log))
+	admin := func(r *http.Request) *http.Request {
+		return r.WithContext(WithActor(r.Context(), Actor{Name: "root", Role: AdminRole}))
+	}
+
+	for _, name := range []string{"Cleo", "Chris", "Lloyd"} {
+		request := newGameRequest(http.MethodPost, "/create/", `{"name": "`+name+`"}`)
+		request.Header.Set(requestIDHeader, "create-"+name)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		assertStatus(t, response.Code, http.StatusCreated)
+		if got := response.Header().Get(requestIDHeader); got != "create-"+name {
+			t.Errorf("got request id %q want %q", got, "create-"+name)
+		}
+	}
+
+	t.Run("pages through the log", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, admin(newGameRequest(http.MethodGet, "/audit?limit=2", "")))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		var page AuditPage
+		if err := json.NewDecoder(response.Body).Decode(&page); err != nil {
+			t.Fatalf("unable to parse audit page from response %q, %v", response.Body, err)
+		}
+		if page.Total != 3 || len(page.Entries) != 2 || page.Next != "/audit?offset=2&limit=2" {
+			t.Errorf("unexpected first page %+v", page)
+		}
+		if entry := page.Entries[0]; entry.RequestID != "create-Lloyd" || entry.Source != SourceREST {
+			t.Errorf("expected the newest entry first with its request id, got %+v", entry)
+		}
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, admin(newGameRequest(http.MethodGet, page.Next, "")))
+		page = AuditPage{}
+		if err := json.NewDecoder(response.Body).Decode(&page); err != nil {
+			t.Fatalf("unable to parse audit page from response %q, %v", response.Body, err)
+		}
+		if len(page.Entries) != 1 || page.Next != "" {
+			t.Errorf("unexpected last page %+v", page)
+		}
+	})
+
+	t.Run("exports JSON lines", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, admin(newGameRequest(http.MethodGet, "/audit/export", "")))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		var names []string
+		scanner := bufio.NewScanner(strings.NewReader(response.Body.String()))
+		for scanner.Scan() {
+			var entry AuditEntry
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				t.Fatalf("unable to parse audit line %q, %v", scanner.Text(), err)
+			}
+			names = append(names, entry.After.Name)
+		}
+		if strings.Join(names, ",") != "Cleo,Chris,Lloyd" {
+			t.Errorf("expected the entries oldest first, got %v", names)
+		}
+	})
+
+	t.Run("only admins may read it", func(t *testing.T) {
+		for _, path := range []string{"/audit", "/audit/export"} {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newGameRequest(http.MethodGet, path, ""))
+			assertStatus(t, response.Code, http.StatusForbidden)
+		}
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, admin(newGameRequest(http.MethodGet, "/audit?limit=x", "")))
+		assertStatus(t, response.Code, http.StatusBadRequest)
+
+		response = httptest.NewRecorder()
+		NewPlayerServer(&StubPlayerStore{}).ServeHTTP(response, admin(newGameRequest(http.MethodGet, "/audit", "")))
+		assertStatus(t, response.Code, http.StatusNotImplemented)
+	})
+}
+
+func TestAuditedFeatures(t *testing.T) {
+	log := NewFileAuditLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	store := createTestDatabase(t)
+	assertNoError(t, store.AddPlayer(&Player{ID: 1, Name: "Cleo"}))
+	assertNoError(t, store.AddPlayer(&Player{ID: 2, Name: "Chris"}))
+	server := NewPlayerServer(store, WithAuditLog(log))
+
+	for _, tt := range []struct {
+		method, path, body string
+		want               int
+	}{
+		{http.MethodPost, "/games/", `{"participants": [1, 2]}`, http.StatusCreated},
+		{http.MethodPost, "/games/1/result", `{"finishing_order": [2, 1]}`, http.StatusOK},
+		{http.MethodPut, "/games/1/money", `[{"player_id": 2, "buy_in": 1000, "payout": 2000}]`, http.StatusOK},
+		{http.MethodPost, "/players/2/wins/undo", `{"by": "Cleo", "reason": "meant me"}`, http.StatusOK},
+		{http.MethodPost, "/seasons/", `{"name": "Spring"}`, http.StatusCreated},
+		{http.MethodPost, "/seasons/1/close", "", http.StatusOK},
+	} {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest(tt.method, tt.path, tt.body))
+		assertStatus(t, response.Code, tt.want)
+	}
+
+	entries, _, err := log.List(context.Background(), 0, 10)
+	assertNoError(t, err)
+	var actions []string
+	for i := len(entries) - 1; i >= 0; i-- {
+		actions = append(actions, entries[i].Action)
+	}
+	if got := strings.Join(actions, ","); got != "CreateGame,RecordWin,RecordMoney,UndoLastWin,CreateSeason,CloseSeason" {
+		t.Fatalf("unexpected audit trail %s", got)
+	}
+
+	win := entries[4]
+	if win.GameID != 1 || win.PlayerID != 2 || win.Before.Wins != 0 || win.After.Wins != 1 || win.Source != SourceREST {
+		t.Errorf("expected the result audited as a win of Chris in game 1, got %+v", win)
+	}
+	undo := entries[2]
+	if undo.Before.Wins != 1 || undo.After.Wins != 0 || !strings.Contains(string(undo.Detail), "meant me") {
+		t.Errorf("expected the undone win with its reason, got %+v", undo)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	key := []byte("test key")
+	log := NewFileAuditLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	server := Authenticate(NewPlayerServer(createTestDatabase(t), WithAuditLog(log)), "admin secret", key)
+	serve := func(method, path, body, token string) *httptest.ResponseRecorder {
+		request := newGameRequest(method, path, body)
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		return response
+	}
+
+	t.Run("audits changes under the actor of a JWT", func(t *testing.T) {
+		token, err := NewActorToken(Actor{Name: "cleo", Role: "writer"}, key, time.Hour)
+		assertNoError(t, err)
+		assertStatus(t, serve(http.MethodPost, "/create/", `{"name": "Cleo"}`, token).Code, http.StatusCreated)
+
+		entries, _, err := log.List(context.Background(), 0, 1)
+		assertNoError(t, err)
+		if len(entries) != 1 || entries[0].Actor != (Actor{Name: "cleo", Role: "writer"}) {
+			t.Errorf("expected the change audited under cleo, got %+v", entries)
+		}
+		assertStatus(t, serve(http.MethodGet, "/audit", "", token).Code, http.StatusForbidden)
+	})
+
+	t.Run("lets the admin token read the audit log", func(t *testing.T) {
+		assertStatus(t, serve(http.MethodGet, "/audit", "", "admin secret").Code, http.StatusOK)
+	})
+
+	t.Run("serves requests without a token anonymously", func(t *testing.T) {
+		assertStatus(t, serve(http.MethodGet, "/league/", "", "").Code, http.StatusOK)
+	})
+
+	t.Run("refuses tokens it cannot check", func(t *testing.T) {
+		expired, err := NewActorToken(Actor{Name: "cleo"}, key, -time.Minute)
+		assertNoError(t, err)
+		forged, err := NewActorToken(Actor{Name: "cleo", Role: AdminRole}, []byte("other key"), time.Hour)
+		assertNoError(t, err)
+		for _, token := range []string{expired, forged, "nonsense"} {
+			assertProblem(t, serve(http.MethodGet, "/league/", "", token), http.StatusUnauthorized, CodeUnauthorized)
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package poker
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ActorClaims are the claims of the JWTs that name an Actor. They are signed
+// with HS256.
+type ActorClaims struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// NewActorToken returns a JWT for actor, signed with key, that expires after
+// ttl.
+func NewActorToken(actor Actor, key []byte, ttl time.Duration) (string, error) {
+	claims := &ActorClaims{
+		Username: actor.Name,
+		Role:     actor.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+}
+
+// ParseActorToken checks that token is a JWT signed with key that has not
+// expired and returns the actor it names.
+func ParseActorToken(token string, key []byte) (Actor, error) {
+	if len(key) == 0 {
+		return Actor{}, errors.New("no key to check tokens with")
+	}
+	claims := &ActorClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return key, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return Actor{}, fmt.Errorf("invalid token, %v", err)
+	}
+	if claims.Username == "" {
+		return Actor{}, errors.New("invalid token, it names no user")
+	}
+	return Actor{Name: claims.Username, Role: claims.Role}, nil
+}
+
+// Authenticate identifies who makes each request from its bearer token, so
+// changes are audited under their name: adminToken, when set, lets the admin
+// in, and a JWT signed with key the actor it names, see NewActorToken.
+// Requests without a token go on anonymously; any other token is answered
+// with 401.
+func Authenticate(next http.Handler, adminToken string, key []byte) http.Handler {
+	admin := []byte(adminToken)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok {
+			writeProblem(w, http.StatusUnauthorized, CodeUnauthorized, "only bearer tokens are accepted")
+			return
+		}
+		if len(admin) > 0 && subtle.ConstantTimeCompare([]byte(token), admin) == 1 {
+			next.ServeHTTP(w, r.WithContext(WithActor(r.Context(), Actor{Name: "admin", Role: AdminRole})))
+			return
+		}
+		actor, err := ParseActorToken(token, key)
+		if err != nil {
+			writeProblem(w, http.StatusUnauthorized, CodeUnauthorized, err.Error())
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithActor(r.Context(), actor)))
+	})
+}
@@ -7,6 +7,8 @@ import (
 	"io"
 	"strconv"
 	"strings"
+
+	"github.com/agnivade/levenshtein"
 )
 
 type CLI struct {
@@ -16,11 +18,14 @@ type CLI struct {
 	game        Game
 }
 
-func NewCLI(in io.Reader, out io.Writer, game Game) *CLI {
+// NewCLI reads commands from in and writes prompts to out. Winners are typed
+// by name and looked up in store before the game records the win.
+func NewCLI(store PlayerStore, in io.Reader, out io.Writer, game Game) *CLI {
 	return &CLI{
-		in:   bufio.NewScanner(in),
-		out:  out,
-		game: game,
+		playerStore: store,
+		in:          bufio.NewScanner(in),
+		out:         out,
+		game:        game,
 	}
 }
 
@@ -30,6 +35,16 @@ const BadPlayerInputErrMsg = "Bad value received for number of players, please t
 
 const BadWinnerInputMsg = "invalid winner input, expect format of 'PlayerName wins'"
 
+const UnknownWinnerMsg = "no player with that name, the win was not recorded"
+
+// SuggestWinnerPrompt is printed with the closest known name when the typed
+// winner does not match any player.
+const SuggestWinnerPrompt = "No player named %q, did you mean %s? (y/n) "
+
+// maxSuggestionDistance is how many single character edits a typed name may
+// be away from a player's name for the player to be suggested.
+const maxSuggestionDistance = 2
+
 func (cli *CLI) PlayPoker() error {
 	if _, err := fmt.Fprint(cli.out, PlayerPrompt); err != nil {
 		return err
@@ -56,17 +71,65 @@ func (cli *CLI) PlayPoker() error {
 		return nil
 	}
 
-	if err := cli.game.Finish(winner); err != nil {
+	player, err := cli.resolveWinner(winner)
+	if errors.Is(err, ErrPlayerNotFound) {
+		if _, err1 := fmt.Fprint(cli.out, UnknownWinnerMsg); err1 != nil {
+			return err1
+		}
+		return nil
+	}
+	if err != nil {
 		return err
 	}
-	return nil
+
+	return cli.game.Finish(player.ID)
+}
+
+// resolveWinner finds the player called name. When there is none it offers
+// the closest name in the league and asks the user to confirm it.
+func (cli *CLI) resolveWinner(name string) (Player, error) {
+	player, err := cli.playerStore.FindByName(name)
+	if !errors.Is(err, ErrPlayerNotFound) {
+		return player, err
+	}
+
+	suggestion := closestPlayer(cli.playerStore.GetLeague(), name)
+	if suggestion == nil {
+		return Player{}, err
+	}
+
+	if _, err := fmt.Fprintf(cli.out, SuggestWinnerPrompt, name, suggestion.Name); err != nil {
+		return Player{}, err
+	}
+	switch strings.ToLower(strings.TrimSpace(cli.readLine())) {
+	case "y", "yes":
+		return *suggestion, nil
+	}
+	return Player{}, err
+}
+
+// closestPlayer returns the player whose name is the fewest edits away from
+// name, ignoring case, or nil if none is within maxSuggestionDistance. Ties
+// go to the player listed first.
+func closestPlayer(league League, name string) *Player {
+	var closest *Player
+	best := maxSuggestionDistance + 1
+	for i, player := range league {
+		distance := levenshtein.ComputeDistance(strings.ToLower(player.Name), strings.ToLower(name))
+		if distance < best {
+			closest, best = &league[i], distance
+		}
+	}
+	return closest
 }
 
+// extractWinner reads the winner's name out of "<name> wins", checked and
+// normalized the way names are when players are added.
 func extractWinner(userInput string) (string, error) {
 	if !strings.Contains(userInput, " wins") {
 		return "", errors.New(BadWinnerInputMsg)
 	}
-	return strings.Replace(userInput, " wins", "", 1), nil
+	return ValidateName(strings.Replace(userInput, " wins", "", 1))
 }
 
 func (cli *CLI) readLine() string {
@@ -3,6 +3,7 @@ package poker_test
 import (
 	"application/poker"
 	"bytes"
+	"fmt"
 	"io"
 	"strings"
 	"testing"
@@ -12,12 +13,19 @@ var dummyBlindAlerter = &poker.SpyBlindAlerter{}
 var dummyPlayerStore = &poker.StubPlayerStore{}
 var dummyStdOut = &bytes.Buffer{}
 
+func newLeagueStore() *poker.StubPlayerStore {
+	return &poker.StubPlayerStore{League: []poker.Player{
+		{ID: 1, Name: "Chris", Wins: 3},
+		{ID: 2, Name: "Cleo", Wins: 1},
+	}}
+}
+
 type GameSpy struct {
 	StartCalled     bool
 	StartCalledWith int
 
 	FinishedCalled   bool
-	FinishCalledWith string
+	FinishCalledWith int
 }
 
 func (g *GameSpy) Start(numberOfPlayers int) {
@@ -25,7 +33,7 @@ func (g *GameSpy) Start(numberOfPlayers int) {
 	g.StartCalledWith = numberOfPlayers
 }
 
-func (g *GameSpy) Finish(winner string) error {
+func (g *GameSpy) Finish(winner int) error {
 	g.FinishedCalled = true
 	g.FinishCalledWith = winner
 	return nil
@@ -42,25 +50,75 @@ func TestCLI(t *testing.T) {
 		stdout := &bytes.Buffer{}
 
 		in := userSends("3", "Chris wins")
-		cli := poker.NewCLI(in, stdout, game)
+		cli := poker.NewCLI(newLeagueStore(), in, stdout, game)
 
 		cli.PlayPoker()
 
 		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt)
 		assertGameStartedWith(t, game, 3)
-		assertFinishCalledWith(t, game, "Chris")
+		assertFinishCalledWith(t, game, 1)
 	})
 
 	t.Run("start game with 8 players and record 'Cleo' as winner", func(t *testing.T) {
 		game := &GameSpy{}
 
 		in := userSends("8", "Cleo wins")
-		cli := poker.NewCLI(in, dummyStdOut, game)
+		cli := poker.NewCLI(newLeagueStore(), in, dummyStdOut, game)
 
 		cli.PlayPoker()
 
 		assertGameStartedWith(t, game, 8)
-		assertFinishCalledWith(t, game, "Cleo")
+		assertFinishCalledWith(t, game, 2)
+	})
+
+	t.Run("matches the winner's name in any case", func(t *testing.T) {
+		game := &GameSpy{}
+
+		in := userSends("4", "cleo wins")
+		cli := poker.NewCLI(newLeagueStore(), in, dummyStdOut, game)
+
+		cli.PlayPoker()
+
+		assertFinishCalledWith(t, game, 2)
+	})
+
+	t.Run("suggests a close name and records the win once confirmed", func(t *testing.T) {
+		game := &GameSpy{}
+		stdout := &bytes.Buffer{}
+
+		in := userSends("4", "Chirs wins", "y")
+		cli := poker.NewCLI(newLeagueStore(), in, stdout, game)
+
+		cli.PlayPoker()
+
+		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, fmt.Sprintf(poker.SuggestWinnerPrompt, "Chirs", "Chris"))
+		assertFinishCalledWith(t, game, 1)
+	})
+
+	t.Run("does not record the win when the suggestion is declined", func(t *testing.T) {
+		game := &GameSpy{}
+		stdout := &bytes.Buffer{}
+
+		in := userSends("4", "Chirs wins", "n")
+		cli := poker.NewCLI(newLeagueStore(), in, stdout, game)
+
+		cli.PlayPoker()
+
+		assertGameNotFinished(t, game)
+		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, fmt.Sprintf(poker.SuggestWinnerPrompt, "Chirs", "Chris"), poker.UnknownWinnerMsg)
+	})
+
+	t.Run("prints an error for a name nobody is close to", func(t *testing.T) {
+		game := &GameSpy{}
+		stdout := &bytes.Buffer{}
+
+		in := userSends("4", "Lloyd wins")
+		cli := poker.NewCLI(newLeagueStore(), in, stdout, game)
+
+		cli.PlayPoker()
+
+		assertGameNotFinished(t, game)
+		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, poker.UnknownWinnerMsg)
 	})
 
 	t.Run("it prints an error when a non numeric value is entered and does not start the game", func(t *testing.T) {
@@ -69,7 +127,7 @@ func TestCLI(t *testing.T) {
 		stdout := &bytes.Buffer{}
 		in := userSends("pies")
 
-		cli := poker.NewCLI(in, stdout, game)
+		cli := poker.NewCLI(dummyPlayerStore, in, stdout, game)
 		cli.PlayPoker()
 
 		assertGameNotStarted(t, game)
@@ -81,13 +139,37 @@ func TestCLI(t *testing.T) {
 		stdout := &bytes.Buffer{}
 
 		in := userSends("8", "Lloyd is a killer")
-		cli := poker.NewCLI(in, stdout, game)
+		cli := poker.NewCLI(dummyPlayerStore, in, stdout, game)
+
+		cli.PlayPoker()
+
+		assertGameNotFinished(t, game)
+		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, poker.BadWinnerInputMsg)
+	})
+
+	t.Run("it prints an error when the winner's name is not valid", func(t *testing.T) {
+		game := &GameSpy{}
+		stdout := &bytes.Buffer{}
+
+		in := userSends("8", "<Cleo> wins")
+		cli := poker.NewCLI(newLeagueStore(), in, stdout, game)
 
 		cli.PlayPoker()
 
 		assertGameNotFinished(t, game)
 		assertMessagesSentToUser(t, stdout, poker.PlayerPrompt, poker.BadWinnerInputMsg)
 	})
+
+	t.Run("normalizes the winner's name", func(t *testing.T) {
+		game := &GameSpy{}
+
+		in := userSends("4", "  Cleo   wins")
+		cli := poker.NewCLI(newLeagueStore(), in, dummyStdOut, game)
+
+		cli.PlayPoker()
+
+		assertFinishCalledWith(t, game, 2)
+	})
 }
 
 func assertGameStartedWith(t testing.TB, game *GameSpy, numberOfPlayersWanted int) {
@@ -111,10 +193,10 @@ func assertGameNotStarted(t testing.TB, game *GameSpy) {
 	}
 }
 
-func assertFinishCalledWith(t testing.TB, game *GameSpy, winner string) {
+func assertFinishCalledWith(t testing.TB, game *GameSpy, winner int) {
 	t.Helper()
-	if game.FinishCalledWith != winner {
-		t.Errorf("expected finish called with %q but got %q", winner, game.FinishCalledWith)
+	if !game.FinishedCalled || game.FinishCalledWith != winner {
+		t.Errorf("expected finish called with %d but got %d", winner, game.FinishCalledWith)
 	}
 }
 
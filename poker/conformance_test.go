@@ -0,0 +1,76 @@
+package poker_test
+
+import (
+	"application/migrations"
+	"application/poker"
+	"application/poker/pokertest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestStubPlayerStoreConformance(t *testing.T) {
+	pokertest.RunPlayerStoreConformance(t, func(t *testing.T) poker.PlayerStore {
+		return &poker.StubPlayerStore{}
+	})
+}
+
+func TestFileSystemPlayerStoreConformance(t *testing.T) {
+	pokertest.RunPlayerStoreConformance(t, func(t *testing.T) poker.PlayerStore {
+		store, closeStore, err := poker.FileSystemPlayerStoreFromFile(filepath.Join(t.TempDir(), "game.db.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(closeStore)
+		return store
+	})
+}
+
+func TestEventSourcedPlayerStoreConformance(t *testing.T) {
+	pokertest.RunPlayerStoreConformance(t, func(t *testing.T) poker.PlayerStore {
+		store, err := poker.NewEventSourcedPlayerStore(filepath.Join(t.TempDir(), "events.jsonl"), poker.WithSnapshotEvery(2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { store.Close() })
+		return store
+	})
+}
+
+func TestSQLitePlayerStoreConformance(t *testing.T) {
+	pokertest.RunPlayerStoreConformance(t, func(t *testing.T) poker.PlayerStore {
+		store, err := poker.NewSQLitePlayerStore(filepath.Join(t.TempDir(), "game.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { store.Close() })
+		return store
+	})
+}
+
+func TestDatabaseStoreConformance(t *testing.T) {
+	dsn := os.Getenv("POKER_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("POKER_TEST_POSTGRES_DSN is not set")
+	}
+	pokertest.RunPlayerStoreConformance(t, func(t *testing.T) poker.PlayerStore {
+		db, err := sqlx.Connect("postgres", dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+		migrator, err := migrations.New(db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := migrator.Up(); err != nil {
+			t.Fatal(err)
+		}
+		if err := poker.ResetPostgres(db); err != nil {
+			t.Fatal(err)
+		}
+		return poker.NewDatabaseStoreFromDB(db)
+	})
+}
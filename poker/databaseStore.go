@@ -1,11 +1,21 @@
 package poker
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"time"
+
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
+// DatabaseStore implements PlayerStore on top of SQL. Its V2 method gives the
+// same store with contexts and errors on every call; the PlayerStore methods
+// run it with a background context and drop the read errors.
 type DatabaseStore struct {
 	db *sqlx.DB
 }
@@ -18,45 +28,242 @@ func NewDatabaseStore(conStr string) (*DatabaseStore, error) {
 	if err = db.Ping(); err != nil {
 		return nil, err
 	}
-	store := &DatabaseStore{db: db}
-	return store, nil
+	return NewDatabaseStoreFromDB(db), nil
+}
+
+// NewDatabaseStoreFromDB wraps an already opened connection. The schema is
+// expected to be up to date, see the migrations package and cmd/migrate.
+func NewDatabaseStoreFromDB(db *sqlx.DB) *DatabaseStore {
+	return &DatabaseStore{db: db}
+}
+
+func (store *DatabaseStore) Close() error {
+	return store.db.Close()
+}
+
+func (store *DatabaseStore) V2() PlayerStoreV2 {
+	return databaseStoreV2{store}
 }
 
 func (store *DatabaseStore) GetLeague() League {
-	var league League
-	err := store.db.Select(&league, "SELECT p.username as name, COUNT(gr.winner_id) AS wins\nFROM players AS p\nLEFT JOIN game_results AS gr ON p.id = gr.winner_id\nGROUP BY p.username\nORDER BY wins DESC")
+	league, _ := store.V2().GetLeague(context.Background())
+	return league
+}
+
+func (store *DatabaseStore) GetPlayerScore(id int) int {
+	wins, _ := store.V2().GetPlayerScore(context.Background(), id)
+	return wins
+}
+
+func (store *DatabaseStore) FindByName(name string) (Player, error) {
+	return store.V2().FindByName(context.Background(), name)
+}
+
+func (store *DatabaseStore) RecordWin(id int) error {
+	return store.V2().RecordWin(context.Background(), id)
+}
+
+func (store *DatabaseStore) AddPlayer(player *Player) error {
+	return store.V2().AddPlayer(context.Background(), player)
+}
+
+func (store *DatabaseStore) DeletePlayer(id int) error {
+	return store.V2().DeletePlayer(context.Background(), id)
+}
+
+type databaseStoreV2 struct {
+	*DatabaseStore
+}
+
+// leagueQuery leaves deleted players out, callers add their conditions with
+// AND.
+const leagueQuery = `SELECT p.id, p.username AS name, COUNT(gr.id) + p.starting_wins AS wins, p.deleted_at
+FROM players AS p
+LEFT JOIN game_results AS gr ON p.id = gr.winner_id
+WHERE p.deleted_at IS NULL`
+
+func (store databaseStoreV2) GetLeague(ctx context.Context) (League, error) {
+	league := League{}
+	err := store.db.SelectContext(ctx, &league, leagueQuery+`
+GROUP BY p.id, p.username
+ORDER BY wins DESC, p.id`)
 	if err != nil {
-		return nil
+		return nil, fmt.Errorf("problem loading league, %w", err)
 	}
-	return league
+	return league, nil
 }
 
-func (store *DatabaseStore) GetPlayerScore(name string) int {
-	var wins int
-	err := store.db.Get(&wins, "SELECT COUNT(gr.winner_id) AS wins\nFROM players AS p\n         JOIN game_results AS gr ON p.id = gr.winner_id\nWHERE p.username = $1\nGROUP BY p.username\nORDER BY wins DESC;", name)
+func (store databaseStoreV2) GetPlayer(ctx context.Context, id int) (Player, error) {
+	var player Player
+	err := store.db.GetContext(ctx, &player, leagueQuery+`
+AND p.id = $1
+GROUP BY p.id, p.username`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Player{}, fmt.Errorf("%w: no player with id %d", ErrPlayerNotFound, id)
+	}
 	if err != nil {
-		return 0
+		return Player{}, fmt.Errorf("problem loading player %d, %w", id, err)
 	}
-	return wins
+	return player, nil
 }
 
-func (store *DatabaseStore) RecordWin(name string) error {
-	if name == "" {
-		return errors.New("no name provided")
+func (store databaseStoreV2) FindByName(ctx context.Context, name string) (Player, error) {
+	var player Player
+	err := store.db.GetContext(ctx, &player, leagueQuery+`
+AND LOWER(p.username) = LOWER($1)
+GROUP BY p.id, p.username`, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Player{}, fmt.Errorf("%w: no player named %q", ErrPlayerNotFound, name)
 	}
-	return nil
-	//TODO
+	if err != nil {
+		return Player{}, fmt.Errorf("problem loading player %q, %w", name, err)
+	}
+	return player, nil
 }
-func (store *DatabaseStore) AddPlayer(player *Player) error {
+
+func (store databaseStoreV2) GetPlayerScore(ctx context.Context, id int) (int, error) {
+	player, err := store.GetPlayer(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	return player.Wins, nil
+}
+
+func (store databaseStoreV2) RecordWin(ctx context.Context, id int) error {
+	return store.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := bumpRevision(ctx, tx, id); err != nil {
+			return err
+		}
+		if err := playerExists(ctx, tx, id); err != nil {
+			return err
+		}
+		return recordWin(ctx, tx, id)
+	})
+}
+
+func (store databaseStoreV2) AddPlayer(ctx context.Context, player *Player) error {
 	if player == nil {
-		return errors.New("no player provided - nil pointer")
+		return fmt.Errorf("%w: no player provided - nil pointer", ErrInvalidPlayer)
+	}
+	if player.Name == "" {
+		return fmt.Errorf("%w: player name cannot be empty", ErrInvalidPlayer)
+	}
+	if player.ID < 0 {
+		return fmt.Errorf("%w: id %d must not be negative", ErrInvalidPlayer, player.ID)
+	}
+	if player.Wins < 0 {
+		return fmt.Errorf("%w: wins %d must not be negative", ErrInvalidPlayer, player.Wins)
+	}
+	config := PlayerConfig{
+		ID:        player.ID,
+		Username:  player.Name,
+		Email:     fmt.Sprintf("%s@gmail.com", player.Name),
+		CreatedAt: time.Now().UTC(),
 	}
-	name := player.Name
+	return store.withTx(ctx, func(tx *sqlx.Tx) error {
+		var taken struct {
+			Username  string     `db:"username"`
+			DeletedAt *time.Time `db:"deleted_at"`
+			PurgedAt  *time.Time `db:"purged_at"`
+		}
+		err := tx.GetContext(ctx, &taken, "SELECT username, deleted_at, purged_at FROM players WHERE id = $1 OR LOWER(username) = LOWER($2)",
+			config.ID, config.Username)
+		if err == nil && taken.PurgedAt != nil {
+			return fmt.Errorf("%w: id %d belonged to a purged player", ErrDuplicatePlayer, config.ID)
+		}
+		if err == nil && taken.DeletedAt != nil {
+			return fmt.Errorf("%w: player with id %d or name %q already exists, deleted but not purged yet",
+				ErrDuplicatePlayer, config.ID, taken.Username)
+		}
+		if err == nil {
+			return fmt.Errorf("%w: player with id %d or name %q already exists", ErrDuplicatePlayer, config.ID, taken.Username)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		// The file store keeps a plain counter, so a player may arrive with
+		// wins already on the board. They are kept apart from the games.
+		if config.ID == 0 {
+			err = tx.QueryRowxContext(ctx, "INSERT INTO players (username, email, created_at, starting_wins) VALUES ($1, $2, $3, $4) RETURNING id",
+				config.Username, config.Email, config.CreatedAt, player.Wins).Scan(&config.ID)
+		} else {
+			_, err = tx.ExecContext(ctx, "INSERT INTO players (id, username, email, created_at, starting_wins) VALUES ($1, $2, $3, $4, $5)",
+				config.ID, config.Username, config.Email, config.CreatedAt, player.Wins)
+		}
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w: player with id %d or name %q already exists", ErrDuplicatePlayer, config.ID, config.Username)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to add player to database, %w", err)
+		}
+		if player.ID != 0 {
+			if err := store.syncPlayerIDs(ctx, tx); err != nil {
+				return err
+			}
+		}
+		player.ID = config.ID
+		return bumpRevision(ctx, tx, config.ID)
+	})
+}
+
+func (store *DatabaseStore) RenamePlayer(ctx context.Context, id int, name string) (Player, error) {
 	if name == "" {
-		return errors.New("no name provided")
+		return Player{}, fmt.Errorf("%w: player name cannot be empty", ErrInvalidPlayer)
 	}
-	email := fmt.Sprintf("%s@gmail.com", name)
-	tx, err := store.db.Begin()
+	var player Player
+	err := store.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := bumpRevision(ctx, tx, id); err != nil {
+			return err
+		}
+		if err := playerExists(ctx, tx, id); err != nil {
+			return err
+		}
+		var taken string
+		err := tx.GetContext(ctx, &taken, "SELECT username FROM players WHERE LOWER(username) = LOWER($1) AND id <> $2", name, id)
+		if err == nil {
+			return fmt.Errorf("%w: player named %q already exists", ErrDuplicatePlayer, taken)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, "UPDATE players SET username = $1 WHERE id = $2", name, id)
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w: player named %q already exists", ErrDuplicatePlayer, name)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to rename player %d, %w", id, err)
+		}
+		return tx.GetContext(ctx, &player, leagueQuery+`
+AND p.id = $1
+GROUP BY p.id, p.username`, id)
+	})
+	return player, err
+}
+
+// DeletePlayer only marks the player deleted. Their results stay until the
+// player is purged, see PurgePlayers.
+func (store databaseStoreV2) DeletePlayer(ctx context.Context, id int) error {
+	return store.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := bumpRevision(ctx, tx, id); err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx, "UPDATE players SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL",
+			time.Now().UTC(), id)
+		if err != nil {
+			return fmt.Errorf("failed to remove player from database, %w", err)
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return fmt.Errorf("%w: no player with id %d", ErrPlayerNotFound, id)
+		}
+		return nil
+	})
+}
+
+func (store *DatabaseStore) withTx(ctx context.Context, fn func(tx *sqlx.Tx) error) (err error) {
+	tx, err := store.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
@@ -70,14 +277,66 @@ func (store *DatabaseStore) AddPlayer(player *Player) error {
 			err = tx.Commit()
 		}
 	}()
-	_, err = tx.Exec("INSERT INTO public.players (username, email) VALUES ($1, $2)", name, email)
+	return fn(tx)
+}
+
+// syncPlayerIDs moves the Postgres id sequence past an id the caller chose,
+// so the next generated id does not collide with it. SQLite's AUTOINCREMENT
+// already does this by itself.
+func (store *DatabaseStore) syncPlayerIDs(ctx context.Context, tx *sqlx.Tx) error {
+	if store.db.DriverName() != "postgres" {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, "SELECT setval(pg_get_serial_sequence('players', 'id'), (SELECT MAX(id) FROM players))")
+	return err
+}
+
+func playerExists(ctx context.Context, tx *sqlx.Tx, id int) error {
+	var found int
+	err := tx.GetContext(ctx, &found, "SELECT id FROM players WHERE id = $1 AND deleted_at IS NULL", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: no player with id %d", ErrPlayerNotFound, id)
+	}
+	return err
+}
+
+// recordWin stores a single game won by winnerID as a games row, its
+// matching game_results row and the winner as its only participant.
+func recordWin(ctx context.Context, tx *sqlx.Tx, winnerID int) error {
+	game := GameConfig{
+		GameDate: time.Now().UTC(),
+	}
+	err := tx.QueryRowxContext(ctx, "INSERT INTO games (game_date, location, notes) VALUES ($1, $2, $3) RETURNING id",
+		game.GameDate, game.Location, game.Notes).Scan(&game.ID)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to create game, %w", err)
+	}
+	result := ResultConfig{
+		GameID:   game.ID,
+		WinnerID: winnerID,
+	}
+	_, err = tx.ExecContext(ctx, "INSERT INTO game_results (game_id, winner_id, amount_won) VALUES ($1, $2, $3)",
+		result.GameID, result.WinnerID, result.AmountWon)
+	if err != nil {
+		return fmt.Errorf("failed to record game result, %w", err)
+	}
+	_, err = tx.ExecContext(ctx, "INSERT INTO game_participants (game_id, player_id, position) VALUES ($1, $2, 1)",
+		game.ID, winnerID)
+	if err != nil {
+		return fmt.Errorf("failed to record game participant, %w", err)
 	}
 	return nil
 }
 
-func (store *DatabaseStore) DeletePlayer(name string) error {
-	//TODO
-	return nil
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505"
+	}
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		code := sqliteErr.Code()
+		return code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY || code == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+	}
+	return false
 }
@@ -0,0 +1,83 @@
+package poker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const deletedQuery = `SELECT p.id, p.username AS name, COUNT(gr.id) + p.starting_wins AS wins, p.deleted_at
+FROM players AS p
+LEFT JOIN game_results AS gr ON p.id = gr.winner_id
+WHERE p.deleted_at IS NOT NULL AND p.purged_at IS NULL`
+
+func (store *DatabaseStore) DeletedPlayers(ctx context.Context) (League, error) {
+	league := League{}
+	err := store.db.SelectContext(ctx, &league, deletedQuery+`
+GROUP BY p.id, p.username, p.deleted_at
+ORDER BY p.id`)
+	if err != nil {
+		return nil, fmt.Errorf("problem loading deleted players, %w", err)
+	}
+	return league, nil
+}
+
+func (store *DatabaseStore) RestorePlayer(ctx context.Context, id int) (Player, error) {
+	var player Player
+	err := store.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := bumpRevision(ctx, tx, id); err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx, "UPDATE players SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL AND purged_at IS NULL", id)
+		if err != nil {
+			return fmt.Errorf("failed to restore player %d, %w", id, err)
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return noDeletedPlayer(id)
+		}
+		err = tx.GetContext(ctx, &player, leagueQuery+`
+AND p.id = $1
+GROUP BY p.id, p.username`, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return noDeletedPlayer(id)
+		}
+		if err != nil {
+			return fmt.Errorf("problem loading player %d, %w", id, err)
+		}
+		return nil
+	})
+	return player, err
+}
+
+// PurgePlayers keeps the purged players' results, reverted ones included, and
+// their places in the games they played, so the games keep their winner and
+// finishing order. What identifies a player is dropped instead: the row the
+// results point at loses its name and email for good, and its id is not
+// given out again.
+func (store *DatabaseStore) PurgePlayers(ctx context.Context, cutoff time.Time) (int, error) {
+	purged := 0
+	err := store.withTx(ctx, func(tx *sqlx.Tx) error {
+		// Names cannot have #, so these stay clear of the names of players.
+		res, err := tx.ExecContext(ctx, `UPDATE players SET username = '#purged-' || CAST(id AS TEXT), email = '', purged_at = $1
+WHERE deleted_at < $2 AND purged_at IS NULL`, time.Now().UTC(), cutoff.UTC())
+		if err != nil {
+			return fmt.Errorf("failed to purge deleted players, %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil || n == 0 {
+			return err
+		}
+		purged = int(n)
+		return bumpRevision(ctx, tx)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
@@ -0,0 +1,270 @@
+package poker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const gameQuery = `SELECT g.id, g.game_date, g.location, g.notes, COALESCE(gr.winner_id, 0) AS winner_id
+FROM games AS g
+LEFT JOIN game_results AS gr ON g.id = gr.game_id`
+
+type gameRow struct {
+	GameConfig
+	WinnerID int `db:"winner_id"`
+}
+
+type participantRow struct {
+	GameID   int    `db:"game_id"`
+	PlayerID int    `db:"player_id"`
+	Name     string `db:"name"`
+	Position int    `db:"position"`
+	BuyIn    int    `db:"buy_in"`
+	Rebuys   int    `db:"rebuys"`
+	Payout   int    `db:"payout"`
+}
+
+func (store *DatabaseStore) CreateGame(ctx context.Context, game *GameRecord) error {
+	if game == nil {
+		return fmt.Errorf("%w: no game provided - nil pointer", ErrInvalidGame)
+	}
+	if err := game.checkNew(); err != nil {
+		return err
+	}
+	config := GameConfig{
+		GameDate: game.Date.UTC(),
+		Location: game.Location,
+		Notes:    game.Notes,
+	}
+	if game.Date.IsZero() {
+		config.GameDate = time.Now().UTC()
+	}
+
+	return store.withTx(ctx, func(tx *sqlx.Tx) error {
+		for _, participant := range game.Participants {
+			if err := playerExists(ctx, tx, participant.PlayerID); err != nil {
+				if errors.Is(err, ErrPlayerNotFound) {
+					return fmt.Errorf("%w: no player with id %d", ErrInvalidGame, participant.PlayerID)
+				}
+				return err
+			}
+		}
+
+		err := tx.QueryRowxContext(ctx, "INSERT INTO games (game_date, location, notes) VALUES ($1, $2, $3) RETURNING id",
+			config.GameDate, config.Location, config.Notes).Scan(&config.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create game, %w", err)
+		}
+		for _, participant := range game.Participants {
+			_, err := tx.ExecContext(ctx, "INSERT INTO game_participants (game_id, player_id, buy_in, rebuys, payout) VALUES ($1, $2, $3, $4, $5)",
+				config.ID, participant.PlayerID, participant.BuyIn, participant.Rebuys, participant.Payout)
+			if err != nil {
+				return fmt.Errorf("failed to add participant %d, %w", participant.PlayerID, err)
+			}
+		}
+
+		game.ID = config.ID
+		game.Date = config.GameDate
+		return bumpRevision(ctx, tx)
+	})
+}
+
+func (store *DatabaseStore) RecordResult(ctx context.Context, gameID int, finishingOrder []int) error {
+	return store.withTx(ctx, func(tx *sqlx.Tx) error {
+		game, err := getGame(ctx, tx, gameID)
+		if err != nil {
+			return err
+		}
+		if err := game.checkResult(finishingOrder); err != nil {
+			return err
+		}
+		if err := bumpRevision(ctx, tx, finishingOrder[0]); err != nil {
+			return err
+		}
+
+		result := ResultConfig{
+			GameID:    gameID,
+			WinnerID:  finishingOrder[0],
+			AmountWon: game.participant(finishingOrder[0]).Payout,
+		}
+		_, err = tx.ExecContext(ctx, "INSERT INTO game_results (game_id, winner_id, amount_won) VALUES ($1, $2, $3)",
+			result.GameID, result.WinnerID, result.AmountWon)
+		if err != nil {
+			return fmt.Errorf("failed to record game result, %w", err)
+		}
+		for i, playerID := range finishingOrder {
+			_, err := tx.ExecContext(ctx, "UPDATE game_participants SET position = $1 WHERE game_id = $2 AND player_id = $3",
+				i+1, gameID, playerID)
+			if err != nil {
+				return fmt.Errorf("failed to record position of player %d, %w", playerID, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (store *DatabaseStore) GetGame(ctx context.Context, id int) (GameRecord, error) {
+	return getGame(ctx, store.db, id)
+}
+
+func (store *DatabaseStore) ListGames(ctx context.Context, filter GameFilter) ([]GameRecord, error) {
+	var where []string
+	var args []interface{}
+	if filter.PlayerID != 0 {
+		args = append(args, filter.PlayerID)
+		where = append(where, fmt.Sprintf("EXISTS (SELECT 1 FROM game_participants AS gp WHERE gp.game_id = g.id AND gp.player_id = $%d)", len(args)))
+	}
+	if !filter.Since.IsZero() {
+		args = append(args, filter.Since.UTC())
+		where = append(where, fmt.Sprintf("g.game_date >= $%d", len(args)))
+	}
+	if !filter.Until.IsZero() {
+		args = append(args, filter.Until.UTC())
+		where = append(where, fmt.Sprintf("g.game_date < $%d", len(args)))
+	}
+
+	query := gameQuery
+	if len(where) > 0 {
+		query += "\nWHERE " + strings.Join(where, " AND ")
+	}
+	query += "\nORDER BY g.game_date DESC, g.id DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf("\nLIMIT $%d", len(args))
+	}
+
+	var rows []gameRow
+	if err := store.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("problem loading games, %w", err)
+	}
+	return withParticipants(ctx, store.db, rows)
+}
+
+func getGame(ctx context.Context, db sqlx.ExtContext, id int) (GameRecord, error) {
+	var row gameRow
+	err := sqlx.GetContext(ctx, db, &row, gameQuery+"\nWHERE g.id = $1", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return GameRecord{}, fmt.Errorf("%w: no game with id %d", ErrGameNotFound, id)
+	}
+	if err != nil {
+		return GameRecord{}, fmt.Errorf("problem loading game %d, %w", id, err)
+	}
+	games, err := withParticipants(ctx, db, []gameRow{row})
+	if err != nil {
+		return GameRecord{}, err
+	}
+	return games[0], nil
+}
+
+// withParticipants turns game rows into records, loading the participants of
+// all of them in one query.
+func withParticipants(ctx context.Context, db sqlx.ExtContext, rows []gameRow) ([]GameRecord, error) {
+	games := make([]GameRecord, len(rows))
+	if len(rows) == 0 {
+		return games, nil
+	}
+	index := make(map[int]int, len(rows))
+	ids := make([]int, len(rows))
+	for i, row := range rows {
+		games[i] = GameRecord{
+			ID:           row.ID,
+			Date:         row.GameDate.UTC(),
+			Location:     row.Location,
+			Notes:        row.Notes,
+			WinnerID:     row.WinnerID,
+			Participants: []Participant{},
+		}
+		index[row.ID] = i
+		ids[i] = row.ID
+	}
+
+	query, args, err := sqlx.In(`SELECT gp.game_id, gp.player_id, p.username AS name, COALESCE(gp.position, 0) AS position,
+	gp.buy_in, gp.rebuys, gp.payout
+FROM game_participants AS gp
+JOIN players AS p ON p.id = gp.player_id
+WHERE gp.game_id IN (?)
+ORDER BY gp.game_id, COALESCE(gp.position, 0) = 0, gp.position, gp.player_id`, ids)
+	if err != nil {
+		return nil, err
+	}
+	var participants []participantRow
+	if err := sqlx.SelectContext(ctx, db, &participants, db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("problem loading game participants, %w", err)
+	}
+	for _, row := range participants {
+		game := &games[index[row.GameID]]
+		game.Participants = append(game.Participants, Participant{
+			PlayerID: row.PlayerID,
+			Name:     row.Name,
+			Position: row.Position,
+			BuyIn:    row.BuyIn,
+			Rebuys:   row.Rebuys,
+			Payout:   row.Payout,
+		})
+	}
+	return games, nil
+}
+
+func (store *DatabaseStore) RecordMoney(ctx context.Context, gameID int, stakes []Stake) error {
+	return store.withTx(ctx, func(tx *sqlx.Tx) error {
+		game, err := getGame(ctx, tx, gameID)
+		if err != nil {
+			return err
+		}
+		if err := game.checkStakes(stakes); err != nil {
+			return err
+		}
+		if err := bumpRevision(ctx, tx); err != nil {
+			return err
+		}
+
+		for _, stake := range stakes {
+			_, err := tx.ExecContext(ctx, "UPDATE game_participants SET buy_in = $1, rebuys = $2, payout = $3 WHERE game_id = $4 AND player_id = $5",
+				stake.BuyIn, stake.Rebuys, stake.Payout, gameID, stake.PlayerID)
+			if err != nil {
+				return fmt.Errorf("failed to record money of player %d, %w", stake.PlayerID, err)
+			}
+		}
+		// game_results keeps what the winner took home.
+		_, err = tx.ExecContext(ctx, `UPDATE game_results SET amount_won = (
+	SELECT gp.payout FROM game_participants AS gp
+	WHERE gp.game_id = game_results.game_id AND gp.player_id = game_results.winner_id
+) WHERE game_id = $1`, gameID)
+		if err != nil {
+			return fmt.Errorf("failed to update amount won, %w", err)
+		}
+		return nil
+	})
+}
+
+func (store *DatabaseStore) GetEarnings(ctx context.Context) (Earnings, error) {
+	var rows []struct {
+		ID       int    `db:"id"`
+		Name     string `db:"name"`
+		Games    int    `db:"games"`
+		Invested int    `db:"invested"`
+		TotalWon int    `db:"total_won"`
+	}
+	err := store.db.SelectContext(ctx, &rows, `SELECT p.id, p.username AS name, COUNT(gp.game_id) AS games,
+	COALESCE(SUM(gp.buy_in + gp.rebuys), 0) AS invested, COALESCE(SUM(gp.payout), 0) AS total_won
+FROM players AS p
+LEFT JOIN game_participants AS gp ON p.id = gp.player_id
+WHERE p.deleted_at IS NULL
+GROUP BY p.id, p.username`)
+	if err != nil {
+		return nil, fmt.Errorf("problem loading earnings, %w", err)
+	}
+
+	earnings := make(Earnings, len(rows))
+	for i, row := range rows {
+		earnings[i] = newEarning(row.ID, row.Name, row.Games, row.Invested, row.TotalWon)
+	}
+	earnings.sort()
+	return earnings, nil
+}
@@ -0,0 +1,92 @@
+package poker
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDatabaseGameStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("recorded wins show up as games", func(t *testing.T) {
+		store := createTestDatabase(t)
+		assertNoError(t, store.AddPlayer(&Player{ID: 1, Name: "Cleo"}))
+		assertNoError(t, store.RecordWin(1))
+
+		games, err := store.ListGames(ctx, GameFilter{PlayerID: 1})
+		assertNoError(t, err)
+		if len(games) != 1 || games[0].WinnerID != 1 {
+			t.Errorf("expected one game won by player 1, got %+v", games)
+		}
+	})
+}
+
+func TestDatabaseMoneyStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("records stakes and builds the earnings leaderboard", func(t *testing.T) {
+		store := createTestDatabase(t)
+		assertNoError(t, store.AddPlayer(&Player{ID: 1, Name: "Cleo"}))
+		assertNoError(t, store.AddPlayer(&Player{ID: 2, Name: "Chris"}))
+		assertNoError(t, store.AddPlayer(&Player{ID: 3, Name: "Lloyd"}))
+
+		game := &GameRecord{Participants: []Participant{{PlayerID: 1, BuyIn: 2000}, {PlayerID: 2, BuyIn: 2000}}}
+		assertNoError(t, store.CreateGame(ctx, game))
+		assertNoError(t, store.RecordResult(ctx, game.ID, []int{2, 1}))
+		assertNoError(t, store.RecordMoney(ctx, game.ID, []Stake{
+			{PlayerID: 1, BuyIn: 2000, Rebuys: 1000},
+			{PlayerID: 2, BuyIn: 2000, Payout: 5000},
+		}))
+
+		earnings, err := store.GetEarnings(ctx)
+		assertNoError(t, err)
+		want := Earnings{
+			{ID: 2, Name: "Chris", Games: 1, Invested: 2000, TotalWon: 5000, NetProfit: 3000, ROI: 1.5},
+			{ID: 3, Name: "Lloyd"},
+			{ID: 1, Name: "Cleo", Games: 1, Invested: 3000, NetProfit: -3000, ROI: -1},
+		}
+		if !reflect.DeepEqual(earnings, want) {
+			t.Errorf("got %+v want %+v", earnings, want)
+		}
+
+		var amountWon int
+		assertNoError(t, store.db.Get(&amountWon, "SELECT amount_won FROM game_results WHERE game_id = $1", game.ID))
+		if amountWon != 5000 {
+			t.Errorf("got amount won %d want 5000", amountWon)
+		}
+	})
+
+	t.Run("rejects negative amounts and players who did not play", func(t *testing.T) {
+		store := createTestDatabase(t)
+		assertNoError(t, store.AddPlayer(&Player{ID: 1, Name: "Cleo"}))
+		assertNoError(t, store.AddPlayer(&Player{ID: 2, Name: "Chris"}))
+		assertNoError(t, store.AddPlayer(&Player{ID: 3, Name: "Lloyd"}))
+		game := &GameRecord{Participants: []Participant{{PlayerID: 1}, {PlayerID: 2}}}
+		assertNoError(t, store.CreateGame(ctx, game))
+
+		for _, stakes := range [][]Stake{
+			{{PlayerID: 1, BuyIn: -1}},
+			{{PlayerID: 3, BuyIn: 100}},
+		} {
+			if err := store.RecordMoney(ctx, game.ID, stakes); !errors.Is(err, ErrInvalidGame) {
+				t.Errorf("stakes %+v: got error %v want %v", stakes, err, ErrInvalidGame)
+			}
+		}
+	})
+}
+
+func assertGameIDs(t testing.TB, store GameStore, filter GameFilter, want ...int) {
+	t.Helper()
+	games, err := store.ListGames(context.Background(), filter)
+	assertNoError(t, err)
+
+	got := make([]int, len(games))
+	for i, game := range games {
+		got[i] = game.ID
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("filter %+v: got games %v want %v", filter, got, want)
+	}
+}
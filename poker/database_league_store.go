@@ -0,0 +1,59 @@
+package poker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// leagueOrders are the ORDER BY terms of the sorts QueryLeague runs in SQL.
+var leagueOrders = map[string]string{
+	SortByWins: "wins",
+	SortByName: "LOWER(p.username)",
+	SortByID:   "p.id",
+}
+
+// likeEscaper escapes the wildcards of LIKE, so a name filter only matches
+// names that contain it.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (store *DatabaseStore) QueryLeague(ctx context.Context, query LeagueQuery) (League, int, error) {
+	order, ok := leagueOrders[query.Sort]
+	if !ok {
+		return nil, 0, invalidQuery("sort", fmt.Sprintf("cannot be %q in SQL", query.Sort))
+	}
+	direction := "ASC"
+	if query.Order == "desc" {
+		direction = "DESC"
+	}
+
+	var args []interface{}
+	matches := leagueQuery
+	if query.Name != "" {
+		args = append(args, "%"+likeEscaper.Replace(strings.ToLower(query.Name))+"%")
+		matches += fmt.Sprintf(` AND LOWER(p.username) LIKE $%d ESCAPE '\'`, len(args))
+	}
+	args = append(args, query.MinWins)
+	matches += fmt.Sprintf(`
+GROUP BY p.id, p.username
+HAVING COUNT(gr.id) + p.starting_wins >= $%d`, len(args))
+
+	var total int
+	if err := store.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM (`+matches+`) AS matches`, args...); err != nil {
+		return nil, 0, fmt.Errorf("problem counting league, %w", err)
+	}
+
+	limit := query.Limit
+	if limit == 0 {
+		limit = MaxPlayerID
+	}
+	args = append(args, limit, query.Offset)
+	league := League{}
+	err := store.db.SelectContext(ctx, &league, matches+fmt.Sprintf(`
+ORDER BY %s %s, p.id
+LIMIT $%d OFFSET $%d`, order, direction, len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("problem loading league, %w", err)
+	}
+	return league, total, nil
+}
@@ -0,0 +1,60 @@
+package poker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func (store *DatabaseStore) Revision(ctx context.Context) (int, error) {
+	var revision int
+	if err := store.db.GetContext(ctx, &revision, "SELECT revision FROM store_revision"); err != nil {
+		return 0, fmt.Errorf("problem loading store revision, %w", err)
+	}
+	return revision, nil
+}
+
+func (store *DatabaseStore) PlayerRevision(ctx context.Context, id int) (int, error) {
+	var revision int
+	err := store.db.GetContext(ctx, &revision, "SELECT revision FROM players WHERE id = $1 AND deleted_at IS NULL", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w: no player with id %d", ErrPlayerNotFound, id)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("problem loading revision of player %d, %w", id, err)
+	}
+	return revision, nil
+}
+
+// bumpRevision moves the store on to its next revision and marks playerIDs
+// as changed at it, once the revisions they are at pass the precondition of
+// ctx. Every transaction that changes the store calls it; taking the next
+// revision locks its row, so changes to the same player check their
+// precondition one after the other. Players that do not exist are skipped,
+// callers report them.
+func bumpRevision(ctx context.Context, tx *sqlx.Tx, playerIDs ...int) error {
+	var revision int
+	if err := tx.GetContext(ctx, &revision, "UPDATE store_revision SET revision = revision + 1 RETURNING revision"); err != nil {
+		return fmt.Errorf("failed to update store revision, %w", err)
+	}
+	for _, id := range playerIDs {
+		var current int
+		err := tx.GetContext(ctx, &current, "SELECT revision FROM players WHERE id = $1", id)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("problem loading revision of player %d, %w", id, err)
+		}
+		if err := checkPlayerRevision(ctx, id, current); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE players SET revision = $1 WHERE id = $2", revision, id); err != nil {
+			return fmt.Errorf("failed to update revision of player %d, %w", id, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package poker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type seasonRow struct {
+	ID       int          `db:"id"`
+	Name     string       `db:"name"`
+	StartsAt time.Time    `db:"starts_at"`
+	EndsAt   sql.NullTime `db:"ends_at"`
+	ClosedAt sql.NullTime `db:"closed_at"`
+}
+
+func (row seasonRow) season() Season {
+	season := Season{ID: row.ID, Name: row.Name, StartsAt: row.StartsAt.UTC()}
+	if row.EndsAt.Valid {
+		endsAt := row.EndsAt.Time.UTC()
+		season.EndsAt = &endsAt
+	}
+	if row.ClosedAt.Valid {
+		closedAt := row.ClosedAt.Time.UTC()
+		season.ClosedAt = &closedAt
+	}
+	return season
+}
+
+const seasonQuery = "SELECT id, name, starts_at, ends_at, closed_at FROM seasons"
+
+func (store *DatabaseStore) CreateSeason(ctx context.Context, season *Season) error {
+	if season == nil {
+		return fmt.Errorf("%w: no season provided - nil pointer", ErrInvalidSeason)
+	}
+	if season.StartsAt.IsZero() {
+		season.StartsAt = time.Now()
+	}
+	season.StartsAt = season.StartsAt.UTC()
+	if season.EndsAt != nil {
+		endsAt := season.EndsAt.UTC()
+		season.EndsAt = &endsAt
+	}
+	if err := season.check(); err != nil {
+		return err
+	}
+
+	err := store.withTx(ctx, func(tx *sqlx.Tx) error {
+		// Taking the next revision first makes seasons created at the
+		// same time check for overlaps one after the other.
+		if err := bumpRevision(ctx, tx); err != nil {
+			return err
+		}
+		if err := checkOverlap(ctx, tx, *season); err != nil {
+			return err
+		}
+		return tx.QueryRowxContext(ctx, "INSERT INTO seasons (name, starts_at, ends_at) VALUES ($1, $2, $3) RETURNING id",
+			season.Name, season.StartsAt, season.EndsAt).Scan(&season.ID)
+	})
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%w: a season named %q already exists", ErrInvalidSeason, season.Name)
+	}
+	if errors.Is(err, ErrInvalidSeason) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create season, %w", err)
+	}
+	season.ClosedAt = nil
+	return nil
+}
+
+func (store *DatabaseStore) CloseSeason(ctx context.Context, id int) (Season, error) {
+	err := store.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := bumpRevision(ctx, tx); err != nil {
+			return err
+		}
+		season, err := getSeason(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if season.Closed() {
+			return fmt.Errorf("%w: season %d was closed on %s", ErrSeasonClosed, id, season.ClosedAt.Format(time.DateOnly))
+		}
+		return closeSeason(ctx, tx, season, time.Now().UTC())
+	})
+	if err != nil {
+		return Season{}, err
+	}
+	return store.GetSeason(ctx, id)
+}
+
+// closeEndedSeasons closes the open seasons whose EndsAt has passed, so they
+// read like seasons closed by hand once they are over.
+func (store *DatabaseStore) closeEndedSeasons(ctx context.Context) error {
+	now := time.Now().UTC()
+	const ended = " WHERE closed_at IS NULL AND ends_at <= $1"
+	var count int
+	if err := store.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM seasons"+ended, now); err != nil {
+		return fmt.Errorf("problem looking for ended seasons, %w", err)
+	}
+	if count == 0 {
+		return nil
+	}
+	return store.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := bumpRevision(ctx, tx); err != nil {
+			return err
+		}
+		// Another request may have closed them since they were counted.
+		var rows []seasonRow
+		if err := tx.SelectContext(ctx, &rows, seasonQuery+ended, now); err != nil {
+			return fmt.Errorf("problem loading ended seasons, %w", err)
+		}
+		for _, row := range rows {
+			if err := closeSeason(ctx, tx, row.season(), now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// closeSeason keeps the standings of season and marks it closed at now. A
+// season still running ends now.
+func closeSeason(ctx context.Context, tx *sqlx.Tx, season Season, now time.Time) error {
+	if season.EndsAt == nil || season.EndsAt.After(now) {
+		season.EndsAt = &now
+	}
+	standings, err := seasonStandings(ctx, tx, season)
+	if err != nil {
+		return err
+	}
+	for rank, player := range standings {
+		_, err := tx.ExecContext(ctx, "INSERT INTO season_standings (season_id, player_id, name, wins, rank) VALUES ($1, $2, $3, $4, $5)",
+			season.ID, player.ID, player.Name, player.Wins, rank+1)
+		if err != nil {
+			return fmt.Errorf("failed to keep standings of season %d, %w", season.ID, err)
+		}
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE seasons SET ends_at = $1, closed_at = $2 WHERE id = $3", season.EndsAt, now, season.ID)
+	if err != nil {
+		return fmt.Errorf("failed to close season %d, %w", season.ID, err)
+	}
+	return nil
+}
+
+func (store *DatabaseStore) GetSeason(ctx context.Context, id int) (Season, error) {
+	if err := store.closeEndedSeasons(ctx); err != nil {
+		return Season{}, err
+	}
+	season, err := getSeason(ctx, store.db, id)
+	if err != nil {
+		return Season{}, err
+	}
+	if season.Closed() {
+		season.Standings = League{}
+		err = store.db.SelectContext(ctx, &season.Standings,
+			"SELECT player_id AS id, name, wins FROM season_standings WHERE season_id = $1 ORDER BY rank", id)
+	} else {
+		season.Standings, err = seasonStandings(ctx, store.db, season)
+	}
+	if err != nil {
+		return Season{}, fmt.Errorf("problem loading standings of season %d, %w", id, err)
+	}
+	return season, nil
+}
+
+func (store *DatabaseStore) ListSeasons(ctx context.Context) ([]Season, error) {
+	if err := store.closeEndedSeasons(ctx); err != nil {
+		return nil, err
+	}
+	var rows []seasonRow
+	if err := store.db.SelectContext(ctx, &rows, seasonQuery+" ORDER BY starts_at DESC, id DESC"); err != nil {
+		return nil, fmt.Errorf("problem loading seasons, %w", err)
+	}
+	seasons := make([]Season, len(rows))
+	for i, row := range rows {
+		seasons[i] = row.season()
+	}
+	return seasons, nil
+}
+
+// checkOverlap makes sure season shares no time with another season. An
+// open season without an EndsAt runs on for ever.
+func checkOverlap(ctx context.Context, tx *sqlx.Tx, season Season) error {
+	query := "SELECT name FROM seasons WHERE (ends_at IS NULL OR ends_at > $1)"
+	args := []interface{}{season.StartsAt}
+	if season.EndsAt != nil {
+		query += " AND starts_at < $2"
+		args = append(args, *season.EndsAt)
+	}
+	var names []string
+	if err := tx.SelectContext(ctx, &names, query+" ORDER BY starts_at", args...); err != nil {
+		return fmt.Errorf("problem looking for overlapping seasons, %w", err)
+	}
+	if len(names) > 0 {
+		return fmt.Errorf("%w: season %q overlaps season %q", ErrInvalidSeason, season.Name, names[0])
+	}
+	return nil
+}
+
+func getSeason(ctx context.Context, db sqlx.QueryerContext, id int) (Season, error) {
+	var row seasonRow
+	err := sqlx.GetContext(ctx, db, &row, seasonQuery+" WHERE id = $1", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Season{}, fmt.Errorf("%w: no season with id %d", ErrSeasonNotFound, id)
+	}
+	if err != nil {
+		return Season{}, fmt.Errorf("problem loading season %d, %w", id, err)
+	}
+	return row.season(), nil
+}
+
+// seasonStandings counts the wins of everyone who played a game in season,
+// ordered like the league. Deleted players are left out, as they are from
+// the league.
+func seasonStandings(ctx context.Context, db sqlx.QueryerContext, season Season) (League, error) {
+	query := `SELECT p.id, p.username AS name, COUNT(gr.id) AS wins
+FROM game_participants AS gp
+JOIN games AS g ON g.id = gp.game_id
+JOIN players AS p ON p.id = gp.player_id
+LEFT JOIN game_results AS gr ON gr.game_id = gp.game_id AND gr.winner_id = gp.player_id
+WHERE p.deleted_at IS NULL AND g.game_date >= $1`
+	args := []interface{}{season.StartsAt}
+	if season.EndsAt != nil {
+		query += " AND g.game_date < $2"
+		args = append(args, *season.EndsAt)
+	}
+	query += `
+GROUP BY p.id, p.username
+ORDER BY wins DESC, p.id`
+
+	standings := League{}
+	if err := sqlx.SelectContext(ctx, db, &standings, query, args...); err != nil {
+		return nil, err
+	}
+	return standings, nil
+}
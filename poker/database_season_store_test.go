@@ -0,0 +1,172 @@
+package poker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDatabaseSeasonStore(t *testing.T) {
+	ctx := context.Background()
+	day := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 20, 0, 0, 0, time.UTC)
+	}
+
+	// playGame records a game on date won by the first of players.
+	playGame := func(t *testing.T, store *DatabaseStore, date time.Time, players ...int) {
+		t.Helper()
+		game := &GameRecord{Date: date}
+		for _, id := range players {
+			game.Participants = append(game.Participants, Participant{PlayerID: id})
+		}
+		assertNoError(t, store.CreateGame(ctx, game))
+		assertNoError(t, store.RecordResult(ctx, game.ID, players[:1]))
+	}
+
+	newStore := func(t *testing.T) *DatabaseStore {
+		store := createTestDatabase(t)
+		assertNoError(t, store.AddPlayer(&Player{ID: 1, Name: "Cleo"}))
+		assertNoError(t, store.AddPlayer(&Player{ID: 2, Name: "Chris"}))
+		assertNoError(t, store.AddPlayer(&Player{ID: 3, Name: "Lloyd"}))
+		return store
+	}
+
+	t.Run("counts the wins of games within the season", func(t *testing.T) {
+		store := newStore(t)
+		ends := day(time.April, 1)
+		spring := &Season{Name: "Spring", StartsAt: day(time.March, 1), EndsAt: &ends}
+		assertNoError(t, store.CreateSeason(ctx, spring))
+
+		playGame(t, store, day(time.February, 1), 1, 2)
+		playGame(t, store, day(time.March, 2), 2, 1)
+		playGame(t, store, day(time.March, 9), 2, 3)
+		playGame(t, store, day(time.April, 2), 3, 1)
+
+		season, err := store.GetSeason(ctx, spring.ID)
+		assertNoError(t, err)
+		assertLeague(t, season.Standings, []Player{
+			{ID: 2, Name: "Chris", Wins: 2},
+			{ID: 1, Name: "Cleo", Wins: 0},
+			{ID: 3, Name: "Lloyd", Wins: 0},
+		})
+	})
+
+	t.Run("closing keeps the standings", func(t *testing.T) {
+		store := newStore(t)
+		open := &Season{Name: "Summer", StartsAt: day(time.June, 1)}
+		assertNoError(t, store.CreateSeason(ctx, open))
+		playGame(t, store, day(time.June, 2), 1, 2)
+
+		closed, err := store.CloseSeason(ctx, open.ID)
+		assertNoError(t, err)
+		if !closed.Closed() || closed.EndsAt == nil {
+			t.Fatalf("expected the season to be closed with an end, got %+v", closed)
+		}
+
+		// A result recorded afterwards for a game inside the season.
+		playGame(t, store, day(time.June, 3), 2, 1)
+
+		season, err := store.GetSeason(ctx, open.ID)
+		assertNoError(t, err)
+		assertLeague(t, season.Standings, []Player{
+			{ID: 1, Name: "Cleo", Wins: 1},
+			{ID: 2, Name: "Chris", Wins: 0},
+		})
+
+		if _, err := store.CloseSeason(ctx, open.ID); !errors.Is(err, ErrSeasonClosed) {
+			t.Errorf("got error %v want %v", err, ErrSeasonClosed)
+		}
+	})
+
+	t.Run("leaves deleted players out", func(t *testing.T) {
+		store := newStore(t)
+		open := &Season{Name: "Autumn", StartsAt: day(time.September, 1)}
+		assertNoError(t, store.CreateSeason(ctx, open))
+		playGame(t, store, day(time.September, 2), 2, 1)
+		assertNoError(t, store.DeletePlayer(2))
+
+		season, err := store.GetSeason(ctx, open.ID)
+		assertNoError(t, err)
+		assertLeague(t, season.Standings, []Player{{ID: 1, Name: "Cleo", Wins: 0}})
+	})
+
+	t.Run("lists seasons latest first", func(t *testing.T) {
+		store := newStore(t)
+		ends := day(time.June, 1)
+		assertNoError(t, store.CreateSeason(ctx, &Season{Name: "Spring", StartsAt: day(time.March, 1), EndsAt: &ends}))
+		assertNoError(t, store.CreateSeason(ctx, &Season{Name: "Summer", StartsAt: day(time.June, 1)}))
+
+		seasons, err := store.ListSeasons(ctx)
+		assertNoError(t, err)
+		if len(seasons) != 2 || seasons[0].Name != "Summer" || seasons[1].Name != "Spring" {
+			t.Errorf("expected Summer then Spring, got %+v", seasons)
+		}
+	})
+
+	t.Run("closes a season once it has ended", func(t *testing.T) {
+		store := newStore(t)
+		ends := day(time.April, 1)
+		spring := &Season{Name: "Spring", StartsAt: day(time.March, 1), EndsAt: &ends}
+		assertNoError(t, store.CreateSeason(ctx, spring))
+		playGame(t, store, day(time.March, 2), 1, 2)
+
+		seasons, err := store.ListSeasons(ctx)
+		assertNoError(t, err)
+		if len(seasons) != 1 || !seasons[0].Closed() || !seasons[0].EndsAt.Equal(ends) {
+			t.Fatalf("expected Spring closed and still ending on %v, got %+v", ends, seasons)
+		}
+
+		// A result recorded afterwards for a game inside the season.
+		playGame(t, store, day(time.March, 3), 2, 1)
+
+		season, err := store.GetSeason(ctx, spring.ID)
+		assertNoError(t, err)
+		assertLeague(t, season.Standings, []Player{
+			{ID: 1, Name: "Cleo", Wins: 1},
+			{ID: 2, Name: "Chris", Wins: 0},
+		})
+		if _, err := store.CloseSeason(ctx, spring.ID); !errors.Is(err, ErrSeasonClosed) {
+			t.Errorf("got error %v want %v", err, ErrSeasonClosed)
+		}
+	})
+
+	t.Run("leaves a season open until it ends", func(t *testing.T) {
+		store := newStore(t)
+		ends := time.Now().Add(time.Hour)
+		season := &Season{Name: "Now", StartsAt: day(time.March, 1), EndsAt: &ends}
+		assertNoError(t, store.CreateSeason(ctx, season))
+
+		got, err := store.GetSeason(ctx, season.ID)
+		assertNoError(t, err)
+		if got.Closed() {
+			t.Errorf("expected the season to run for another hour, got %+v", got)
+		}
+	})
+
+	t.Run("rejects bad seasons", func(t *testing.T) {
+		store := newStore(t)
+		springEnds, summerEnds := day(time.April, 1), day(time.July, 1)
+		assertNoError(t, store.CreateSeason(ctx, &Season{Name: "Spring", StartsAt: day(time.March, 1), EndsAt: &springEnds}))
+		assertNoError(t, store.CreateSeason(ctx, &Season{Name: "Autumn", StartsAt: day(time.September, 1)}))
+		before, may, october := day(time.January, 1), day(time.May, 1), day(time.October, 1)
+
+		for name, season := range map[string]*Season{
+			"no name":              {StartsAt: day(time.March, 1)},
+			"ends before":          {Name: "Winter", StartsAt: day(time.March, 1), EndsAt: &before},
+			"name taken":           {Name: "Spring", StartsAt: day(time.June, 1), EndsAt: &summerEnds},
+			"overlaps the start":   {Name: "Late winter", StartsAt: day(time.February, 1), EndsAt: &may},
+			"inside another":       {Name: "Easter", StartsAt: day(time.March, 20), EndsAt: &springEnds},
+			"runs into open one":   {Name: "Late summer", StartsAt: day(time.June, 1), EndsAt: &october},
+			"open before open one": {Name: "Summer", StartsAt: day(time.June, 1)},
+			"after the open one":   {Name: "Winter", StartsAt: day(time.December, 1)},
+		} {
+			if err := store.CreateSeason(ctx, season); !errors.Is(err, ErrInvalidSeason) {
+				t.Errorf("%s: got error %v want %v", name, err, ErrInvalidSeason)
+			}
+		}
+		if _, err := store.GetSeason(ctx, 99); !errors.Is(err, ErrSeasonNotFound) {
+			t.Errorf("got error %v want %v", err, ErrSeasonNotFound)
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package poker
+
+import (
+	"application/migrations"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// resetPostgres empties every table the migrations create but
+// schema_version, and puts back the one row of store_revision.
+func resetPostgres(db *sqlx.DB) error {
+	_, err := db.Exec(`TRUNCATE players, games, game_results, game_participants, seasons,
+	season_standings, reverted_wins, store_revision RESTART IDENTITY CASCADE`)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec("INSERT INTO store_revision (revision) VALUES (0)")
+	return err
+}
+
+// createTestDatabase returns a store on a fresh SQLite database, or on the
+// Postgres database in POKER_TEST_POSTGRES_DSN when it is set.
+func createTestDatabase(t testing.TB) *DatabaseStore {
+	t.Helper()
+
+	if dsn := os.Getenv("POKER_TEST_POSTGRES_DSN"); dsn != "" {
+		store, err := NewDatabaseStore(dsn)
+		if err != nil {
+			t.Fatalf("could not connect to postgres %v", err)
+		}
+		migrator, err := migrations.New(store.db)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := migrator.Up(); err != nil {
+			t.Fatalf("could not migrate postgres database %v", err)
+		}
+		if err := resetPostgres(store.db); err != nil {
+			t.Fatalf("could not reset postgres database %v", err)
+		}
+		t.Cleanup(func() { store.Close() })
+		return store
+	}
+
+	store, err := NewSQLitePlayerStore(filepath.Join(t.TempDir(), "game.db"))
+	if err != nil {
+		t.Fatalf("could not create sqlite player store %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store.DatabaseStore
+}
+
+func TestDatabaseStore(t *testing.T) {
+
+	t.Run("add players and get league sorted", func(t *testing.T) {
+		store := createTestDatabase(t)
+
+		assertNoError(t, store.AddPlayer(&Player{1, "Cleo", 10, nil}))
+		assertNoError(t, store.AddPlayer(&Player{2, "Chris", 33, nil}))
+
+		got := store.GetLeague()
+		want := []Player{
+			{2, "Chris", 33, nil},
+			{1, "Cleo", 10, nil},
+		}
+		assertLeague(t, got, want)
+	})
+
+	t.Run("assigns an id when none is given", func(t *testing.T) {
+		store := createTestDatabase(t)
+
+		player := &Player{Name: "Cleo"}
+		assertNoError(t, store.AddPlayer(player))
+
+		if player.ID == 0 {
+			t.Error("expected the player to be given an id")
+		}
+	})
+
+	t.Run("store wins for existing players", func(t *testing.T) {
+		store := createTestDatabase(t)
+		assertNoError(t, store.AddPlayer(&Player{1, "Cleo", 2, nil}))
+
+		assertNoError(t, store.RecordWin(1))
+
+		assertScoreEquals(t, store.GetPlayerScore(1), 3)
+	})
+
+	t.Run("records each win as a game result", func(t *testing.T) {
+		store := createTestDatabase(t)
+		assertNoError(t, store.AddPlayer(&Player{1, "Cleo", 0, nil}))
+		assertNoError(t, store.RecordWin(1))
+
+		var results []ResultConfig
+		err := store.db.Select(&results, "SELECT id, game_id, winner_id, amount_won FROM game_results")
+		assertNoError(t, err)
+
+		if len(results) != 1 || results[0].WinnerID != 1 || results[0].GameID == 0 {
+			t.Errorf("expected one result linked to a game, got %+v", results)
+		}
+	})
+
+	t.Run("keeps the wins a player joins with apart from the games", func(t *testing.T) {
+		store := createTestDatabase(t)
+		assertNoError(t, store.AddPlayer(&Player{1, "Cleo", 3, nil}))
+
+		var games int
+		assertNoError(t, store.db.Get(&games, "SELECT COUNT(*) FROM games"))
+		if games != 0 {
+			t.Errorf("expected no games for the wins a player joined with, got %d", games)
+		}
+		assertScoreEquals(t, store.GetPlayerScore(1), 3)
+		assertError(t, store.AddPlayer(&Player{2, "Chris", -1, nil}))
+	})
+
+	t.Run("folds the case of names beyond ASCII", func(t *testing.T) {
+		store := createTestDatabase(t)
+		assertNoError(t, store.AddPlayer(&Player{1, "Élodie", 0, nil}))
+
+		found, err := store.FindByName("ÉLODIE")
+		assertNoError(t, err)
+		if found.ID != 1 {
+			t.Errorf("got player %+v, want Élodie", found)
+		}
+		assertErrorIs(t, store.AddPlayer(&Player{2, "ÉLODIE", 0, nil}), ErrDuplicatePlayer)
+		if _, err := store.db.Exec("INSERT INTO players (id, username) VALUES (3, 'ÉLODIE')"); err == nil {
+			t.Error("expected the unique index to refuse ÉLODIE next to Élodie")
+		}
+	})
+
+	t.Run("record win for missing player", func(t *testing.T) {
+		store := createTestDatabase(t)
+
+		assertError(t, store.RecordWin(3))
+	})
+
+	t.Run("delete player", func(t *testing.T) {
+		store := createTestDatabase(t)
+		assertNoError(t, store.AddPlayer(&Player{1, "Cleo", 4, nil}))
+		assertNoError(t, store.AddPlayer(&Player{2, "Chris", 1, nil}))
+
+		assertNoError(t, store.DeletePlayer(1))
+
+		assertLeague(t, store.GetLeague(), []Player{{2, "Chris", 1, nil}})
+		assertScoreEquals(t, store.GetPlayerScore(1), 0)
+	})
+
+	t.Run("delete missing player", func(t *testing.T) {
+		store := createTestDatabase(t)
+
+		assertError(t, store.DeletePlayer(1))
+	})
+}
@@ -0,0 +1,146 @@
+package poker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type winRow struct {
+	ResultConfig
+	GameDate   time.Time      `db:"game_date"`
+	RevertedAt sql.NullTime   `db:"reverted_at"`
+	RevertedBy sql.NullString `db:"reverted_by"`
+	Reason     sql.NullString `db:"reason"`
+}
+
+func (row winRow) win() Win {
+	win := Win{ID: row.ID, PlayerID: row.WinnerID, GameID: row.GameID, At: row.GameDate.UTC()}
+	if row.RevertedAt.Valid {
+		win.Reverted = &Reversion{At: row.RevertedAt.Time.UTC(), By: row.RevertedBy.String, Reason: row.Reason.String}
+	}
+	return win
+}
+
+const winQuery = `SELECT gr.id, gr.game_id, gr.winner_id, gr.amount_won, g.game_date
+FROM game_results AS gr
+JOIN games AS g ON g.id = gr.game_id`
+
+const revertedWinQuery = `SELECT rw.id, rw.game_id, rw.winner_id, rw.amount_won, g.game_date, rw.reverted_at, rw.reverted_by, rw.reason
+FROM reverted_wins AS rw
+JOIN games AS g ON g.id = rw.game_id`
+
+func (store *DatabaseStore) Wins(ctx context.Context, playerID int) ([]Win, error) {
+	var wins []Win
+	err := store.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := playerExists(ctx, tx, playerID); err != nil {
+			return err
+		}
+		var rows, reverted []winRow
+		if err := tx.SelectContext(ctx, &rows, winQuery+"\nWHERE gr.winner_id = $1", playerID); err != nil {
+			return fmt.Errorf("problem loading wins of player %d, %w", playerID, err)
+		}
+		if err := tx.SelectContext(ctx, &reverted, revertedWinQuery+"\nWHERE rw.winner_id = $1", playerID); err != nil {
+			return fmt.Errorf("problem loading reverted wins of player %d, %w", playerID, err)
+		}
+		wins = make([]Win, 0, len(rows)+len(reverted))
+		for _, row := range append(rows, reverted...) {
+			wins = append(wins, row.win())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Results keep their id when they are reverted, and ids are handed out
+	// in the order wins are recorded.
+	sort.Slice(wins, func(i, j int) bool {
+		return wins[i].ID < wins[j].ID
+	})
+	return wins, nil
+}
+
+func (store *DatabaseStore) UndoLastWin(ctx context.Context, playerID int, reversion Reversion) (Win, error) {
+	if err := reversion.check(); err != nil {
+		return Win{}, err
+	}
+	var win Win
+	err := store.withTx(ctx, func(tx *sqlx.Tx) error {
+		if err := bumpRevision(ctx, tx, playerID); err != nil {
+			return err
+		}
+		if err := playerExists(ctx, tx, playerID); err != nil {
+			return err
+		}
+		var last sql.NullInt64
+		if err := tx.GetContext(ctx, &last, "SELECT MAX(id) FROM game_results WHERE winner_id = $1", playerID); err != nil {
+			return fmt.Errorf("problem finding the last win of player %d, %w", playerID, err)
+		}
+		if !last.Valid {
+			return fmt.Errorf("%w: player %d has no wins left to undo", ErrWinNotFound, playerID)
+		}
+		var err error
+		win, err = revokeWin(ctx, tx, int(last.Int64), reversion)
+		return err
+	})
+	return win, err
+}
+
+func (store *DatabaseStore) RevokeWin(ctx context.Context, winID int, reversion Reversion) (Win, error) {
+	if err := reversion.check(); err != nil {
+		return Win{}, err
+	}
+	var win Win
+	err := store.withTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		if win, err = revokeWin(ctx, tx, winID, reversion); err != nil {
+			return err
+		}
+		return bumpRevision(ctx, tx, win.PlayerID)
+	})
+	return win, err
+}
+
+// revokeWin moves a game result over to reverted_wins. The game loses its
+// finishing order along with its winner, so a corrected result can be
+// recorded for it.
+func revokeWin(ctx context.Context, tx *sqlx.Tx, winID int, reversion Reversion) (Win, error) {
+	var row winRow
+	err := tx.GetContext(ctx, &row, winQuery+"\nWHERE gr.id = $1", winID)
+	if errors.Is(err, sql.ErrNoRows) {
+		var reverted winRow
+		err = tx.GetContext(ctx, &reverted, revertedWinQuery+"\nWHERE rw.id = $1", winID)
+		if err == nil {
+			return reverted.win().revert(reversion)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return Win{}, fmt.Errorf("%w: no win with id %d", ErrWinNotFound, winID)
+		}
+	}
+	if err != nil {
+		return Win{}, fmt.Errorf("problem loading win %d, %w", winID, err)
+	}
+
+	win, err := row.win().revert(reversion)
+	if err != nil {
+		return Win{}, err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO reverted_wins (id, game_id, winner_id, amount_won, reverted_at, reverted_by, reason)
+VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		row.ID, row.GameID, row.WinnerID, row.AmountWon, win.Reverted.At, win.Reverted.By, win.Reverted.Reason)
+	if err != nil {
+		return Win{}, fmt.Errorf("failed to keep reverted win %d, %w", winID, err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM game_results WHERE id = $1", winID); err != nil {
+		return Win{}, fmt.Errorf("failed to revert win %d, %w", winID, err)
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE game_participants SET position = NULL WHERE game_id = $1", row.GameID); err != nil {
+		return Win{}, fmt.Errorf("failed to clear the result of game %d, %w", row.GameID, err)
+	}
+	return win, nil
+}
@@ -0,0 +1,79 @@
+package poker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// DefaultRetention is how long a deleted player can be restored before a
+// purge removes them for good.
+const DefaultRetention = 30 * 24 * time.Hour
+
+// DeletedPlayerStore is implemented by stores that delete players softly.
+// A deleted player keeps their id, name and wins, so the id and name stay
+// taken, but is left out of every other call until restored.
+//
+// Errors wrap ErrPlayerNotFound where it applies.
+type DeletedPlayerStore interface {
+	// DeletedPlayers lists the deleted players, ordered by id.
+	DeletedPlayers(ctx context.Context) (League, error)
+	// RestorePlayer brings a deleted player back with their wins.
+	RestorePlayer(ctx context.Context, id int) (Player, error)
+	// PurgePlayers removes the players deleted before cutoff for good and
+	// returns how many there were. Stores that keep games keep the results
+	// of the purged players, without their names; the others drop their
+	// wins with them.
+	PurgePlayers(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// active returns the players of l that are not deleted.
+func (l League) active() League {
+	active := make(League, 0, len(l))
+	for _, player := range l {
+		if !player.Deleted() {
+			active = append(active, player)
+		}
+	}
+	return active
+}
+
+// deleted returns the deleted players of l ordered by id.
+func (l League) deleted() League {
+	deleted := League{}
+	for _, player := range l {
+		if player.Deleted() {
+			deleted = append(deleted, player)
+		}
+	}
+	sort.Slice(deleted, func(i, j int) bool {
+		return deleted[i].ID < deleted[j].ID
+	})
+	return deleted
+}
+
+// findActive is Find for players that are not deleted.
+func (l League) findActive(id int) *Player {
+	if player := l.Find(id); player != nil && !player.Deleted() {
+		return player
+	}
+	return nil
+}
+
+func noDeletedPlayer(id int) error {
+	return fmt.Errorf("%w: no deleted player with id %d", ErrPlayerNotFound, id)
+}
+
+// PrintDeleted writes deleted players as a table with the day they were
+// deleted.
+func PrintDeleted(out io.Writer, league League) error {
+	table := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tPlayer\tWins\tDeleted")
+	for _, player := range league {
+		fmt.Fprintf(table, "%d\t%s\t%d\t%s\n", player.ID, player.Name, player.Wins, player.DeletedAt.Format(time.DateOnly))
+	}
+	return table.Flush()
+}
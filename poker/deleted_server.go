@@ -0,0 +1,92 @@
+package poker
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PurgeResult is the answer to a purge.
+type PurgeResult struct {
+	Purged int       `json:"purged"`
+	Cutoff time.Time `json:"cutoff"`
+}
+
+// deletedHandler serves deleted players to admins:
+//
+//	GET  /deleted/                          list the deleted players
+//	POST /deleted/{id}/restore              bring a player back
+//	POST /deleted/purge?older_than=720h     remove players deleted before then
+//
+// older_than defaults to DefaultRetention.
+func (p *PlayerServer) deletedHandler(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/deleted/"), "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "":
+		p.deletedAllowed(w, r, http.MethodGet, p.listDeleted)
+	case len(parts) == 1 && parts[0] == "purge":
+		p.deletedAllowed(w, r, http.MethodPost, p.purgeDeleted)
+	case len(parts) == 2 && parts[1] == "restore":
+		p.deletedAllowed(w, r, http.MethodPost, func(w http.ResponseWriter, r *http.Request) {
+			id, err := strconv.Atoi(parts[0])
+			if err != nil {
+				badRequest(w, "must provide a valid id (int)")
+				return
+			}
+			player, err := p.deleted.RestorePlayer(r.Context(), id)
+			if err != nil {
+				storeError(w, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, player)
+		})
+	default:
+		notFound(w, r)
+	}
+}
+
+// deletedAllowed calls next for a request made with method by an admin on a
+// server whose store deletes players softly, and answers it itself otherwise.
+func (p *PlayerServer) deletedAllowed(w http.ResponseWriter, r *http.Request, method string, next http.HandlerFunc) {
+	if !allowMethod(w, r, method) {
+		return
+	}
+	if p.deleted == nil {
+		notSupported(w, "restoring deleted players is not supported by this store")
+		return
+	}
+	if !requireAdmin(w, r, "deleted players") {
+		return
+	}
+	next(w, r)
+}
+
+func (p *PlayerServer) listDeleted(w http.ResponseWriter, r *http.Request) {
+	league, err := p.deleted.DeletedPlayers(r.Context())
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, league)
+}
+
+func (p *PlayerServer) purgeDeleted(w http.ResponseWriter, r *http.Request) {
+	retention := DefaultRetention
+	if value := r.URL.Query().Get("older_than"); value != "" {
+		var err error
+		retention, err = time.ParseDuration(value)
+		if err != nil || retention < 0 {
+			badRequest(w, "older_than must be a non-negative duration such as 720h, got %q", value)
+			return
+		}
+	}
+	result := PurgeResult{Cutoff: time.Now().UTC().Add(-retention)}
+	var err error
+	result.Purged, err = p.deleted.PurgePlayers(r.Context(), result.Cutoff)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
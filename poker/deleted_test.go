@@ -0,0 +1,89 @@
+package poker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestDeletedEndpoints(t *testing.T) {
+	log := NewFileAuditLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	server := NewPlayerServer(createTestDatabase(t), WithAuditLog(log))
+	admin := func(r *http.Request) *http.Request {
+		return r.WithContext(WithActor(r.Context(), Actor{Name: "root", Role: AdminRole}))
+	}
+	serve := func(request *http.Request) *httptest.ResponseRecorder {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		return response
+	}
+
+	assertStatus(t, serve(newGameRequest(http.MethodPost, "/create/", `{"id": 1, "name": "Cleo"}`)).Code, http.StatusCreated)
+	assertStatus(t, serve(newGameRequest(http.MethodDelete, "/delete/1", "")).Code, http.StatusNoContent)
+
+	t.Run("only admins may see deleted players", func(t *testing.T) {
+		assertStatus(t, serve(newGameRequest(http.MethodGet, "/deleted/", "")).Code, http.StatusForbidden)
+		assertStatus(t, serve(newGameRequest(http.MethodPost, "/deleted/1/restore", "")).Code, http.StatusForbidden)
+	})
+
+	t.Run("lists and restores a deleted player", func(t *testing.T) {
+		response := serve(admin(newGameRequest(http.MethodGet, "/deleted/", "")))
+		assertStatus(t, response.Code, http.StatusOK)
+		var deleted League
+		if err := json.NewDecoder(response.Body).Decode(&deleted); err != nil {
+			t.Fatalf("unable to parse deleted players from response %q, %v", response.Body, err)
+		}
+		if len(deleted) != 1 || deleted[0].Name != "Cleo" || !deleted[0].Deleted() {
+			t.Fatalf("expected Cleo to be deleted, got %+v", deleted)
+		}
+
+		assertStatus(t, serve(admin(newGameRequest(http.MethodPost, "/deleted/1/restore", ""))).Code, http.StatusOK)
+		assertStatus(t, serve(admin(newGameRequest(http.MethodPost, "/deleted/1/restore", ""))).Code, http.StatusNotFound)
+		assertStatus(t, serve(newGameRequest(http.MethodGet, "/info/1", "")).Code, http.StatusOK)
+	})
+
+	t.Run("purges players deleted before the retention period", func(t *testing.T) {
+		assertStatus(t, serve(newGameRequest(http.MethodDelete, "/delete/1", "")).Code, http.StatusNoContent)
+
+		for _, tt := range []struct {
+			olderThan string
+			purged    int
+		}{
+			{"", 0},
+			{"0s", 1},
+		} {
+			response := serve(admin(newGameRequest(http.MethodPost, "/deleted/purge?older_than="+tt.olderThan, "")))
+			assertStatus(t, response.Code, http.StatusOK)
+			var result PurgeResult
+			if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+				t.Fatalf("unable to parse purge result from response %q, %v", response.Body, err)
+			}
+			if result.Purged != tt.purged {
+				t.Errorf("older than %q: got %d purged want %d", tt.olderThan, result.Purged, tt.purged)
+			}
+		}
+		assertStatus(t, serve(admin(newGameRequest(http.MethodPost, "/deleted/purge?older_than=soon", ""))).Code, http.StatusBadRequest)
+	})
+
+	t.Run("audits restores and purges", func(t *testing.T) {
+		entries, _, err := log.List(context.Background(), 0, 2)
+		assertNoError(t, err)
+		if len(entries) != 2 || entries[0].Action != "PurgePlayer" || entries[1].Action != "DeletePlayer" {
+			t.Fatalf("expected the purge after the second delete, got %+v", entries)
+		}
+		entries, _, err = log.List(context.Background(), 2, 1)
+		assertNoError(t, err)
+		if len(entries) != 1 || entries[0].Action != "RestorePlayer" || entries[0].After.Deleted() {
+			t.Errorf("expected the restore, got %+v", entries)
+		}
+	})
+
+	t.Run("is not implemented without soft delete", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		NewPlayerServer(&StubPlayerStore{}).ServeHTTP(response, admin(newGameRequest(http.MethodGet, "/deleted/", "")))
+		assertStatus(t, response.Code, http.StatusNotImplemented)
+	})
+}
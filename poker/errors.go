@@ -0,0 +1,45 @@
+package poker
+
+import "errors"
+
+// Errors returned by the player stores. Stores wrap them with details, so
+// compare with errors.Is.
+var (
+	ErrPlayerNotFound  = errors.New("player not found")
+	ErrDuplicatePlayer = errors.New("player already exists")
+	ErrInvalidPlayer   = errors.New("invalid player")
+)
+
+// Errors returned by game stores, wrapped the same way.
+var (
+	ErrGameNotFound = errors.New("game not found")
+	ErrGameFinished = errors.New("game already has a result")
+	ErrInvalidGame  = errors.New("invalid game")
+)
+
+// Errors returned by season stores, wrapped the same way.
+var (
+	ErrSeasonNotFound = errors.New("season not found")
+	ErrSeasonClosed   = errors.New("season already closed")
+	ErrInvalidSeason  = errors.New("invalid season")
+)
+
+// Errors returned when reverting wins, wrapped the same way.
+var (
+	ErrWinNotFound      = errors.New("win not found")
+	ErrWinReverted      = errors.New("win already reverted")
+	ErrInvalidReversion = errors.New("invalid reversion")
+)
+
+// ErrNotSupported is returned for work that needs an optional store
+// feature, like GameStore, that the store does not have.
+var ErrNotSupported = errors.New("not supported by this store")
+
+// ErrInvalidQuery is returned for a league query that cannot be run, see
+// LeagueQuery.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// ErrRevisionMismatch is returned when a change was made on the condition
+// that a player is still at a revision they have since moved on from, see
+// IfPlayerRevision.
+var ErrRevisionMismatch = errors.New("revision mismatch")
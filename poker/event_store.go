@@ -0,0 +1,778 @@
+package poker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+type EventType string
+
+const (
+	PlayerAdded   EventType = "PlayerAdded"
+	WinRecorded   EventType = "WinRecorded"
+	PlayerDeleted EventType = "PlayerDeleted"
+	WinReverted   EventType = "WinReverted"
+	// PlayerDeleted only marks the player deleted, PlayerRestored brings
+	// them back and PlayerPurged removes them from the league for good.
+	PlayerRestored EventType = "PlayerRestored"
+	PlayerPurged   EventType = "PlayerPurged"
+	PlayerRenamed  EventType = "PlayerRenamed"
+	// GameCreated holds the new game in Game. GameFinished names the game in
+	// GameID, its winner in PlayerID and the finishing order in Order, and
+	// counts as a win of the winner like WinRecorded. A WinReverted event
+	// that takes it back names the game too.
+	GameCreated  EventType = "GameCreated"
+	GameFinished EventType = "GameFinished"
+)
+
+// Event is one line of the event log. Seq numbers start at 1 and grow by one
+// with every event. A WinReverted event names the WinRecorded or
+// GameFinished event it takes back in Win.
+type Event struct {
+	Seq      int         `json:"seq"`
+	Type     EventType   `json:"type"`
+	At       time.Time   `json:"at"`
+	PlayerID int         `json:"player_id"`
+	Name     string      `json:"name,omitempty"`
+	Wins     int         `json:"wins,omitempty"`
+	Win      int         `json:"win,omitempty"`
+	By       string      `json:"by,omitempty"`
+	Reason   string      `json:"reason,omitempty"`
+	Game     *GameRecord `json:"game,omitempty"`
+	GameID   int         `json:"game_id,omitempty"`
+	Order    []int       `json:"order,omitempty"`
+}
+
+type snapshot struct {
+	Seq       int          `json:"seq"`
+	At        time.Time    `json:"at"`
+	NextID    int          `json:"next_id"`
+	League    League       `json:"league"`
+	Revisions map[int]int  `json:"revisions,omitempty"`
+	Purged    map[int]bool `json:"purged,omitempty"`
+	Games     []GameRecord `json:"games,omitempty"`
+}
+
+const defaultSnapshotEvery = 1000
+
+// EventSourcedPlayerStore appends every change to a JSON lines log and builds
+// the league by replaying it. Every so often the league is written to a
+// snapshot and the events it covers move from the log to an archive, so
+// startup only replays what happened since the last snapshot while the
+// archive keeps the full history.
+//
+// For a log at path the snapshot lives in path + ".snapshot" and the archive
+// in path + ".archive".
+//
+// The store's revision is the sequence number of its last event, and a
+// player's revision that of the last event about them.
+type EventSourcedPlayerStore struct {
+	mu            sync.Mutex
+	path          string
+	log           *os.File
+	league        League
+	revisions     map[int]int
+	purged        map[int]bool
+	games         []GameRecord
+	seq           int
+	nextID        int
+	sinceSnapshot int
+	snapshotEvery int
+}
+
+type EventStoreOption func(*EventSourcedPlayerStore)
+
+// WithSnapshotEvery takes a snapshot and compacts the log after n events.
+func WithSnapshotEvery(n int) EventStoreOption {
+	return func(e *EventSourcedPlayerStore) {
+		e.snapshotEvery = n
+	}
+}
+
+func NewEventSourcedPlayerStore(path string, options ...EventStoreOption) (*EventSourcedPlayerStore, error) {
+	store := &EventSourcedPlayerStore{
+		path:          path,
+		league:        League{},
+		revisions:     map[int]int{},
+		purged:        map[int]bool{},
+		nextID:        1,
+		snapshotEvery: defaultSnapshotEvery,
+	}
+	for _, option := range options {
+		option(store)
+	}
+
+	if err := store.loadSnapshot(); err != nil {
+		return nil, err
+	}
+
+	events, err := readEvents(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		if event.Seq <= store.seq {
+			continue
+		}
+		store.apply(event)
+		store.sinceSnapshot++
+	}
+
+	if err := trimPartialLine(path); err != nil {
+		return nil, err
+	}
+	if err := store.openLog(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (e *EventSourcedPlayerStore) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.log.Close()
+}
+
+// V2 gives the store with contexts and errors on every call. A player's
+// revision is checked against the precondition of the context, see
+// IfPlayerRevision, before each change to them.
+func (e *EventSourcedPlayerStore) V2() PlayerStoreV2 {
+	return eventStoreV2{e}
+}
+
+func (e *EventSourcedPlayerStore) GetLeague() League {
+	league, _ := e.V2().GetLeague(context.Background())
+	return league
+}
+
+func (e *EventSourcedPlayerStore) GetPlayerScore(id int) int {
+	wins, _ := e.V2().GetPlayerScore(context.Background(), id)
+	return wins
+}
+
+func (e *EventSourcedPlayerStore) FindByName(name string) (Player, error) {
+	return e.V2().FindByName(context.Background(), name)
+}
+
+func (e *EventSourcedPlayerStore) RecordWin(id int) error {
+	return e.V2().RecordWin(context.Background(), id)
+}
+
+func (e *EventSourcedPlayerStore) AddPlayer(player *Player) error {
+	return e.V2().AddPlayer(context.Background(), player)
+}
+
+func (e *EventSourcedPlayerStore) DeletePlayer(id int) error {
+	return e.V2().DeletePlayer(context.Background(), id)
+}
+
+type eventStoreV2 struct {
+	*EventSourcedPlayerStore
+}
+
+func (e eventStoreV2) GetLeague(ctx context.Context) (League, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	league := e.league.active()
+	league.sortByWins()
+	return league, nil
+}
+
+func (e eventStoreV2) GetPlayer(ctx context.Context, id int) (Player, error) {
+	if err := ctx.Err(); err != nil {
+		return Player{}, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if player := e.league.findActive(id); player != nil {
+		return *player, nil
+	}
+	return Player{}, fmt.Errorf("%w: no player with id %d", ErrPlayerNotFound, id)
+}
+
+func (e eventStoreV2) GetPlayerScore(ctx context.Context, id int) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if player := e.league.findActive(id); player != nil {
+		return player.Wins, nil
+	}
+	return 0, fmt.Errorf("%w: no player with id %d", ErrPlayerNotFound, id)
+}
+
+func (e eventStoreV2) FindByName(ctx context.Context, name string) (Player, error) {
+	if err := ctx.Err(); err != nil {
+		return Player{}, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if player := e.league.active().FindByName(name); player != nil {
+		return *player, nil
+	}
+	return Player{}, fmt.Errorf("%w: no player named %q", ErrPlayerNotFound, name)
+}
+
+func (e eventStoreV2) RecordWin(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.checkActive(ctx, id); err != nil {
+		return err
+	}
+	return e.append(Event{Type: WinRecorded, PlayerID: id})
+}
+
+func (e eventStoreV2) AddPlayer(ctx context.Context, player *Player) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if player.Name == "" {
+		return fmt.Errorf("%w: player name cannot be empty", ErrInvalidPlayer)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.league.checkNew(*player); err != nil {
+		return err
+	}
+	if e.purged[player.ID] {
+		return fmt.Errorf("%w: id %d belonged to a purged player", ErrDuplicatePlayer, player.ID)
+	}
+
+	id := player.ID
+	if id == 0 {
+		id = e.nextID
+	}
+	if err := e.append(Event{Type: PlayerAdded, PlayerID: id, Name: player.Name, Wins: player.Wins}); err != nil {
+		return err
+	}
+	player.ID = id
+	return nil
+}
+
+func (e eventStoreV2) DeletePlayer(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.checkActive(ctx, id); err != nil {
+		return err
+	}
+	return e.append(Event{Type: PlayerDeleted, PlayerID: id})
+}
+
+func (e *EventSourcedPlayerStore) RenamePlayer(ctx context.Context, id int, name string) (Player, error) {
+	if err := ctx.Err(); err != nil {
+		return Player{}, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.league.checkRename(id, name); err != nil {
+		return Player{}, err
+	}
+	if err := checkPlayerRevision(ctx, id, e.revisions[id]); err != nil {
+		return Player{}, err
+	}
+	if err := e.append(Event{Type: PlayerRenamed, PlayerID: id, Name: name}); err != nil {
+		return Player{}, err
+	}
+	return *e.league.Find(id), nil
+}
+
+func (e *EventSourcedPlayerStore) DeletedPlayers(ctx context.Context) (League, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.league.deleted(), nil
+}
+
+func (e *EventSourcedPlayerStore) RestorePlayer(ctx context.Context, id int) (Player, error) {
+	if err := ctx.Err(); err != nil {
+		return Player{}, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if player := e.league.Find(id); player == nil || !player.Deleted() {
+		return Player{}, noDeletedPlayer(id)
+	}
+	if err := e.append(Event{Type: PlayerRestored, PlayerID: id}); err != nil {
+		return Player{}, err
+	}
+	return *e.league.Find(id), nil
+}
+
+// PurgePlayers removes players from the league. Their events stay in the
+// log and the archive, which hold the store's full history.
+func (e *EventSourcedPlayerStore) PurgePlayers(ctx context.Context, cutoff time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	purged := 0
+	for _, player := range e.league.deleted() {
+		if !player.DeletedAt.Before(cutoff) {
+			continue
+		}
+		if err := e.append(Event{Type: PlayerPurged, PlayerID: player.ID}); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// Wins lists the wins of a player, each identified by the sequence number of
+// its WinRecorded or GameFinished event.
+func (e *EventSourcedPlayerStore) Wins(ctx context.Context, playerID int) ([]Win, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.league.findActive(playerID) == nil {
+		return nil, fmt.Errorf("%w: no player with id %d", ErrPlayerNotFound, playerID)
+	}
+	events, err := e.history()
+	if err != nil {
+		return nil, err
+	}
+	return winsOf(events, playerID), nil
+}
+
+func (e *EventSourcedPlayerStore) UndoLastWin(ctx context.Context, playerID int, reversion Reversion) (Win, error) {
+	if err := ctx.Err(); err != nil {
+		return Win{}, err
+	}
+	if err := reversion.check(); err != nil {
+		return Win{}, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.checkActive(ctx, playerID); err != nil {
+		return Win{}, err
+	}
+	events, err := e.history()
+	if err != nil {
+		return Win{}, err
+	}
+	win, ok := lastWin(winsOf(events, playerID))
+	if !ok {
+		return Win{}, fmt.Errorf("%w: player %d has no wins left to undo", ErrWinNotFound, playerID)
+	}
+	return e.revert(win, reversion)
+}
+
+func (e *EventSourcedPlayerStore) RevokeWin(ctx context.Context, winID int, reversion Reversion) (Win, error) {
+	if err := ctx.Err(); err != nil {
+		return Win{}, err
+	}
+	if err := reversion.check(); err != nil {
+		return Win{}, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	events, err := e.history()
+	if err != nil {
+		return Win{}, err
+	}
+	for _, win := range winsOf(events, 0) {
+		if win.ID == winID && e.league.findActive(win.PlayerID) != nil {
+			return e.revert(win, reversion)
+		}
+	}
+	return Win{}, fmt.Errorf("%w: no win with id %d", ErrWinNotFound, winID)
+}
+
+func (e *EventSourcedPlayerStore) revert(win Win, reversion Reversion) (Win, error) {
+	reverted, err := win.revert(reversion)
+	if err != nil {
+		return Win{}, err
+	}
+	err = e.append(Event{
+		Type:     WinReverted,
+		At:       reverted.Reverted.At,
+		PlayerID: win.PlayerID,
+		Win:      win.ID,
+		GameID:   win.GameID,
+		By:       reversion.By,
+		Reason:   reversion.Reason,
+	})
+	if err != nil {
+		return Win{}, err
+	}
+	return reverted, nil
+}
+
+// winsOf replays the wins of a player, or of everyone for playerID 0.
+func winsOf(events []Event, playerID int) []Win {
+	var wins []Win
+	index := map[int]int{}
+	for _, event := range events {
+		if playerID != 0 && event.PlayerID != playerID {
+			continue
+		}
+		switch event.Type {
+		case WinRecorded, GameFinished:
+			index[event.Seq] = len(wins)
+			wins = append(wins, Win{ID: event.Seq, PlayerID: event.PlayerID, GameID: event.GameID, At: event.At})
+		case WinReverted:
+			if i, ok := index[event.Win]; ok {
+				wins[i].Reverted = &Reversion{At: event.At, By: event.By, Reason: event.Reason}
+			}
+		}
+	}
+	return wins
+}
+
+func (e *EventSourcedPlayerStore) Revision(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.seq, nil
+}
+
+func (e *EventSourcedPlayerStore) PlayerRevision(ctx context.Context, id int) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.league.findActive(id) == nil {
+		return 0, fmt.Errorf("%w: no player with id %d", ErrPlayerNotFound, id)
+	}
+	return e.revisions[id], nil
+}
+
+// checkActive fails unless player id is in the league, not deleted, and
+// passes the revision precondition of ctx. Callers hold e.mu.
+func (e *EventSourcedPlayerStore) checkActive(ctx context.Context, id int) error {
+	if e.league.findActive(id) == nil {
+		return fmt.Errorf("%w: no player with id %d", ErrPlayerNotFound, id)
+	}
+	return checkPlayerRevision(ctx, id, e.revisions[id])
+}
+
+// History returns every event recorded so far, oldest first, including the
+// ones already compacted into the archive.
+func (e *EventSourcedPlayerStore) History() ([]Event, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.history()
+}
+
+// LeagueAt rebuilds the league as it stood at t.
+func (e *EventSourcedPlayerStore) LeagueAt(t time.Time) (League, error) {
+	events, err := e.History()
+	if err != nil {
+		return nil, err
+	}
+
+	league := League{}
+	for _, event := range events {
+		if event.At.After(t) {
+			break
+		}
+		league = league.apply(event)
+	}
+	league = league.active()
+	league.sortByWins()
+	return league, nil
+}
+
+// Snapshot writes the current league to the snapshot file and moves the
+// events it covers from the log to the archive.
+func (e *EventSourcedPlayerStore) Snapshot() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.snapshot()
+}
+
+func (e *EventSourcedPlayerStore) append(event Event) error {
+	event.Seq = e.seq + 1
+	if event.At.IsZero() {
+		event.At = time.Now().UTC()
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if _, err := e.log.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("problem appending to event log %s, %v", e.path, err)
+	}
+	if err := e.log.Sync(); err != nil {
+		return fmt.Errorf("problem syncing event log %s, %v", e.path, err)
+	}
+
+	e.apply(event)
+	e.sinceSnapshot++
+
+	// The event is durable at this point. Should the snapshot fail it is
+	// tried again after the next event.
+	if e.snapshotEvery > 0 && e.sinceSnapshot >= e.snapshotEvery {
+		_ = e.snapshot()
+	}
+	return nil
+}
+
+func (e *EventSourcedPlayerStore) snapshot() error {
+	data, err := json.Marshal(snapshot{Seq: e.seq, At: time.Now().UTC(), NextID: e.nextID, League: e.league, Revisions: e.revisions, Purged: e.purged, Games: e.games})
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(e.path+".snapshot", data); err != nil {
+		return err
+	}
+
+	// The snapshot is safe on disk, so the log can be compacted. Should this
+	// stop half way the events are in both files, which replay tolerates by
+	// skipping sequence numbers it has already seen.
+	events, err := readEvents(e.path)
+	if err != nil {
+		return err
+	}
+	if err := appendEvents(e.path+".archive", events); err != nil {
+		return err
+	}
+	// The log is closed before it is replaced, and opened again whether or
+	// not that worked, so a failed compaction leaves the store appending to
+	// the log it had.
+	err = e.log.Close()
+	if err == nil {
+		err = writeFileAtomic(e.path, nil)
+	}
+	if reopenErr := e.openLog(); reopenErr != nil {
+		return errors.Join(err, reopenErr)
+	}
+	if err != nil {
+		return err
+	}
+	e.sinceSnapshot = 0
+	return nil
+}
+
+func (e *EventSourcedPlayerStore) history() ([]Event, error) {
+	archived, err := readEvents(e.path + ".archive")
+	if err != nil {
+		return nil, err
+	}
+	recent, err := readEvents(e.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	last := 0
+	for _, event := range append(archived, recent...) {
+		if event.Seq <= last {
+			continue
+		}
+		events = append(events, event)
+		last = event.Seq
+	}
+	return events, nil
+}
+
+func (e *EventSourcedPlayerStore) loadSnapshot() error {
+	data, err := os.ReadFile(e.path + ".snapshot")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("problem reading snapshot for %s, %v", e.path, err)
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("problem parsing snapshot for %s, %v", e.path, err)
+	}
+	e.seq = snap.Seq
+	if snap.League != nil {
+		e.league = snap.League
+	}
+	if snap.Revisions != nil {
+		e.revisions = snap.Revisions
+	}
+	if snap.Purged != nil {
+		e.purged = snap.Purged
+	}
+	e.games = snap.Games
+	e.nextID = max(snap.NextID, e.league.nextID())
+	return nil
+}
+
+// apply brings the store's state up to date with an event that is already
+// in the log. Ids are never handed out twice, not even after a player is
+// deleted or purged, so history keeps pointing at the right player.
+func (e *EventSourcedPlayerStore) apply(event Event) {
+	e.league = e.league.apply(event)
+	e.seq = event.Seq
+	if event.Type == PlayerPurged {
+		delete(e.revisions, event.PlayerID)
+		e.purged[event.PlayerID] = true
+	} else if event.PlayerID != 0 {
+		e.revisions[event.PlayerID] = event.Seq
+	}
+	switch event.Type {
+	case GameCreated:
+		e.games = append(e.games, *event.Game)
+	case GameFinished:
+		if game := e.game(event.GameID); game != nil {
+			*game = game.finished(event.Order)
+		}
+	case WinReverted:
+		// Like the SQL stores, a game whose win is taken back has no
+		// winner any more.
+		if game := e.game(event.GameID); game != nil {
+			game.WinnerID = 0
+		}
+	}
+	if event.Type == PlayerAdded && event.PlayerID >= e.nextID {
+		e.nextID = event.PlayerID + 1
+	}
+}
+
+func (e *EventSourcedPlayerStore) openLog() error {
+	log, err := os.OpenFile(e.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return fmt.Errorf("problem opening event log %s, %v", e.path, err)
+	}
+	e.log = log
+	return nil
+}
+
+func (l League) apply(event Event) League {
+	switch event.Type {
+	case PlayerAdded:
+		return append(l, Player{ID: event.PlayerID, Name: event.Name, Wins: event.Wins})
+	case WinRecorded, GameFinished:
+		if player := l.Find(event.PlayerID); player != nil {
+			player.Wins++
+		}
+	case WinReverted:
+		if player := l.Find(event.PlayerID); player != nil && player.Wins > 0 {
+			player.Wins--
+		}
+	case PlayerDeleted:
+		if player := l.Find(event.PlayerID); player != nil {
+			deletedAt := event.At
+			player.DeletedAt = &deletedAt
+		}
+	case PlayerRestored:
+		if player := l.Find(event.PlayerID); player != nil {
+			player.DeletedAt = nil
+		}
+	case PlayerRenamed:
+		if player := l.Find(event.PlayerID); player != nil {
+			player.Name = event.Name
+		}
+	case PlayerPurged:
+		for i, player := range l {
+			if player.ID == event.PlayerID {
+				return append(l[:i], l[i+1:]...)
+			}
+		}
+	}
+	return l
+}
+
+func readEvents(path string) ([]Event, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("problem opening event log %s, %v", path, err)
+	}
+	defer file.Close()
+
+	var events []Event
+	reader := bufio.NewReader(file)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// A line without its newline is a write that never finished.
+			return events, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("problem reading event log %s, %v", path, err)
+		}
+		var event Event
+		if err := json.Unmarshal(data, &event); err != nil {
+			return nil, fmt.Errorf("problem parsing event log %s line %d, %v", path, line, err)
+		}
+		events = append(events, event)
+	}
+}
+
+// trimPartialLine cuts off an event that was being written when the process
+// stopped, so new events are not appended onto the end of it.
+func trimPartialLine(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("problem reading event log %s, %v", path, err)
+	}
+	complete := bytes.LastIndexByte(data, '\n') + 1
+	if complete == len(data) {
+		return nil
+	}
+	return os.Truncate(path, int64(complete))
+}
+
+func appendEvents(path string, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return fmt.Errorf("problem opening %s, %v", path, err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return fmt.Errorf("problem writing %s, %v", path, err)
+		}
+	}
+	return file.Sync()
+}
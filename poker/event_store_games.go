@@ -0,0 +1,88 @@
+package poker
+
+import (
+	"context"
+	"fmt"
+)
+
+// The event store keeps its games in the log next to the players, so a game
+// and its result are replayed, snapshotted and archived like any other
+// change. Games are never removed, so a game's id is its place in the list.
+
+func (e *EventSourcedPlayerStore) CreateGame(ctx context.Context, game *GameRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if game == nil {
+		return fmt.Errorf("%w: no game provided - nil pointer", ErrInvalidGame)
+	}
+	if err := game.checkNew(); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := game.checkPlayers(e.league); err != nil {
+		return err
+	}
+	created := game.created(len(e.games) + 1)
+	if err := e.append(Event{Type: GameCreated, Game: &created}); err != nil {
+		return err
+	}
+	game.ID = created.ID
+	game.Date = created.Date
+	return nil
+}
+
+func (e *EventSourcedPlayerStore) RecordResult(ctx context.Context, gameID int, finishingOrder []int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	game := e.game(gameID)
+	if game == nil {
+		return fmt.Errorf("%w: no game with id %d", ErrGameNotFound, gameID)
+	}
+	if err := game.checkResult(finishingOrder); err != nil {
+		return err
+	}
+	winner := finishingOrder[0]
+	if err := checkPlayerRevision(ctx, winner, e.revisions[winner]); err != nil {
+		return err
+	}
+	return e.append(Event{Type: GameFinished, PlayerID: winner, GameID: gameID, Order: finishingOrder})
+}
+
+func (e *EventSourcedPlayerStore) GetGame(ctx context.Context, id int) (GameRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return GameRecord{}, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	game := e.game(id)
+	if game == nil {
+		return GameRecord{}, fmt.Errorf("%w: no game with id %d", ErrGameNotFound, id)
+	}
+	return game.named(e.league), nil
+}
+
+func (e *EventSourcedPlayerStore) ListGames(ctx context.Context, filter GameFilter) ([]GameRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return filterGames(e.games, e.league, filter), nil
+}
+
+// game finds a game by id. Callers hold e.mu.
+func (e *EventSourcedPlayerStore) game(id int) *GameRecord {
+	if id < 1 || id > len(e.games) {
+		return nil
+	}
+	return &e.games[id-1]
+}
@@ -0,0 +1,187 @@
+package poker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func createEventStore(t testing.TB, path string, options ...EventStoreOption) *EventSourcedPlayerStore {
+	t.Helper()
+	store, err := NewEventSourcedPlayerStore(path, options...)
+	if err != nil {
+		t.Fatalf("could not create event sourced player store %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestEventSourcedPlayerStore(t *testing.T) {
+
+	t.Run("builds the league from events", func(t *testing.T) {
+		store := createEventStore(t, filepath.Join(t.TempDir(), "events.jsonl"))
+
+		assertNoError(t, store.AddPlayer(&Player{1, "Cleo", 10, nil}))
+		assertNoError(t, store.AddPlayer(&Player{2, "Chris", 33, nil}))
+		assertNoError(t, store.AddPlayer(&Player{3, "Lloyd", 0, nil}))
+		assertNoError(t, store.RecordWin(1))
+		assertNoError(t, store.DeletePlayer(3))
+
+		assertLeague(t, store.GetLeague(), []Player{
+			{2, "Chris", 33, nil},
+			{1, "Cleo", 11, nil},
+		})
+		assertScoreEquals(t, store.GetPlayerScore(1), 11)
+	})
+
+	t.Run("rejects changes to missing players", func(t *testing.T) {
+		store := createEventStore(t, filepath.Join(t.TempDir(), "events.jsonl"))
+
+		assertError(t, store.RecordWin(1))
+		assertError(t, store.DeletePlayer(1))
+		assertError(t, store.AddPlayer(&Player{ID: 1}))
+	})
+
+	t.Run("replays the log on startup", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.jsonl")
+		store := createEventStore(t, path)
+		assertNoError(t, store.AddPlayer(&Player{1, "Cleo", 0, nil}))
+		assertNoError(t, store.RecordWin(1))
+		assertNoError(t, store.Close())
+
+		store = createEventStore(t, path)
+
+		assertLeague(t, store.GetLeague(), []Player{{1, "Cleo", 1, nil}})
+	})
+
+	t.Run("ignores an event that was only half written", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.jsonl")
+		store := createEventStore(t, path)
+		assertNoError(t, store.AddPlayer(&Player{1, "Cleo", 0, nil}))
+		assertNoError(t, store.Close())
+
+		log, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0666)
+		assertNoError(t, err)
+		_, err = log.WriteString(`{"seq":2,"type":"WinRec`)
+		assertNoError(t, err)
+		log.Close()
+
+		store = createEventStore(t, path)
+		assertNoError(t, store.RecordWin(1))
+		assertNoError(t, store.Close())
+
+		store = createEventStore(t, path)
+		assertLeague(t, store.GetLeague(), []Player{{1, "Cleo", 1, nil}})
+	})
+
+	t.Run("does not revert a count below zero", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.jsonl")
+		store := createEventStore(t, path)
+		assertNoError(t, store.AddPlayer(&Player{1, "Cleo", 0, nil}))
+		assertNoError(t, store.Close())
+
+		log, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0666)
+		assertNoError(t, err)
+		_, err = log.WriteString(`{"seq":2,"type":"WinReverted","player_id":1,"win":7}` + "\n")
+		assertNoError(t, err)
+		log.Close()
+
+		store = createEventStore(t, path)
+		assertLeague(t, store.GetLeague(), []Player{{1, "Cleo", 0, nil}})
+	})
+
+	t.Run("snapshots and compacts the log", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.jsonl")
+		store := createEventStore(t, path, WithSnapshotEvery(3))
+		assertNoError(t, store.AddPlayer(&Player{1, "Cleo", 0, nil}))
+		assertNoError(t, store.RecordWin(1))
+		assertNoError(t, store.RecordWin(1))
+		assertNoError(t, store.RecordWin(1))
+
+		events, err := readEvents(path)
+		assertNoError(t, err)
+		if len(events) != 1 {
+			t.Errorf("expected the log to be compacted down to 1 event, got %d", len(events))
+		}
+
+		history, err := store.History()
+		assertNoError(t, err)
+		if len(history) != 4 {
+			t.Errorf("expected the full history of 4 events, got %d", len(history))
+		}
+
+		assertNoError(t, store.Close())
+		store = createEventStore(t, path, WithSnapshotEvery(3))
+		assertLeague(t, store.GetLeague(), []Player{{1, "Cleo", 3, nil}})
+	})
+
+	t.Run("keeps revisions in the snapshot", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.jsonl")
+		store := createEventStore(t, path, WithSnapshotEvery(2))
+		assertNoError(t, store.AddPlayer(&Player{1, "Cleo", 0, nil}))
+		assertNoError(t, store.AddPlayer(&Player{2, "Chris", 0, nil}))
+		assertNoError(t, store.RecordWin(1))
+
+		assertNoError(t, store.Close())
+		store = createEventStore(t, path, WithSnapshotEvery(2))
+		assertRevisions(t, store, 3, map[int]int{1: 3, 2: 2})
+	})
+
+	t.Run("keeps games in the snapshot", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.jsonl")
+		store := createEventStore(t, path, WithSnapshotEvery(3))
+		assertNoError(t, store.AddPlayer(&Player{1, "Cleo", 0, nil}))
+		assertNoError(t, store.AddPlayer(&Player{2, "Chris", 0, nil}))
+		game := &GameRecord{Participants: []Participant{{PlayerID: 1}, {PlayerID: 2}}}
+		assertNoError(t, store.CreateGame(context.Background(), game))
+		assertNoError(t, store.RecordResult(context.Background(), game.ID, []int{2, 1}))
+		assertNoError(t, store.Close())
+
+		store = createEventStore(t, path, WithSnapshotEvery(3))
+
+		got, err := store.GetGame(context.Background(), game.ID)
+		assertNoError(t, err)
+		if got.WinnerID != 2 || !reflect.DeepEqual(got.FinishingOrder(), []int{2, 1}) {
+			t.Errorf("got game %+v, want it won by Chris ahead of Cleo", got)
+		}
+		assertLeague(t, store.GetLeague(), []Player{{2, "Chris", 1, nil}, {1, "Cleo", 0, nil}})
+	})
+
+	t.Run("rebuilds the league at a point in time", func(t *testing.T) {
+		store := createEventStore(t, filepath.Join(t.TempDir(), "events.jsonl"), WithSnapshotEvery(2))
+		assertNoError(t, store.AddPlayer(&Player{1, "Cleo", 0, nil}))
+		assertNoError(t, store.RecordWin(1))
+
+		history, err := store.History()
+		assertNoError(t, err)
+		before := history[len(history)-1].At
+		time.Sleep(time.Millisecond)
+
+		assertNoError(t, store.RecordWin(1))
+		assertNoError(t, store.RecordWin(1))
+
+		got, err := store.LeagueAt(before)
+		assertNoError(t, err)
+		assertLeague(t, got, []Player{{1, "Cleo", 1, nil}})
+	})
+
+	t.Run("does not take the id of a purged player again", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.jsonl")
+		store := createEventStore(t, path, WithSnapshotEvery(2))
+		assertNoError(t, store.AddPlayer(&Player{1, "Cleo", 0, nil}))
+		assertNoError(t, store.AddPlayer(&Player{2, "Chris", 0, nil}))
+		assertNoError(t, store.RecordWin(2))
+		assertNoError(t, store.DeletePlayer(2))
+		_, err := store.PurgePlayers(context.Background(), time.Now().Add(time.Minute))
+		assertNoError(t, err)
+
+		assertErrorIs(t, store.AddPlayer(&Player{2, "Lloyd", 0, nil}), ErrDuplicatePlayer)
+		assertNoError(t, store.Close())
+
+		store = createEventStore(t, path, WithSnapshotEvery(2))
+		assertErrorIs(t, store.AddPlayer(&Player{2, "Lloyd", 0, nil}), ErrDuplicatePlayer)
+	})
+}
@@ -0,0 +1,5 @@
+package poker
+
+// ResetPostgres lets the conformance tests in poker_test start from an empty
+// Postgres database too.
+var ResetPostgres = resetPostgres
@@ -0,0 +1,49 @@
+package poker
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// fileLock is an advisory lock shared with every process that opens the same
+// lock file, taken with flock(2).
+type fileLock struct {
+	file *os.File
+}
+
+func newFileLock(path string) (*fileLock, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, fmt.Errorf("problem opening lock file %s, %v", path, err)
+	}
+	return &fileLock{file: file}, nil
+}
+
+func (l *fileLock) Lock() error {
+	return l.flock(syscall.LOCK_EX)
+}
+
+func (l *fileLock) RLock() error {
+	return l.flock(syscall.LOCK_SH)
+}
+
+func (l *fileLock) Unlock() error {
+	return l.flock(syscall.LOCK_UN)
+}
+
+func (l *fileLock) Close() error {
+	return l.file.Close()
+}
+
+func (l *fileLock) flock(how int) error {
+	for {
+		err := syscall.Flock(int(l.file.Fd()), how)
+		if err != syscall.EINTR {
+			if err != nil {
+				return fmt.Errorf("problem locking %s, %v", l.file.Name(), err)
+			}
+			return nil
+		}
+	}
+}
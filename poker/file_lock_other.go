@@ -0,0 +1,17 @@
+//go:build !linux
+
+package poker
+
+// fileLock does nothing outside Linux. Writers in the same process are still
+// serialised by FileSystemPlayerStore's mutex, but separate processes sharing
+// a league file are not.
+type fileLock struct{}
+
+func newFileLock(path string) (*fileLock, error) {
+	return &fileLock{}, nil
+}
+
+func (l *fileLock) Lock() error   { return nil }
+func (l *fileLock) RLock() error  { return nil }
+func (l *fileLock) Unlock() error { return nil }
+func (l *fileLock) Close() error  { return nil }
@@ -0,0 +1,217 @@
+package poker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// The league file only holds players, so FileSystemPlayerStore keeps its
+// games in a JSON file next to it, in path + ".games", rewritten whole on
+// every change. A result is written to the games file first, then counted in
+// the league and then logged as a win of the game, and each step is put back
+// should a later one fail. Reverting the win takes the result off again. Games are never removed, so a game's id is its
+// place in the file.
+
+func (f *FileSystemPlayerStore) gamesPath() string {
+	return f.tape.path + ".games"
+}
+
+func (f *FileSystemPlayerStore) CreateGame(ctx context.Context, game *GameRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if game == nil {
+		return fmt.Errorf("%w: no game provided - nil pointer", ErrInvalidGame)
+	}
+	if err := game.checkNew(); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	unlock, err := f.lockAndReload()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := game.checkPlayers(f.league); err != nil {
+		return err
+	}
+	games, err := readGames(f.gamesPath())
+	if err != nil {
+		return err
+	}
+	created := game.created(len(games) + 1)
+	if err := f.saveRevisions(f.league, nil); err != nil {
+		return fmt.Errorf("failed to create game, %v", err)
+	}
+	if err := writeGames(f.gamesPath(), append(games, created)); err != nil {
+		return err
+	}
+	game.ID = created.ID
+	game.Date = created.Date
+	return nil
+}
+
+func (f *FileSystemPlayerStore) RecordResult(ctx context.Context, gameID int, finishingOrder []int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	unlock, err := f.lockAndReload()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	games, err := readGames(f.gamesPath())
+	if err != nil {
+		return err
+	}
+	game := findGame(games, gameID)
+	if game == nil {
+		return fmt.Errorf("%w: no game with id %d", ErrGameNotFound, gameID)
+	}
+	if err := game.checkResult(finishingOrder); err != nil {
+		return err
+	}
+	winner := finishingOrder[0]
+	if err := checkPlayerRevision(ctx, winner, f.revisions.Players[winner]); err != nil {
+		return err
+	}
+
+	finished := append([]GameRecord(nil), games...)
+	*findGame(finished, gameID) = game.finished(finishingOrder)
+	if err := writeGames(f.gamesPath(), finished); err != nil {
+		return err
+	}
+	before := f.league
+	league := f.league.copy()
+	if player := league.Find(winner); player != nil {
+		player.Wins++
+	}
+	if err := f.save(league, winner); err != nil {
+		return f.undoGames(games, fmt.Errorf("failed to record result of game %d, %v", gameID, err))
+	}
+	if err := f.logWin(winner, gameID); err != nil {
+		return f.undoGames(games, f.undoSave(before, err, winner))
+	}
+	return nil
+}
+
+func (f *FileSystemPlayerStore) GetGame(ctx context.Context, id int) (GameRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return GameRecord{}, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.lock.RLock(); err != nil {
+		return GameRecord{}, err
+	}
+	defer f.lock.Unlock()
+	if err := f.reloadIfChanged(); err != nil {
+		return GameRecord{}, err
+	}
+
+	games, err := readGames(f.gamesPath())
+	if err != nil {
+		return GameRecord{}, err
+	}
+	game := findGame(games, id)
+	if game == nil {
+		return GameRecord{}, fmt.Errorf("%w: no game with id %d", ErrGameNotFound, id)
+	}
+	return game.named(f.league), nil
+}
+
+func (f *FileSystemPlayerStore) ListGames(ctx context.Context, filter GameFilter) ([]GameRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.lock.RLock(); err != nil {
+		return nil, err
+	}
+	defer f.lock.Unlock()
+	if err := f.reloadIfChanged(); err != nil {
+		return nil, err
+	}
+
+	games, err := readGames(f.gamesPath())
+	if err != nil {
+		return nil, err
+	}
+	return filterGames(games, f.league, filter), nil
+}
+
+// unfinishGame takes the result off game id, as the SQL stores do when its
+// win is reverted, and returns the games as they were, or nil when id is 0
+// or names no game. Callers hold f.mu and the exclusive file lock.
+func (f *FileSystemPlayerStore) unfinishGame(id int) ([]GameRecord, error) {
+	if id == 0 {
+		return nil, nil
+	}
+	games, err := readGames(f.gamesPath())
+	if err != nil {
+		return nil, err
+	}
+	if findGame(games, id) == nil {
+		return nil, nil
+	}
+	unfinished := append([]GameRecord(nil), games...)
+	findGame(unfinished, id).WinnerID = 0
+	if err := writeGames(f.gamesPath(), unfinished); err != nil {
+		return nil, err
+	}
+	return games, nil
+}
+
+// undoGames puts the games file back to games, as it was before a result
+// that could not be counted, and returns cause along with why the file could
+// not be put back if it could not. Callers hold f.mu and the exclusive file
+// lock.
+func (f *FileSystemPlayerStore) undoGames(games []GameRecord, cause error) error {
+	if err := writeGames(f.gamesPath(), games); err != nil {
+		return fmt.Errorf("%w, and the games file could not be put back, %v", cause, err)
+	}
+	return cause
+}
+
+func findGame(games []GameRecord, id int) *GameRecord {
+	if id < 1 || id > len(games) {
+		return nil
+	}
+	return &games[id-1]
+}
+
+func readGames(path string) ([]GameRecord, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("problem reading games %s, %v", path, err)
+	}
+	var games []GameRecord
+	if err := json.Unmarshal(data, &games); err != nil {
+		return nil, fmt.Errorf("problem parsing games %s, %v", path, err)
+	}
+	return games, nil
+}
+
+func writeGames(path string, games []GameRecord) error {
+	data, err := json.Marshal(games)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("problem writing games %s, %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package poker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+)
+
+// The league file has no room for revisions, so FileSystemPlayerStore keeps
+// them next to it, in path + ".revisions". The file is written before the
+// league file on every change and read again whenever the league is, so
+// processes sharing the store agree on its revisions. A change that stops
+// between the two writes only costs a revision number.
+//
+// The file also keeps the id the store gives the next player and the ids of
+// the players it purged, so the ids of purged players are not given out
+// again, nor taken by a caller that picks its own. Purging drops wins from
+// the win log, so it keeps the id of the next win too.
+
+type fileRevisions struct {
+	Revision  int          `json:"revision"`
+	Players   map[int]int  `json:"players"`
+	NextID    int          `json:"next_id,omitempty"`
+	Purged    map[int]bool `json:"purged,omitempty"`
+	NextWinID int          `json:"next_win_id,omitempty"`
+}
+
+// nextID is the id the store gives a new player when the caller did not
+// pick one: past every player it has ever had. Callers hold f.mu.
+func (f *FileSystemPlayerStore) nextID() int {
+	return max(f.revisions.NextID, f.league.nextID())
+}
+
+func (f *FileSystemPlayerStore) revisionsPath() string {
+	return f.tape.path + ".revisions"
+}
+
+func (f *FileSystemPlayerStore) Revision(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.reload(); err != nil {
+		return 0, err
+	}
+
+	return f.revisions.Revision, nil
+}
+
+func (f *FileSystemPlayerStore) PlayerRevision(ctx context.Context, id int) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.reload(); err != nil {
+		return 0, err
+	}
+
+	if f.league.findActive(id) == nil {
+		return 0, fmt.Errorf("%w: no player with id %d", ErrPlayerNotFound, id)
+	}
+	return f.revisions.Players[id], nil
+}
+
+// checkActive fails unless player id is in the league, not deleted, and
+// passes the revision precondition of ctx. Callers hold f.mu and the
+// exclusive file lock.
+func (f *FileSystemPlayerStore) checkActive(ctx context.Context, id int) error {
+	if f.league.findActive(id) == nil {
+		return fmt.Errorf("%w: no player with id %d", ErrPlayerNotFound, id)
+	}
+	return checkPlayerRevision(ctx, id, f.revisions.Players[id])
+}
+
+// saveRevisions moves the store on to its next revision, with changed marked
+// as changed at it and the players no longer in league dropped, and
+// remembered as purged until a purge that failed puts them back. Callers
+// hold f.mu and the exclusive file lock.
+func (f *FileSystemPlayerStore) saveRevisions(league League, changed []int) error {
+	next := fileRevisions{Revision: f.revisions.Revision + 1, Players: map[int]int{}, NextID: max(f.nextID(), league.nextID()), Purged: maps.Clone(f.revisions.Purged), NextWinID: f.revisions.NextWinID}
+	for _, player := range league {
+		if revision, ok := f.revisions.Players[player.ID]; ok {
+			next.Players[player.ID] = revision
+		}
+		delete(next.Purged, player.ID)
+	}
+	for _, player := range f.league {
+		if league.Find(player.ID) != nil {
+			continue
+		}
+		if next.Purged == nil {
+			next.Purged = map[int]bool{}
+		}
+		next.Purged[player.ID] = true
+	}
+	for _, id := range changed {
+		next.Players[id] = next.Revision
+	}
+	data, err := json.Marshal(next)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(f.revisionsPath(), data); err != nil {
+		return err
+	}
+	f.revisions = next
+	return nil
+}
+
+func readRevisions(path string) (fileRevisions, error) {
+	revisions := fileRevisions{Players: map[int]int{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return revisions, nil
+	}
+	if err != nil {
+		return fileRevisions{}, fmt.Errorf("problem reading revisions %s, %v", path, err)
+	}
+	if err := json.Unmarshal(data, &revisions); err != nil {
+		return fileRevisions{}, fmt.Errorf("problem parsing revisions %s, %v", path, err)
+	}
+	if revisions.Players == nil {
+		revisions.Players = map[int]int{}
+	}
+	return revisions, nil
+}
@@ -1,56 +1,117 @@
 package poker
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"os"
-	"sort"
+	"sync"
+	"time"
 )
 
+// FileSystemPlayerStore keeps the league in memory and writes all of it back
+// to its JSON file after every change. It is safe for concurrent use, also
+// by several processes sharing the file: every call takes an advisory lock
+// on a sibling .lock file and reloads the league if another process has
+// replaced the file since it was last read.
 type FileSystemPlayerStore struct {
-	database *json.Encoder
-	league   League
+	mu        sync.Mutex
+	lock      *fileLock
+	tape      *tape
+	database  *json.Encoder
+	league    League
+	revisions fileRevisions
+	loaded    os.FileInfo
 }
 
-func NewFileSystemPlayerStore(file *os.File) (*FileSystemPlayerStore, error) {
+type FileSystemStoreOption func(*FileSystemPlayerStore)
 
-	err := initialisePlayerDBFile(file)
+// WithBackup keeps the previous version of the league file next to it with a
+// .bak suffix.
+func WithBackup() FileSystemStoreOption {
+	return func(f *FileSystemPlayerStore) {
+		f.tape.backup = true
+	}
+}
+
+func NewFileSystemPlayerStore(file *os.File, options ...FileSystemStoreOption) (*FileSystemPlayerStore, error) {
+
+	lock, err := newFileLock(file.Name() + ".lock")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := lock.Lock(); err != nil {
+		lock.Close()
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	err = initialisePlayerDBFile(file)
 
 	if err != nil {
+		lock.Close()
 		return nil, fmt.Errorf("problem initialising player db file, %v", err)
 	}
 
 	league, err := NewLeague(file)
 
 	if err != nil {
+		lock.Close()
 		return nil, fmt.Errorf("problem loading player store from file %s, %v", file.Name(), err)
 	}
 
-	return &FileSystemPlayerStore{
-		database: json.NewEncoder(&tape{file}),
-		league:   league,
-	}, nil
+	loaded, err := file.Stat()
+
+	if err != nil {
+		lock.Close()
+		return nil, fmt.Errorf("problem getting file info from file %s, %v", file.Name(), err)
+	}
+
+	revisions, err := readRevisions(file.Name() + ".revisions")
+
+	if err != nil {
+		lock.Close()
+		return nil, err
+	}
+
+	store := &FileSystemPlayerStore{
+		lock:      lock,
+		tape:      &tape{path: file.Name()},
+		league:    league,
+		revisions: revisions,
+		loaded:    loaded,
+	}
+	store.database = json.NewEncoder(store.tape)
+
+	for _, option := range options {
+		option(store)
+	}
+
+	return store, nil
 }
 
-func FileSystemPlayerStoreFromFile(path string) (*FileSystemPlayerStore, func(), error) {
+func FileSystemPlayerStoreFromFile(path string, options ...FileSystemStoreOption) (*FileSystemPlayerStore, func(), error) {
 	db, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0666)
 
 	if err != nil {
 		return nil, nil, fmt.Errorf("problem opening %s %v", path, err)
 	}
 
-	closeFunc := func() {
-		db.Close()
-	}
-
-	store, err := NewFileSystemPlayerStore(db)
+	store, err := NewFileSystemPlayerStore(db, options...)
 
 	if err != nil {
+		db.Close()
 		return nil, nil, fmt.Errorf("problem creating file system player store, %v ", err)
 	}
 
+	closeFunc := func() {
+		store.Close()
+		db.Close()
+	}
+
 	return store, closeFunc, nil
 }
 
@@ -71,61 +132,376 @@ func initialisePlayerDBFile(file *os.File) error {
 	return nil
 }
 
+// Close releases the store's lock file.
+func (f *FileSystemPlayerStore) Close() error {
+	return f.lock.Close()
+}
+
+// V2 gives the store with contexts and errors on every call. A player's
+// revision is checked against the precondition of the context, see
+// IfPlayerRevision, before each change to them.
+func (f *FileSystemPlayerStore) V2() PlayerStoreV2 {
+	return fileStoreV2{f}
+}
+
 func (f *FileSystemPlayerStore) GetLeague() League {
-	sort.Slice(f.league, func(i, j int) bool {
-		return f.league[i].Wins > f.league[j].Wins
-	})
-	return f.league
+	league, _ := f.V2().GetLeague(context.Background())
+	return league
 }
 
 func (f *FileSystemPlayerStore) GetPlayerScore(id int) int {
+	wins, _ := f.V2().GetPlayerScore(context.Background(), id)
+	return wins
+}
 
-	player := f.league.Find(id)
+func (f *FileSystemPlayerStore) FindByName(name string) (Player, error) {
+	return f.V2().FindByName(context.Background(), name)
+}
 
-	if player != nil {
-		return player.Wins
+func (f *FileSystemPlayerStore) RecordWin(id int) error {
+	return f.V2().RecordWin(context.Background(), id)
+}
+
+func (f *FileSystemPlayerStore) AddPlayer(player *Player) error {
+	return f.V2().AddPlayer(context.Background(), player)
+}
+
+func (f *FileSystemPlayerStore) DeletePlayer(id int) error {
+	return f.V2().DeletePlayer(context.Background(), id)
+}
+
+type fileStoreV2 struct {
+	*FileSystemPlayerStore
+}
+
+func (f fileStoreV2) GetLeague(ctx context.Context) (League, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.refresh()
 
-	return 0
+	league := f.league.active()
+	league.sortByWins()
+	return league, nil
 }
 
-func (f *FileSystemPlayerStore) RecordWin(id int) error {
+func (f fileStoreV2) GetPlayer(ctx context.Context, id int) (Player, error) {
+	if err := ctx.Err(); err != nil {
+		return Player{}, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.refresh()
 
-	player := f.league.Find(id)
+	if player := f.league.findActive(id); player != nil {
+		return *player, nil
+	}
+	return Player{}, fmt.Errorf("%w: no player with id %d", ErrPlayerNotFound, id)
+}
 
-	if player != nil {
-		player.Wins++
-	} else {
-		return errors.New("player with this id not found")
+func (f fileStoreV2) GetPlayerScore(ctx context.Context, id int) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
 	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.refresh()
 
-	return f.database.Encode(f.league)
+	if player := f.league.findActive(id); player != nil {
+		return player.Wins, nil
+	}
+	return 0, fmt.Errorf("%w: no player with id %d", ErrPlayerNotFound, id)
 }
 
-func (f *FileSystemPlayerStore) AddPlayer(player *Player) error {
+func (f fileStoreV2) FindByName(ctx context.Context, name string) (Player, error) {
+	if err := ctx.Err(); err != nil {
+		return Player{}, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.refresh()
+
+	player := f.league.active().FindByName(name)
+
+	if player == nil {
+		return Player{}, fmt.Errorf("%w: no player named %q", ErrPlayerNotFound, name)
+	}
+
+	return *player, nil
+}
+
+func (f fileStoreV2) RecordWin(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	unlock, err := f.lockAndReload()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := f.checkActive(ctx, id); err != nil {
+		return err
+	}
+	before := f.league
+	league := f.league.copy()
+	league.findActive(id).Wins++
+
+	if err := f.save(league, id); err != nil {
+		return err
+	}
+	if err := f.logWin(id, 0); err != nil {
+		return f.undoSave(before, err, id)
+	}
+	return nil
+}
+
+func (f fileStoreV2) AddPlayer(ctx context.Context, player *Player) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if player.Name == "" {
-		return errors.New("player name cannot be empty")
+		return fmt.Errorf("%w: player name cannot be empty", ErrInvalidPlayer)
 	}
-	f.league = append(f.league, *player)
-	if err := f.database.Encode(f.league); err != nil {
-		return errors.New("failed to add player to database" + err.Error())
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	unlock, err := f.lockAndReload()
+	if err != nil {
+		return err
 	}
+	defer unlock()
+
+	if err := f.league.checkNew(*player); err != nil {
+		return err
+	}
+	if f.revisions.Purged[player.ID] {
+		return fmt.Errorf("%w: id %d belonged to a purged player", ErrDuplicatePlayer, player.ID)
+	}
+
+	added := *player
+	if added.ID == 0 {
+		added.ID = f.nextID()
+	}
+	league := append(f.league.copy(), added)
+	if err := f.save(league, added.ID); err != nil {
+		return fmt.Errorf("failed to add player to database, %v", err)
+	}
+	player.ID = added.ID
 	return nil
 }
 
-func (f *FileSystemPlayerStore) DeletePlayer(id int) error {
-	for i, player := range f.league {
-		if player.ID == id {
-			f.league = removeElement(f.league, i)
-			if err := f.database.Encode(f.league); err != nil {
-				return errors.New("failed to remove player from database" + err.Error())
-			}
-			return nil
+func (f fileStoreV2) DeletePlayer(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	unlock, err := f.lockAndReload()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := f.checkActive(ctx, id); err != nil {
+		return err
+	}
+	league := f.league.copy()
+	deletedAt := time.Now().UTC()
+	league.findActive(id).DeletedAt = &deletedAt
+	if err := f.save(league, id); err != nil {
+		return fmt.Errorf("failed to remove player from database, %v", err)
+	}
+	return nil
+}
+
+func (f *FileSystemPlayerStore) RenamePlayer(ctx context.Context, id int, name string) (Player, error) {
+	if err := ctx.Err(); err != nil {
+		return Player{}, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	unlock, err := f.lockAndReload()
+	if err != nil {
+		return Player{}, err
+	}
+	defer unlock()
+
+	if err := f.league.checkRename(id, name); err != nil {
+		return Player{}, err
+	}
+	if err := checkPlayerRevision(ctx, id, f.revisions.Players[id]); err != nil {
+		return Player{}, err
+	}
+	league := f.league.copy()
+	player := league.Find(id)
+	player.Name = name
+	renamed := *player
+	if err := f.save(league, id); err != nil {
+		return Player{}, fmt.Errorf("failed to rename player, %v", err)
+	}
+	return renamed, nil
+}
+
+func (f *FileSystemPlayerStore) DeletedPlayers(ctx context.Context) (League, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.refresh()
+
+	return f.league.deleted(), nil
+}
+
+func (f *FileSystemPlayerStore) RestorePlayer(ctx context.Context, id int) (Player, error) {
+	if err := ctx.Err(); err != nil {
+		return Player{}, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	unlock, err := f.lockAndReload()
+	if err != nil {
+		return Player{}, err
+	}
+	defer unlock()
+
+	league := f.league.copy()
+	player := league.Find(id)
+	if player == nil || !player.Deleted() {
+		return Player{}, noDeletedPlayer(id)
+	}
+	player.DeletedAt = nil
+	restored := *player
+	if err := f.save(league, id); err != nil {
+		return Player{}, fmt.Errorf("failed to restore player, %v", err)
+	}
+	return restored, nil
+}
+
+// PurgePlayers also drops the wins of the purged players from the win log.
+func (f *FileSystemPlayerStore) PurgePlayers(ctx context.Context, cutoff time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	unlock, err := f.lockAndReload()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	before := f.league
+	league := League{}
+	purged := map[int]bool{}
+	var ids []int
+	for _, player := range f.league {
+		if player.Deleted() && player.DeletedAt.Before(cutoff) {
+			purged[player.ID] = true
+			ids = append(ids, player.ID)
+			continue
 		}
+		league = append(league, player)
+	}
+	if len(purged) == 0 {
+		return 0, nil
 	}
-	return errors.New("player not found")
+	wins, err := readWins(f.winsPath())
+	if err != nil {
+		return 0, err
+	}
+	// The last wins in the log may be the purged players', so the ids they
+	// leave free are marked taken before they go.
+	f.revisions.NextWinID = f.nextWinID(wins)
+	if err := f.save(league); err != nil {
+		return 0, fmt.Errorf("failed to purge players, %v", err)
+	}
+	if err := f.purgeWins(wins, purged); err != nil {
+		return 0, f.undoSave(before, fmt.Errorf("failed to purge wins, %v", err), ids...)
+	}
+	return len(purged), nil
 }
 
-func removeElement(slice []Player, index int) []Player {
-	return append(slice[:index], slice[index+1:]...)
+// refresh brings a read up to date with changes made by other processes. If
+// the file cannot be read the league already in memory is served instead.
+// Callers hold f.mu.
+func (f *FileSystemPlayerStore) refresh() {
+	_ = f.reload()
+}
+
+// reload is refresh for reads that must not serve a stale answer, like
+// revisions clients compare against: it fails when the file cannot be read.
+// Callers hold f.mu.
+func (f *FileSystemPlayerStore) reload() error {
+	if err := f.lock.RLock(); err != nil {
+		return err
+	}
+	defer f.lock.Unlock()
+	return f.reloadIfChanged()
+}
+
+// lockAndReload takes the exclusive file lock ahead of a change and reloads
+// the league if another process has written it in the meantime. Callers hold
+// f.mu and must call the returned unlock once the change is saved.
+func (f *FileSystemPlayerStore) lockAndReload() (func(), error) {
+	if err := f.lock.Lock(); err != nil {
+		return nil, err
+	}
+	if err := f.reloadIfChanged(); err != nil {
+		f.lock.Unlock()
+		return nil, err
+	}
+	return func() { f.lock.Unlock() }, nil
+}
+
+func (f *FileSystemPlayerStore) reloadIfChanged() error {
+	current, err := os.Stat(f.tape.path)
+	if err != nil {
+		return fmt.Errorf("problem getting file info from file %s, %v", f.tape.path, err)
+	}
+	if os.SameFile(current, f.loaded) && current.ModTime().Equal(f.loaded.ModTime()) && current.Size() == f.loaded.Size() {
+		return nil
+	}
+
+	file, err := os.Open(f.tape.path)
+	if err != nil {
+		return fmt.Errorf("problem opening %s %v", f.tape.path, err)
+	}
+	defer file.Close()
+
+	league, err := NewLeague(file)
+	if err != nil {
+		return fmt.Errorf("problem reloading player store from file %s, %v", f.tape.path, err)
+	}
+	revisions, err := readRevisions(f.revisionsPath())
+	if err != nil {
+		return err
+	}
+	f.league = league
+	f.revisions = revisions
+	f.loaded = current
+	return nil
+}
+
+// save persists league and only then makes it the store's current state, so
+// a failed write leaves memory and disk in agreement. The store moves on to
+// its next revision, and the changed players with it, before the league is
+// written. Callers hold f.mu and the exclusive file lock.
+func (f *FileSystemPlayerStore) save(league League, changed ...int) error {
+	if err := f.saveRevisions(league, changed); err != nil {
+		return err
+	}
+	if err := f.database.Encode(league); err != nil {
+		return err
+	}
+	f.league = league
+	if info, err := os.Stat(f.tape.path); err == nil {
+		f.loaded = info
+	}
+	return nil
 }
@@ -1,8 +1,12 @@
 package poker
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"sync"
 	"testing"
+	"time"
 )
 
 func createTempFile(t testing.TB, initialData string) (*os.File, func()) {
@@ -25,6 +29,7 @@ func createTempFile(t testing.TB, initialData string) (*os.File, func()) {
 		if err = os.Remove(tmpFile.Name()); err != nil {
 			t.Fatalf("could not remove temp file %v", err)
 		}
+		os.Remove(tmpFile.Name() + ".lock")
 	}
 
 	return tmpFile, removeFile
@@ -45,8 +50,8 @@ func TestFileSystemStore(t *testing.T) {
 		got := store.GetLeague()
 
 		want := []Player{
-			{1, "Chris", 33},
-			{2, "Cleo", 10},
+			{1, "Chris", 33, nil},
+			{2, "Cleo", 10, nil},
 		}
 
 		assertLeague(t, got, want)
@@ -103,6 +108,48 @@ func TestFileSystemStore(t *testing.T) {
 		assertError(t, err)
 	})
 
+	t.Run("does not count a win it cannot log", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `[{"ID": 1, "Name": "Cleo", "Wins": 10}]`)
+		defer cleanDatabase()
+		store, err := NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+
+		// A directory where the log should be cannot be written to.
+		assertNoError(t, os.Mkdir(store.winsPath(), 0755))
+		defer os.Remove(store.winsPath())
+
+		assertError(t, store.RecordWin(1))
+		assertScoreEquals(t, store.GetPlayerScore(1), 10)
+
+		reopened, err := NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+		assertScoreEquals(t, reopened.GetPlayerScore(1), 10)
+	})
+
+	t.Run("does not give out the id of a purged win again", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `[
+			{"ID": 1, "Name": "Cleo", "Wins": 0},
+			{"ID": 2, "Name": "Chris", "Wins": 0}]`)
+		defer cleanDatabase()
+		store, err := NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+		assertNoError(t, store.RecordWin(1))
+		assertNoError(t, store.RecordWin(2))
+		assertNoError(t, store.DeletePlayer(2))
+		_, err = store.PurgePlayers(context.Background(), time.Now().Add(time.Minute))
+		assertNoError(t, err)
+
+		reopened, err := NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+		assertNoError(t, reopened.RecordWin(1))
+
+		wins, err := reopened.Wins(context.Background(), 1)
+		assertNoError(t, err)
+		if len(wins) != 2 || wins[1].ID != 3 {
+			t.Errorf("got wins %+v, want the new win to have id 3", wins)
+		}
+	})
+
 	t.Run("works with an empty file", func(t *testing.T) {
 		database, cleanDatabase := createTempFile(t, "")
 		defer cleanDatabase()
@@ -133,3 +180,152 @@ func assertError(t testing.TB, err error) {
 		t.Fatal("expected an error but didn't get one")
 	}
 }
+
+func TestFileSystemStoreConcurrency(t *testing.T) {
+	database, cleanDatabase := createTempFile(t, `[
+		{"id": 1, "name": "Cleo", "wins": 0}]`)
+	defer cleanDatabase()
+
+	store, err := NewFileSystemPlayerStore(database)
+	assertNoError(t, err)
+
+	const writers = 50
+	var wg sync.WaitGroup
+	wg.Add(writers * 2)
+	for i := 0; i < writers; i++ {
+		go func(i int) {
+			defer wg.Done()
+			if err := store.RecordWin(1); err != nil {
+				t.Errorf("could not record win, %v", err)
+			}
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			if err := store.AddPlayer(&Player{ID: i + 2, Name: fmt.Sprintf("Player%d", i)}); err != nil {
+				t.Errorf("could not add player, %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	reloaded, err := os.Open(database.Name())
+	assertNoError(t, err)
+	defer reloaded.Close()
+	league, err := NewLeague(reloaded)
+	assertNoError(t, err)
+
+	if len(league) != writers+1 {
+		t.Errorf("got %d players in the file, want %d", len(league), writers+1)
+	}
+	assertScoreEquals(t, league.Find(1).Wins, writers)
+}
+
+func TestFileSystemStoreSharedFile(t *testing.T) {
+
+	t.Run("sees players added through another store", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `[]`)
+		defer cleanDatabase()
+
+		rest, err := NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+		defer rest.Close()
+		graphql := openSecondStore(t, database.Name())
+
+		assertNoError(t, rest.AddPlayer(&Player{1, "Cleo", 0, nil}))
+		assertNoError(t, graphql.RecordWin(1))
+
+		assertScoreEquals(t, rest.GetPlayerScore(1), 1)
+	})
+
+	t.Run("does not lose wins written by another store", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `[
+			{"id": 1, "name": "Cleo", "wins": 0}]`)
+		defer cleanDatabase()
+
+		rest, err := NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+		defer rest.Close()
+		graphql := openSecondStore(t, database.Name())
+
+		const wins = 25
+		var wg sync.WaitGroup
+		for _, store := range []*FileSystemPlayerStore{rest, graphql} {
+			wg.Add(1)
+			go func(store *FileSystemPlayerStore) {
+				defer wg.Done()
+				for i := 0; i < wins; i++ {
+					if err := store.RecordWin(1); err != nil {
+						t.Errorf("could not record win, %v", err)
+					}
+				}
+			}(store)
+		}
+		wg.Wait()
+
+		assertScoreEquals(t, rest.GetPlayerScore(1), 2*wins)
+		assertScoreEquals(t, graphql.GetPlayerScore(1), 2*wins)
+	})
+
+	t.Run("agrees on revisions with another store", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `[]`)
+		defer cleanDatabase()
+
+		rest, err := NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+		defer rest.Close()
+		graphql := openSecondStore(t, database.Name())
+
+		assertNoError(t, rest.AddPlayer(&Player{1, "Cleo", 0, nil}))
+		assertNoError(t, graphql.AddPlayer(&Player{2, "Chris", 0, nil}))
+		assertNoError(t, rest.RecordWin(1))
+
+		for _, store := range []*FileSystemPlayerStore{rest, graphql} {
+			assertRevisions(t, store, 3, map[int]int{1: 3, 2: 2})
+		}
+	})
+
+	t.Run("does not answer revisions from a league it cannot reload", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `[]`)
+		defer cleanDatabase()
+
+		store, err := NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+		assertNoError(t, store.AddPlayer(&Player{1, "Cleo", 0, nil}))
+		assertNoError(t, os.WriteFile(database.Name(), []byte("not a league"), 0666))
+
+		if _, err := store.Revision(context.Background()); err == nil {
+			t.Error("expected an error reading the store revision")
+		}
+		if _, err := store.PlayerRevision(context.Background(), 1); err == nil {
+			t.Error("expected an error reading the revision of player 1")
+		}
+	})
+}
+
+// openSecondStore opens path again through its own file handle and lock, the
+// way a second server process would.
+func openSecondStore(t testing.TB, path string) *FileSystemPlayerStore {
+	t.Helper()
+	store, closeStore, err := FileSystemPlayerStoreFromFile(path)
+	assertNoError(t, err)
+	t.Cleanup(closeStore)
+	return store
+}
+
+// assertRevisions checks the revision of store and of the players in
+// players, by id.
+func assertRevisions(t testing.TB, store Revisioner, revision int, players map[int]int) {
+	t.Helper()
+	got, err := store.Revision(context.Background())
+	assertNoError(t, err)
+	if got != revision {
+		t.Errorf("got store revision %d want %d", got, revision)
+	}
+	for id, want := range players {
+		got, err := store.PlayerRevision(context.Background(), id)
+		assertNoError(t, err)
+		if got != want {
+			t.Errorf("got revision %d for player %d want %d", got, id, want)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package poker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// The league file only holds win counts, so FileSystemPlayerStore keeps the
+// wins it records in a JSON lines log next to it, in path + ".wins". A line
+// for a win that is already in the log replaces it, which is how reversions
+// are written. Wins recorded before the log existed are not in it.
+
+func (f *FileSystemPlayerStore) winsPath() string {
+	return f.tape.path + ".wins"
+}
+
+func (f *FileSystemPlayerStore) Wins(ctx context.Context, playerID int) ([]Win, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.lock.RLock(); err != nil {
+		return nil, err
+	}
+	defer f.lock.Unlock()
+	if err := f.reloadIfChanged(); err != nil {
+		return nil, err
+	}
+
+	if f.league.findActive(playerID) == nil {
+		return nil, fmt.Errorf("%w: no player with id %d", ErrPlayerNotFound, playerID)
+	}
+	wins, err := readWins(f.winsPath())
+	if err != nil {
+		return nil, err
+	}
+	return winsOfPlayer(wins, playerID), nil
+}
+
+func (f *FileSystemPlayerStore) UndoLastWin(ctx context.Context, playerID int, reversion Reversion) (Win, error) {
+	if err := ctx.Err(); err != nil {
+		return Win{}, err
+	}
+	if err := reversion.check(); err != nil {
+		return Win{}, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	unlock, err := f.lockAndReload()
+	if err != nil {
+		return Win{}, err
+	}
+	defer unlock()
+
+	if err := f.checkActive(ctx, playerID); err != nil {
+		return Win{}, err
+	}
+	wins, err := readWins(f.winsPath())
+	if err != nil {
+		return Win{}, err
+	}
+	win, ok := lastWin(winsOfPlayer(wins, playerID))
+	if !ok {
+		return Win{}, fmt.Errorf("%w: player %d has no wins left to undo", ErrWinNotFound, playerID)
+	}
+	return f.revert(win, reversion)
+}
+
+func (f *FileSystemPlayerStore) RevokeWin(ctx context.Context, winID int, reversion Reversion) (Win, error) {
+	if err := ctx.Err(); err != nil {
+		return Win{}, err
+	}
+	if err := reversion.check(); err != nil {
+		return Win{}, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	unlock, err := f.lockAndReload()
+	if err != nil {
+		return Win{}, err
+	}
+	defer unlock()
+
+	wins, err := readWins(f.winsPath())
+	if err != nil {
+		return Win{}, err
+	}
+	for _, win := range wins {
+		if win.ID == winID && f.league.findActive(win.PlayerID) != nil {
+			return f.revert(win, reversion)
+		}
+	}
+	return Win{}, fmt.Errorf("%w: no win with id %d", ErrWinNotFound, winID)
+}
+
+// logWin adds a win that was just saved to the league file to the log, for
+// game gameID or for none when it is 0. Should it fail, callers undo the
+// save. Callers hold f.mu and the exclusive file lock.
+func (f *FileSystemPlayerStore) logWin(playerID, gameID int) error {
+	wins, err := readWins(f.winsPath())
+	if err != nil {
+		return err
+	}
+	return appendWin(f.winsPath(), Win{ID: f.nextWinID(wins), PlayerID: playerID, GameID: gameID, At: time.Now().UTC()})
+}
+
+// nextWinID is the id of the next win: past every win in the log, and past
+// the wins purged from it. Callers hold f.mu.
+func (f *FileSystemPlayerStore) nextWinID(wins []Win) int {
+	id := max(f.revisions.NextWinID, 1)
+	if len(wins) > 0 {
+		id = max(id, wins[len(wins)-1].ID+1)
+	}
+	return id
+}
+
+// revert takes the result off the game of win, if it has one, then takes
+// win off the league and then marks it reverted in the log, putting back
+// what it changed if a later step fails.
+// Callers hold f.mu and the exclusive file lock.
+func (f *FileSystemPlayerStore) revert(win Win, reversion Reversion) (Win, error) {
+	reverted, err := win.revert(reversion)
+	if err != nil {
+		return Win{}, err
+	}
+	games, err := f.unfinishGame(win.GameID)
+	if err != nil {
+		return Win{}, err
+	}
+	undo := func(cause error) error {
+		if games == nil {
+			return cause
+		}
+		return f.undoGames(games, cause)
+	}
+	before := f.league
+	league := f.league.copy()
+	if player := league.Find(win.PlayerID); player != nil && player.Wins > 0 {
+		player.Wins--
+	}
+	if err := f.save(league, win.PlayerID); err != nil {
+		return Win{}, undo(fmt.Errorf("failed to revert win %d, %v", win.ID, err))
+	}
+	if err := appendWin(f.winsPath(), reverted); err != nil {
+		return Win{}, undo(f.undoSave(before, err, win.PlayerID))
+	}
+	return reverted, nil
+}
+
+// undoSave puts the league file back to league, as it was before a change
+// to the players changed that could not be logged, so the league and the
+// log keep agreeing. It returns cause, along with why the league could not
+// be put back if it could not. Callers hold f.mu and the exclusive file lock.
+func (f *FileSystemPlayerStore) undoSave(league League, cause error, changed ...int) error {
+	if err := f.save(league, changed...); err != nil {
+		return fmt.Errorf("%w, and the league file could not be put back, %v", cause, err)
+	}
+	return cause
+}
+
+// purgeWins rewrites the log, wins, without the wins of players.
+// Callers hold f.mu and the exclusive file lock.
+func (f *FileSystemPlayerStore) purgeWins(wins []Win, players map[int]bool) error {
+	if len(wins) == 0 {
+		return nil
+	}
+	var kept bytes.Buffer
+	encoder := json.NewEncoder(&kept)
+	for _, win := range wins {
+		if players[win.PlayerID] {
+			continue
+		}
+		if err := encoder.Encode(win); err != nil {
+			return err
+		}
+	}
+	return writeFileAtomic(f.winsPath(), kept.Bytes())
+}
+
+func winsOfPlayer(wins []Win, playerID int) []Win {
+	found := []Win{}
+	for _, win := range wins {
+		if win.PlayerID == playerID {
+			found = append(found, win)
+		}
+	}
+	return found
+}
+
+// readWins returns the wins in the log at path ordered by id, each as last
+// written.
+func readWins(path string) ([]Win, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("problem opening win log %s, %v", path, err)
+	}
+	defer file.Close()
+
+	var wins []Win
+	index := map[int]int{}
+	reader := bufio.NewReader(file)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// A line without its newline is a write that never finished.
+			return wins, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("problem reading win log %s, %v", path, err)
+		}
+		var win Win
+		if err := json.Unmarshal(data, &win); err != nil {
+			return nil, fmt.Errorf("problem parsing win log %s line %d, %v", path, line, err)
+		}
+		if i, ok := index[win.ID]; ok {
+			wins[i] = win
+			continue
+		}
+		index[win.ID] = len(wins)
+		wins = append(wins, win)
+	}
+}
+
+func appendWin(path string, win Win) error {
+	if err := trimPartialLine(path); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return fmt.Errorf("problem opening win log %s, %v", path, err)
+	}
+	defer file.Close()
+
+	if err := json.NewEncoder(file).Encode(win); err != nil {
+		return fmt.Errorf("problem writing win log %s, %v", path, err)
+	}
+	return file.Sync()
+}
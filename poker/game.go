@@ -2,5 +2,5 @@ package poker
 
 type Game interface {
 	Start(numberOfPlayers int)
-	Finish(winner string) error
+	Finish(winnerID int) error
 }
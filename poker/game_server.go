@@ -0,0 +1,282 @@
+package poker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewGame is the body of a request that creates a game.
+type NewGame struct {
+	Date         time.Time `json:"date"`
+	Location     string    `json:"location"`
+	Notes        string    `json:"notes"`
+	Participants []int     `json:"participants"`
+}
+
+// GameResult is the body of a request that finishes a game. FinishingOrder
+// lists player ids from first to last place.
+type GameResult struct {
+	FinishingOrder []int `json:"finishing_order"`
+}
+
+// check lists what is wrong with the players of g. Whether they exist is
+// left to the store.
+func (g NewGame) check() error {
+	var fields []FieldError
+	if len(g.Participants) < 2 {
+		fields = append(fields, FieldError{Field: "participants", Message: fmt.Sprintf("must list at least two players, got %d", len(g.Participants))})
+	}
+	fields = append(fields, checkPlayerIDs("participants", g.Participants)...)
+	if len(fields) > 0 {
+		return &ValidationError{Err: ErrInvalidGame, Fields: fields}
+	}
+	return nil
+}
+
+// check lists what is wrong with the finishing order of r. Whether its
+// players played the game is left to the store.
+func (r GameResult) check() error {
+	var fields []FieldError
+	if len(r.FinishingOrder) == 0 {
+		fields = append(fields, FieldError{Field: "finishing_order", Message: "must name at least the winner"})
+	}
+	fields = append(fields, checkPlayerIDs("finishing_order", r.FinishingOrder)...)
+	if len(fields) > 0 {
+		return &ValidationError{Err: ErrInvalidGame, Fields: fields}
+	}
+	return nil
+}
+
+func checkPlayerIDs(field string, ids []int) []FieldError {
+	var fields []FieldError
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		switch {
+		case id <= 0 || id > MaxPlayerID:
+			fields = append(fields, FieldError{Field: field, Message: fmt.Sprintf("must only hold player ids, got %d", id)})
+		case seen[id]:
+			fields = append(fields, FieldError{Field: field, Message: fmt.Sprintf("must not list player %d twice", id)})
+		}
+		seen[id] = true
+	}
+	return fields
+}
+
+// gamesHandler serves the game history:
+//
+//	GET  /games/             list games, see parseGameFilter
+//	POST /games/             create a game from a NewGame
+//	GET  /games/{id}         one game
+//	POST /games/{id}/result  finish a game with a GameResult
+//	PUT  /games/{id}/money   record a list of Stakes
+//	GET  /games/{id}/settlement  who owes whom, see SettleGame
+func (p *PlayerServer) gamesHandler(w http.ResponseWriter, r *http.Request) {
+	if p.games == nil {
+		notSupported(w, "this store keeps no game history")
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/games/"), "/"), "/")
+	if parts[0] == "" {
+		switch r.Method {
+		case http.MethodGet:
+			p.listGames(w, r)
+		case http.MethodPost:
+			p.createGame(w, r)
+		default:
+			methodNotAllowed(w, r, http.MethodGet, http.MethodPost)
+		}
+		return
+	}
+
+	id, err := strconv.Atoi(parts[0])
+	if err != nil {
+		badRequest(w, "must provide a valid game id (int)")
+		return
+	}
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		p.getGame(w, r, id)
+	case len(parts) == 2 && parts[1] == "result" && r.Method == http.MethodPost:
+		p.recordResult(w, r, id)
+	case len(parts) == 2 && parts[1] == "money" && r.Method == http.MethodPut:
+		p.recordMoney(w, r, id)
+	case len(parts) == 2 && parts[1] == "settlement" && r.Method == http.MethodGet:
+		p.getSettlement(w, r, id)
+	case len(parts) == 1, len(parts) == 2 && parts[1] == "settlement":
+		methodNotAllowed(w, r, http.MethodGet)
+	case len(parts) == 2 && parts[1] == "result":
+		methodNotAllowed(w, r, http.MethodPost)
+	case len(parts) == 2 && parts[1] == "money":
+		methodNotAllowed(w, r, http.MethodPut)
+	default:
+		notFound(w, r)
+	}
+}
+
+func (p *PlayerServer) listGames(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseGameFilter(r)
+	if err != nil {
+		badRequest(w, "%v", err)
+		return
+	}
+	games, err := p.games.ListGames(r.Context(), filter)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, games)
+}
+
+func (p *PlayerServer) createGame(w http.ResponseWriter, r *http.Request) {
+	var request NewGame
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	if err := request.check(); err != nil {
+		storeError(w, err)
+		return
+	}
+	game := GameRecord{
+		Date:     request.Date,
+		Location: request.Location,
+		Notes:    request.Notes,
+	}
+	for _, playerID := range request.Participants {
+		game.Participants = append(game.Participants, Participant{PlayerID: playerID})
+	}
+	if err := p.games.CreateGame(r.Context(), &game); err != nil {
+		storeError(w, err)
+		return
+	}
+	created, err := p.games.GetGame(r.Context(), game.ID)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/games/"+strconv.Itoa(game.ID))
+	writeJSON(w, http.StatusCreated, created)
+}
+
+func (p *PlayerServer) getGame(w http.ResponseWriter, r *http.Request, id int) {
+	game, err := p.games.GetGame(r.Context(), id)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, game)
+}
+
+func (p *PlayerServer) recordResult(w http.ResponseWriter, r *http.Request, id int) {
+	var result GameResult
+	if !decodeJSON(w, r, &result) {
+		return
+	}
+	if err := result.check(); err != nil {
+		storeError(w, err)
+		return
+	}
+	if err := p.games.RecordResult(r.Context(), id, result.FinishingOrder); err != nil {
+		storeError(w, err)
+		return
+	}
+	p.getGame(w, r, id)
+}
+
+func (p *PlayerServer) recordMoney(w http.ResponseWriter, r *http.Request, id int) {
+	if p.money == nil {
+		notSupported(w, "money tracking needs the sqlite or postgres store")
+		return
+	}
+	var stakes []Stake
+	if !decodeJSON(w, r, &stakes) {
+		return
+	}
+	if err := p.money.RecordMoney(r.Context(), id, stakes); err != nil {
+		storeError(w, err)
+		return
+	}
+	p.getGame(w, r, id)
+}
+
+func (p *PlayerServer) getSettlement(w http.ResponseWriter, r *http.Request, id int) {
+	game, err := p.games.GetGame(r.Context(), id)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	settlement, err := SettleGame(game)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, settlement)
+}
+
+// GET
+func (p *PlayerServer) earningsHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	if p.money == nil {
+		notSupported(w, "money tracking needs the sqlite or postgres store")
+		return
+	}
+	earnings, err := p.money.GetEarnings(r.Context())
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, earnings)
+}
+
+// parseGameFilter reads ?player=, ?since=, ?until= and ?limit= from the
+// query string. Dates are RFC 3339 or plain 2006-01-02 days.
+func parseGameFilter(r *http.Request) (GameFilter, error) {
+	var filter GameFilter
+	query := r.URL.Query()
+
+	for name, target := range map[string]*int{"player": &filter.PlayerID, "limit": &filter.Limit} {
+		if value := query.Get(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return GameFilter{}, fmt.Errorf("%s must be a non-negative number, got %q", name, value)
+			}
+			*target = n
+		}
+	}
+	for name, target := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if value := query.Get(name); value != "" {
+			t, err := parseDate(value)
+			if err != nil {
+				return GameFilter{}, fmt.Errorf("%s must be a date, %v", name, err)
+			}
+			*target = t
+		}
+	}
+	return filter, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+// writeJSON encodes body before writing the header, so an encoding error can
+// still be answered with a 500.
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		internalError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", jsonContentType)
+	w.WriteHeader(status)
+	w.Write(append(data, '\n'))
+}
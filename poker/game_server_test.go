@@ -0,0 +1,164 @@
+package poker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGameHistoryEndpoints(t *testing.T) {
+	store := createTestDatabase(t)
+	assertNoError(t, store.AddPlayer(&Player{ID: 1, Name: "Cleo"}))
+	assertNoError(t, store.AddPlayer(&Player{ID: 2, Name: "Chris"}))
+	server := NewPlayerServer(store)
+
+	created := httptest.NewRecorder()
+	server.ServeHTTP(created, newGameRequest(http.MethodPost, "/games/",
+		`{"date": "2024-03-01T20:00:00Z", "location": "Sofia", "participants": [1, 2]}`))
+	assertStatus(t, created.Code, http.StatusCreated)
+	assertLocation(t, created, "/games/1")
+
+	t.Run("records a result", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest(http.MethodPost, "/games/1/result", `{"finishing_order": [2, 1]}`))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		game := getGameFromResponse(t, response)
+		if game.WinnerID != 2 {
+			t.Errorf("got winner %d want 2", game.WinnerID)
+		}
+		assertScoreEquals(t, store.GetPlayerScore(2), 1)
+	})
+
+	t.Run("lists games of a player", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest(http.MethodGet, "/games/?player=1&since=2024-01-01", ""))
+		assertStatus(t, response.Code, http.StatusOK)
+		assertContentType(t, response, jsonContentType)
+
+		var games []GameRecord
+		if err := json.NewDecoder(response.Body).Decode(&games); err != nil {
+			t.Fatalf("unable to parse games from response %q, %v", response.Body, err)
+		}
+		if len(games) != 1 || games[0].Location != "Sofia" {
+			t.Errorf("expected the game in Sofia, got %+v", games)
+		}
+	})
+
+	t.Run("records money and shows earnings", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest(http.MethodPut, "/games/1/money",
+			`[{"player_id": 1, "buy_in": 1000}, {"player_id": 2, "buy_in": 1000, "payout": 2000}]`))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest(http.MethodGet, "/earnings/", ""))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		var earnings Earnings
+		if err := json.NewDecoder(response.Body).Decode(&earnings); err != nil {
+			t.Fatalf("unable to parse earnings from response %q, %v", response.Body, err)
+		}
+		if len(earnings) != 2 || earnings[0].Name != "Chris" || earnings[0].NetProfit != 1000 {
+			t.Errorf("expected Chris on top with 1000 profit, got %+v", earnings)
+		}
+	})
+
+	t.Run("settles the game", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest(http.MethodGet, "/games/1/settlement", ""))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		var settlement Settlement
+		if err := json.NewDecoder(response.Body).Decode(&settlement); err != nil {
+			t.Fatalf("unable to parse settlement from response %q, %v", response.Body, err)
+		}
+		want := []Transfer{{From: 1, FromName: "Cleo", To: 2, ToName: "Chris", Amount: 1000}}
+		if !reflect.DeepEqual(settlement.Transfers, want) {
+			t.Errorf("got transfers %+v want %+v", settlement.Transfers, want)
+		}
+	})
+
+	tests := []struct {
+		name           string
+		request        *http.Request
+		expectedStatus int
+	}{
+		{"missing game", newGameRequest(http.MethodGet, "/games/9", ""), http.StatusNotFound},
+		{"second result", newGameRequest(http.MethodPost, "/games/1/result", `{"finishing_order": [1]}`), http.StatusConflict},
+		{"too few participants", newGameRequest(http.MethodPost, "/games/", `{"participants": [1]}`), http.StatusBadRequest},
+		{"bad filter", newGameRequest(http.MethodGet, "/games/?limit=many", ""), http.StatusBadRequest},
+		{"wrong method", newGameRequest(http.MethodDelete, "/games/1", ""), http.StatusMethodNotAllowed},
+		{"negative buy-in", newGameRequest(http.MethodPut, "/games/1/money", `[{"player_id": 1, "buy_in": -5}]`), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, tt.request)
+			assertStatus(t, response.Code, tt.expectedStatus)
+		})
+	}
+
+	t.Run("rejects bad games", func(t *testing.T) {
+		store := createTestDatabase(t)
+		for id, name := range []string{"Cleo", "Chris", "Ruth"} {
+			assertNoError(t, store.AddPlayer(&Player{ID: id + 1, Name: name}))
+		}
+		assertNoError(t, store.DeletePlayer(3))
+		server := NewPlayerServer(store)
+		server.ServeHTTP(httptest.NewRecorder(), newGameRequest(http.MethodPost, "/games/", `{"participants": [1, 2]}`))
+
+		tests := []struct {
+			name   string
+			path   string
+			body   string
+			fields int
+		}{
+			{"no participants", "/games/", `{"participants": []}`, 1},
+			{"a participant twice", "/games/", `{"participants": [1, 2, 1]}`, 1},
+			{"ids that are not player ids", "/games/", `{"participants": [0, -1]}`, 2},
+			{"an unknown participant", "/games/", `{"participants": [1, 9]}`, 0},
+			{"a deleted participant", "/games/", `{"participants": [1, 3]}`, 0},
+			{"an empty finishing order", "/games/1/result", `{"finishing_order": []}`, 1},
+			{"a winner who did not play", "/games/1/result", `{"finishing_order": [3, 1]}`, 0},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				response := httptest.NewRecorder()
+				server.ServeHTTP(response, newGameRequest(http.MethodPost, tt.path, tt.body))
+
+				body := response.Body.String()
+				assertProblem(t, response, http.StatusBadRequest, CodeInvalidGame)
+				var problem Problem
+				assertNoError(t, json.Unmarshal([]byte(body), &problem))
+				if len(problem.Errors) != tt.fields {
+					t.Errorf("got field errors %+v, want %d", problem.Errors, tt.fields)
+				}
+			})
+		}
+	})
+
+	t.Run("stores without game history answer 501", func(t *testing.T) {
+		server := NewPlayerServer(&StubPlayerStore{})
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest(http.MethodGet, "/games/", ""))
+		assertStatus(t, response.Code, http.StatusNotImplemented)
+	})
+}
+
+func newGameRequest(method, path, body string) *http.Request {
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	return req
+}
+
+func getGameFromResponse(t testing.TB, response *httptest.ResponseRecorder) GameRecord {
+	t.Helper()
+	var game GameRecord
+	if err := json.NewDecoder(response.Body).Decode(&game); err != nil {
+		t.Fatalf("unable to parse game from response %q, %v", response.Body, err)
+	}
+	return game
+}
@@ -0,0 +1,242 @@
+package poker
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+)
+
+// GameRecord is a game that was played, or is being played, and the players
+// who sat at the table. A game without a winner has not finished yet.
+type GameRecord struct {
+	ID           int           `json:"id"`
+	Date         time.Time     `json:"date"`
+	Location     string        `json:"location"`
+	Notes        string        `json:"notes"`
+	WinnerID     int           `json:"winner_id,omitempty"`
+	Participants []Participant `json:"participants"`
+}
+
+// Participant is a player in a game. Position is where they finished, 1 for
+// the winner, and 0 until the result is recorded or if it was not given. The
+// money they played with is recorded as a Stake, see MoneyStore.
+type Participant struct {
+	PlayerID int    `json:"player_id"`
+	Name     string `json:"name"`
+	Position int    `json:"position,omitempty"`
+	BuyIn    int    `json:"buy_in"`
+	Rebuys   int    `json:"rebuys"`
+	Payout   int    `json:"payout"`
+}
+
+// place orders participants by where they finished. Players without a
+// position finish level, behind the last placed one.
+func (p Participant) place() int {
+	if p.Position == 0 {
+		return math.MaxInt
+	}
+	return p.Position
+}
+
+func (p Participant) stake() Stake {
+	return Stake{PlayerID: p.PlayerID, BuyIn: p.BuyIn, Rebuys: p.Rebuys, Payout: p.Payout}
+}
+
+// GameFilter narrows ListGames down. Zero values match everything.
+type GameFilter struct {
+	PlayerID int
+	Since    time.Time
+	Until    time.Time
+	Limit    int
+}
+
+// GameStore keeps the history of played games. Every store implements it
+// next to PlayerStoreV2, so a recorded result shows up in the league as a
+// win of the winner.
+//
+// Errors wrap ErrGameNotFound, ErrGameFinished and ErrInvalidGame where they
+// apply.
+type GameStore interface {
+	// CreateGame stores a new game with its participants and their
+	// stakes, filling in game.ID. A zero Date means now.
+	CreateGame(ctx context.Context, game *GameRecord) error
+	// RecordResult finishes a game. finishingOrder lists player ids from
+	// first to last place; it must name the winner and may leave off the
+	// players who finished further down.
+	RecordResult(ctx context.Context, gameID int, finishingOrder []int) error
+	GetGame(ctx context.Context, id int) (GameRecord, error)
+	// ListGames returns games newest first.
+	ListGames(ctx context.Context, filter GameFilter) ([]GameRecord, error)
+}
+
+// FinishingOrder returns the ids of the participants that have a position,
+// first place first.
+func (g GameRecord) FinishingOrder() []int {
+	placed := make([]Participant, 0, len(g.Participants))
+	for _, participant := range g.Participants {
+		if participant.Position > 0 {
+			placed = append(placed, participant)
+		}
+	}
+	sort.Slice(placed, func(i, j int) bool {
+		return placed[i].Position < placed[j].Position
+	})
+
+	order := make([]int, len(placed))
+	for i, participant := range placed {
+		order[i] = participant.PlayerID
+	}
+	return order
+}
+
+// created returns g as a store that keeps games apart from its players
+// stores it under id: in UTC, dated now if it has no date, and without a
+// result or names, which are only filled in when it is read.
+func (g GameRecord) created(id int) GameRecord {
+	g.ID = id
+	g.WinnerID = 0
+	g.Date = g.Date.UTC()
+	if g.Date.IsZero() {
+		g.Date = time.Now().UTC()
+	}
+	g.Participants = slices.Clone(g.Participants)
+	for i := range g.Participants {
+		g.Participants[i].Name = ""
+		g.Participants[i].Position = 0
+	}
+	return g
+}
+
+// checkPlayers makes sure every participant of g is in league and not
+// deleted.
+func (g GameRecord) checkPlayers(league League) error {
+	for _, participant := range g.Participants {
+		if league.findActive(participant.PlayerID) == nil {
+			return fmt.Errorf("%w: no player with id %d", ErrInvalidGame, participant.PlayerID)
+		}
+	}
+	return nil
+}
+
+// finished returns a copy of g won by the first of finishingOrder, with the
+// players in it placed in its order. Check it with checkResult first.
+func (g GameRecord) finished(finishingOrder []int) GameRecord {
+	g.Participants = slices.Clone(g.Participants)
+	g.WinnerID = finishingOrder[0]
+	for i, playerID := range finishingOrder {
+		g.participant(playerID).Position = i + 1
+	}
+	return g
+}
+
+// named returns a copy of g with the names of its participants looked up in
+// league, for the stores that keep games apart from their players. Purged
+// players are left without a name.
+func (g GameRecord) named(league League) GameRecord {
+	g.Participants = slices.Clone(g.Participants)
+	for i, participant := range g.Participants {
+		g.Participants[i].Name = ""
+		if player := league.Find(participant.PlayerID); player != nil {
+			g.Participants[i].Name = player.Name
+		}
+	}
+	return g
+}
+
+// filterGames returns the games matching filter, newest first and named from
+// league, for the stores that keep their games in memory.
+func filterGames(games []GameRecord, league League, filter GameFilter) []GameRecord {
+	found := []GameRecord{}
+	for _, game := range games {
+		if filter.PlayerID != 0 && game.participant(filter.PlayerID) == nil {
+			continue
+		}
+		if !filter.Since.IsZero() && game.Date.Before(filter.Since) {
+			continue
+		}
+		if !filter.Until.IsZero() && !game.Date.Before(filter.Until) {
+			continue
+		}
+		found = append(found, game.named(league))
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		if !found[i].Date.Equal(found[j].Date) {
+			return found[i].Date.After(found[j].Date)
+		}
+		return found[i].ID > found[j].ID
+	})
+	if filter.Limit > 0 && len(found) > filter.Limit {
+		found = found[:filter.Limit]
+	}
+	return found
+}
+
+func (g GameRecord) participant(playerID int) *Participant {
+	for i, participant := range g.Participants {
+		if participant.PlayerID == playerID {
+			return &g.Participants[i]
+		}
+	}
+	return nil
+}
+
+// checkNew makes sure a game can be created: it needs at least two players
+// and none of them twice.
+func (g GameRecord) checkNew() error {
+	if len(g.Participants) < 2 {
+		return fmt.Errorf("%w: a game needs at least two participants, got %d", ErrInvalidGame, len(g.Participants))
+	}
+	seen := make(map[int]bool, len(g.Participants))
+	for _, participant := range g.Participants {
+		if seen[participant.PlayerID] {
+			return fmt.Errorf("%w: player %d is listed twice", ErrInvalidGame, participant.PlayerID)
+		}
+		seen[participant.PlayerID] = true
+		if err := participant.stake().check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkResult makes sure finishingOrder can finish g.
+func (g GameRecord) checkResult(finishingOrder []int) error {
+	if g.WinnerID != 0 {
+		return fmt.Errorf("%w: game %d was won by player %d", ErrGameFinished, g.ID, g.WinnerID)
+	}
+	if len(finishingOrder) == 0 {
+		return fmt.Errorf("%w: the finishing order must name at least the winner", ErrInvalidGame)
+	}
+	seen := make(map[int]bool, len(finishingOrder))
+	for _, playerID := range finishingOrder {
+		if g.participant(playerID) == nil {
+			return fmt.Errorf("%w: player %d did not play in game %d", ErrInvalidGame, playerID, g.ID)
+		}
+		if seen[playerID] {
+			return fmt.Errorf("%w: player %d is listed twice", ErrInvalidGame, playerID)
+		}
+		seen[playerID] = true
+	}
+	return nil
+}
+
+// checkStakes makes sure stakes only name players of g, each once.
+func (g GameRecord) checkStakes(stakes []Stake) error {
+	seen := make(map[int]bool, len(stakes))
+	for _, stake := range stakes {
+		if g.participant(stake.PlayerID) == nil {
+			return fmt.Errorf("%w: player %d did not play in game %d", ErrInvalidGame, stake.PlayerID, g.ID)
+		}
+		if seen[stake.PlayerID] {
+			return fmt.Errorf("%w: player %d is listed twice", ErrInvalidGame, stake.PlayerID)
+		}
+		seen[stake.PlayerID] = true
+		if err := stake.check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package poker
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// gameTestStore is a store the game history tests run against.
+type gameTestStore interface {
+	PlayerStore
+	GameStore
+	WinReverter
+}
+
+// gameStoreFactories make every kind of store that keeps games.
+var gameStoreFactories = map[string]func(t *testing.T) gameTestStore{
+	"sql": func(t *testing.T) gameTestStore {
+		return createTestDatabase(t)
+	},
+	"file": func(t *testing.T) gameTestStore {
+		store, closeStore, err := FileSystemPlayerStoreFromFile(filepath.Join(t.TempDir(), "game.db.json"))
+		assertNoError(t, err)
+		t.Cleanup(closeStore)
+		return store
+	},
+	"events": func(t *testing.T) gameTestStore {
+		return createEventStore(t, filepath.Join(t.TempDir(), "events.jsonl"))
+	},
+}
+
+func TestGameStore(t *testing.T) {
+	ctx := context.Background()
+
+	for name, factory := range gameStoreFactories {
+		newStoreWithPlayers := func(t *testing.T) gameTestStore {
+			store := factory(t)
+			assertNoError(t, store.AddPlayer(&Player{ID: 1, Name: "Cleo"}))
+			assertNoError(t, store.AddPlayer(&Player{ID: 2, Name: "Chris"}))
+			assertNoError(t, store.AddPlayer(&Player{ID: 3, Name: "Lloyd"}))
+			assertNoError(t, store.AddPlayer(&Player{ID: 4, Name: "Ruth"}))
+			assertNoError(t, store.DeletePlayer(4))
+			return store
+		}
+
+		t.Run(name+"/creates a game with its participants", func(t *testing.T) {
+			store := newStoreWithPlayers(t)
+			date := time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC)
+
+			game := &GameRecord{Date: date, Location: "Sofia", Participants: []Participant{{PlayerID: 1}, {PlayerID: 2}}}
+			assertNoError(t, store.CreateGame(ctx, game))
+
+			got, err := store.GetGame(ctx, game.ID)
+			assertNoError(t, err)
+			want := GameRecord{
+				ID:           game.ID,
+				Date:         date,
+				Location:     "Sofia",
+				Participants: []Participant{{PlayerID: 1, Name: "Cleo"}, {PlayerID: 2, Name: "Chris"}},
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v want %+v", got, want)
+			}
+		})
+
+		t.Run(name+"/rejects games that cannot be played", func(t *testing.T) {
+			store := newStoreWithPlayers(t)
+
+			games := map[string]*GameRecord{
+				"one participant":     {Participants: []Participant{{PlayerID: 1}}},
+				"same player twice":   {Participants: []Participant{{PlayerID: 1}, {PlayerID: 1}}},
+				"unknown participant": {Participants: []Participant{{PlayerID: 1}, {PlayerID: 9}}},
+			}
+			for name, game := range games {
+				if err := store.CreateGame(ctx, game); !errors.Is(err, ErrInvalidGame) {
+					t.Errorf("%s: got error %v want %v", name, err, ErrInvalidGame)
+				}
+			}
+		})
+
+		t.Run(name+"/recording a result counts as a win in the league", func(t *testing.T) {
+			store := newStoreWithPlayers(t)
+			game := &GameRecord{Participants: []Participant{{PlayerID: 1}, {PlayerID: 2}, {PlayerID: 3}}}
+			assertNoError(t, store.CreateGame(ctx, game))
+
+			assertNoError(t, store.RecordResult(ctx, game.ID, []int{2, 3}))
+
+			got, err := store.GetGame(ctx, game.ID)
+			assertNoError(t, err)
+			if got.WinnerID != 2 {
+				t.Errorf("got winner %d want 2", got.WinnerID)
+			}
+			if order := got.FinishingOrder(); !reflect.DeepEqual(order, []int{2, 3}) {
+				t.Errorf("got finishing order %v want [2 3]", order)
+			}
+			assertScoreEquals(t, store.GetPlayerScore(2), 1)
+		})
+
+		t.Run(name+"/rejects a second result and players who did not play", func(t *testing.T) {
+			store := newStoreWithPlayers(t)
+			game := &GameRecord{Participants: []Participant{{PlayerID: 1}, {PlayerID: 2}}}
+			assertNoError(t, store.CreateGame(ctx, game))
+
+			if err := store.RecordResult(ctx, game.ID, []int{3}); !errors.Is(err, ErrInvalidGame) {
+				t.Errorf("got error %v want %v", err, ErrInvalidGame)
+			}
+			assertNoError(t, store.RecordResult(ctx, game.ID, []int{1}))
+			if err := store.RecordResult(ctx, game.ID, []int{2}); !errors.Is(err, ErrGameFinished) {
+				t.Errorf("got error %v want %v", err, ErrGameFinished)
+			}
+			if err := store.RecordResult(ctx, 99, []int{1}); !errors.Is(err, ErrGameNotFound) {
+				t.Errorf("got error %v want %v", err, ErrGameNotFound)
+			}
+		})
+
+		t.Run(name+"/lists games newest first and filters them", func(t *testing.T) {
+			store := newStoreWithPlayers(t)
+			march := &GameRecord{Date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Participants: []Participant{{PlayerID: 1}, {PlayerID: 2}}}
+			april := &GameRecord{Date: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), Participants: []Participant{{PlayerID: 2}, {PlayerID: 3}}}
+			assertNoError(t, store.CreateGame(ctx, march))
+			assertNoError(t, store.CreateGame(ctx, april))
+
+			assertGameIDs(t, store, GameFilter{}, april.ID, march.ID)
+			assertGameIDs(t, store, GameFilter{PlayerID: 1}, march.ID)
+			assertGameIDs(t, store, GameFilter{Since: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)}, april.ID)
+			assertGameIDs(t, store, GameFilter{Limit: 1}, april.ID)
+		})
+
+		t.Run(name+"/reverting the win of a game takes its result off", func(t *testing.T) {
+			store := newStoreWithPlayers(t)
+			game := &GameRecord{Participants: []Participant{{PlayerID: 1}, {PlayerID: 2}}}
+			assertNoError(t, store.CreateGame(ctx, game))
+			assertNoError(t, store.RecordResult(ctx, game.ID, []int{1, 2}))
+
+			wins, err := store.Wins(ctx, 1)
+			assertNoError(t, err)
+			if len(wins) != 1 || wins[0].GameID != game.ID {
+				t.Fatalf("expected one win of game %d, got %+v", game.ID, wins)
+			}
+			_, err = store.UndoLastWin(ctx, 1, Reversion{By: "Chris", Reason: "wrong table"})
+			assertNoError(t, err)
+
+			got, err := store.GetGame(ctx, game.ID)
+			assertNoError(t, err)
+			if got.WinnerID != 0 {
+				t.Errorf("got winner %d, want none", got.WinnerID)
+			}
+			assertScoreEquals(t, store.GetPlayerScore(1), 0)
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 )
 
 type League []Player
@@ -17,6 +19,67 @@ func (l League) Find(id int) *Player {
 	return nil
 }
 
+// FindByName looks a player up by name, ignoring case.
+func (l League) FindByName(name string) *Player {
+	for i, p := range l {
+		if strings.EqualFold(p.Name, name) {
+			return &l[i]
+		}
+	}
+	return nil
+}
+
+// nextID is the id a store gives a new player when the caller did not pick
+// one.
+func (l League) nextID() int {
+	next := 1
+	for _, p := range l {
+		if p.ID >= next {
+			next = p.ID + 1
+		}
+	}
+	return next
+}
+
+// checkNew reports why player cannot join the league: a negative id, or an
+// id or name (compared case-insensitively) that is already taken, also by a
+// deleted player.
+func (l League) checkNew(player Player) error {
+	if player.ID < 0 {
+		return fmt.Errorf("%w: id %d must not be negative", ErrInvalidPlayer, player.ID)
+	}
+	for _, p := range l {
+		deleted := ""
+		if p.Deleted() {
+			deleted = ", deleted but not purged yet"
+		}
+		if player.ID != 0 && p.ID == player.ID {
+			return fmt.Errorf("%w: player with id %d already exists%s", ErrDuplicatePlayer, player.ID, deleted)
+		}
+		if strings.EqualFold(p.Name, player.Name) {
+			return fmt.Errorf("%w: player named %q already exists%s", ErrDuplicatePlayer, p.Name, deleted)
+		}
+	}
+	return nil
+}
+
+// sortByWins orders the league the way GetLeague returns it: most wins
+// first, ties broken by id.
+func (l League) sortByWins() {
+	sort.Slice(l, func(i, j int) bool {
+		if l[i].Wins != l[j].Wins {
+			return l[i].Wins > l[j].Wins
+		}
+		return l[i].ID < l[j].ID
+	})
+}
+
+func (l League) copy() League {
+	c := make(League, len(l))
+	copy(c, l)
+	return c
+}
+
 func NewLeague(rdr io.Reader) (League, error) {
 	var league []Player
 	err := json.NewDecoder(rdr).Decode(&league)
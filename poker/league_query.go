@@ -0,0 +1,273 @@
+package poker
+
+import (
+	"context"
+	"encoding/base64"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Orders a league can be sorted in. SortByRank, the default, is the order of
+// the scoring rules.
+const (
+	SortByRank = "rank"
+	SortByWins = "wins"
+	SortByName = "name"
+	SortByID   = "id"
+)
+
+// MaxLeagueLimit is the most players a page of the league can have.
+const MaxLeagueLimit = 100
+
+// LeagueQuery picks a page of the league. A zero Limit asks for every player
+// from Offset on, a page has at most MaxLeagueLimit players otherwise. Order is asc or desc and defaults to the natural order of
+// Sort: best first by rank, most wins first, names from A and ids from 1.
+// Only players with at least MinWins wins and, when Name is set, a name that
+// contains it, ignoring case, are listed.
+type LeagueQuery struct {
+	Limit   int    `json:"limit" validate:"min=0,max=100"`
+	Offset  int    `json:"offset" validate:"min=0"`
+	Sort    string `json:"sort" validate:"oneof=rank wins name id"`
+	Order   string `json:"order" validate:"oneof=asc desc"`
+	MinWins int    `json:"min_wins" validate:"min=0"`
+	Name    string `json:"q" validate:"normalize,max=64"`
+}
+
+// LeagueQuerier is implemented by stores that can filter, sort and page
+// their league themselves, in SQL for example. QueryLeague returns the
+// players of the page and how many players match the query in all. It is
+// only asked for orders other than SortByRank, as ranking needs the scores
+// of the whole league.
+type LeagueQuerier interface {
+	QueryLeague(ctx context.Context, query LeagueQuery) (League, int, error)
+}
+
+// LeaguePage is a page of the league. Offset is where its first standing is
+// in the whole list of Total standings that match Query, the query the page
+// was picked with, its defaults filled in.
+type LeaguePage struct {
+	Standings Standings
+	Total     int
+	Offset    int
+	Query     LeagueQuery
+}
+
+// Next returns the query for the page after p, or false when p is the last.
+func (p LeaguePage) Next() (LeagueQuery, bool) {
+	end := p.Offset + len(p.Standings)
+	if p.Query.Limit == 0 || end >= p.Total {
+		return LeagueQuery{}, false
+	}
+	next := p.Query
+	next.Offset = end
+	return next, true
+}
+
+// EncodeCursor returns the opaque cursor that pages continue from offset
+// with.
+func EncodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+// DecodeCursor returns the offset of a cursor made by EncodeCursor.
+func DecodeCursor(cursor string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, invalidQuery("cursor", "is not a cursor of this league")
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(data), "offset:"))
+	if err != nil || !strings.HasPrefix(string(data), "offset:") {
+		return 0, invalidQuery("cursor", "is not a cursor of this league")
+	}
+	return offset, nil
+}
+
+// ParseLeagueQuery reads a LeagueQuery from the parameters limit, offset or
+// cursor, sort, order, min_wins and q. It only checks that numbers are
+// numbers; QueryLeague validates the rest.
+func ParseLeagueQuery(values url.Values) (LeagueQuery, error) {
+	query := LeagueQuery{
+		Sort:  values.Get("sort"),
+		Order: values.Get("order"),
+		Name:  values.Get("q"),
+	}
+	var fields []FieldError
+	for _, number := range []struct {
+		name  string
+		value *int
+	}{
+		{"limit", &query.Limit},
+		{"offset", &query.Offset},
+		{"min_wins", &query.MinWins},
+	} {
+		if text := values.Get(number.name); text != "" {
+			n, err := strconv.Atoi(text)
+			if err != nil {
+				fields = append(fields, FieldError{Field: number.name, Message: "must be a number"})
+			}
+			*number.value = n
+		}
+	}
+	if cursor := values.Get("cursor"); cursor != "" {
+		offset, err := DecodeCursor(cursor)
+		switch {
+		case err != nil:
+			fields = append(fields, FieldError{Field: "cursor", Message: "is not a cursor of this league"})
+		case values.Get("offset") != "":
+			fields = append(fields, FieldError{Field: "cursor", Message: "cannot be given with an offset"})
+		}
+		query.Offset = offset
+	}
+	if len(fields) > 0 {
+		return LeagueQuery{}, &ValidationError{Err: ErrInvalidQuery, Fields: fields}
+	}
+	return query, nil
+}
+
+// Values returns the parameters ParseLeagueQuery reads query back from, with
+// the offset as a cursor.
+func (q LeagueQuery) Values() url.Values {
+	values := url.Values{}
+	if q.Limit > 0 {
+		values.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if q.Offset > 0 {
+		values.Set("cursor", EncodeCursor(q.Offset))
+	}
+	values.Set("sort", q.Sort)
+	values.Set("order", q.Order)
+	if q.MinWins > 0 {
+		values.Set("min_wins", strconv.Itoa(q.MinWins))
+	}
+	if q.Name != "" {
+		values.Set("q", q.Name)
+	}
+	return values
+}
+
+// withDefaults fills in the sort and order left out of q.
+func (q LeagueQuery) withDefaults() LeagueQuery {
+	if q.Sort == "" {
+		q.Sort = SortByRank
+	}
+	if q.Order == "" {
+		q.Order = "asc"
+		if q.Sort == SortByWins {
+			q.Order = "desc"
+		}
+	}
+	return q
+}
+
+func (q LeagueQuery) matches(name string, wins int) bool {
+	return wins >= q.MinWins && strings.Contains(strings.ToLower(name), strings.ToLower(q.Name))
+}
+
+// page cuts the page q asks for out of n matches and returns its bounds.
+func (q LeagueQuery) page(n int) (int, int) {
+	start := min(q.Offset, n)
+	if q.Limit == 0 {
+		return start, n
+	}
+	return start, min(start+q.Limit, n)
+}
+
+// QueryLeague returns the page of the league of store that query asks for,
+// scored with rules. Sorted by rank, the whole league is scored and then
+// paged. Sorted any other way, the page is picked first, by the store when
+// it is a LeagueQuerier, and only its players are scored; their Rank is left
+// 0, as ranking takes the whole league.
+func QueryLeague(ctx context.Context, store PlayerStoreV2, rules RuleSet, query LeagueQuery) (LeaguePage, error) {
+	query = query.withDefaults()
+	if err := Validate(&query, ErrInvalidQuery); err != nil {
+		return LeaguePage{}, err
+	}
+
+	if query.Sort == SortByRank {
+		standings, err := ScoreLeague(ctx, store, rules)
+		if err != nil {
+			return LeaguePage{}, err
+		}
+		matching := Standings{}
+		for _, standing := range standings {
+			if query.matches(standing.Name, standing.Wins) {
+				matching = append(matching, standing)
+			}
+		}
+		if query.Order == "desc" {
+			for i, j := 0, len(matching)-1; i < j; i, j = i+1, j-1 {
+				matching[i], matching[j] = matching[j], matching[i]
+			}
+		}
+		start, end := query.page(len(matching))
+		return LeaguePage{Standings: matching[start:end], Total: len(matching), Offset: start, Query: query}, nil
+	}
+
+	var players League
+	var total int
+	if querier, ok := StoreFeature[LeagueQuerier](store); ok {
+		var err error
+		if players, total, err = querier.QueryLeague(ctx, query); err != nil {
+			return LeaguePage{}, err
+		}
+	} else {
+		league, err := store.GetLeague(ctx)
+		if err != nil {
+			return LeaguePage{}, err
+		}
+		players, total = league.query(query)
+	}
+
+	games, err := leagueGames(ctx, store, players)
+	if err != nil {
+		return LeaguePage{}, err
+	}
+	scored := make(map[int]Standing, len(players))
+	for _, standing := range rules.Score(players, games) {
+		standing.Rank = 0
+		scored[standing.ID] = standing
+	}
+	standings := make(Standings, len(players))
+	for i, player := range players {
+		standings[i] = scored[player.ID]
+	}
+	return LeaguePage{Standings: standings, Total: total, Offset: min(query.Offset, total), Query: query}, nil
+}
+
+// query filters, sorts and pages the league the way a LeagueQuerier does.
+// Ties are broken by id.
+func (l League) query(query LeagueQuery) (League, int) {
+	matching := League{}
+	for _, player := range l {
+		if query.matches(player.Name, player.Wins) {
+			matching = append(matching, player)
+		}
+	}
+	compare := func(a, b Player) int {
+		switch query.Sort {
+		case SortByWins:
+			return a.Wins - b.Wins
+		case SortByName:
+			return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		}
+		return a.ID - b.ID
+	}
+	sort.SliceStable(matching, func(i, j int) bool {
+		c := compare(matching[i], matching[j])
+		if query.Order == "desc" {
+			c = -c
+		}
+		if c != 0 {
+			return c < 0
+		}
+		return matching[i].ID < matching[j].ID
+	})
+	start, end := query.page(len(matching))
+	return matching[start:end], len(matching)
+}
+
+func invalidQuery(field, message string) error {
+	return &ValidationError{Err: ErrInvalidQuery, Fields: []FieldError{{Field: field, Message: message}}}
+}
@@ -0,0 +1,186 @@
+package poker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseLeagueQuery(t *testing.T) {
+	t.Run("reads every parameter", func(t *testing.T) {
+		values, _ := url.ParseQuery("limit=2&cursor=" + EncodeCursor(4) + "&sort=name&order=desc&min_wins=1&q=cl")
+		query, err := ParseLeagueQuery(values)
+		assertNoError(t, err)
+		want := LeagueQuery{Limit: 2, Offset: 4, Sort: "name", Order: "desc", MinWins: 1, Name: "cl"}
+		if query != want {
+			t.Errorf("got %+v want %+v", query, want)
+		}
+		if again, _ := ParseLeagueQuery(query.Values()); again != want {
+			t.Errorf("expected the values to read back the same query, got %+v", again)
+		}
+	})
+
+	t.Run("rejects numbers that are not numbers and cursors that are not cursors", func(t *testing.T) {
+		for _, raw := range []string{"limit=ten", "min_wins=lots", "cursor=nope", "cursor=" + EncodeCursor(1) + "&offset=1"} {
+			values, _ := url.ParseQuery(raw)
+			_, err := ParseLeagueQuery(values)
+			assertErrorIs(t, err, ErrInvalidQuery)
+		}
+	})
+}
+
+func TestQueryLeague(t *testing.T) {
+	events, err := NewEventSourcedPlayerStore(filepath.Join(t.TempDir(), "events.jsonl"))
+	assertNoError(t, err)
+	stores := map[string]PlayerStore{
+		"in memory": events,
+		"in SQL":    createTestDatabase(t),
+	}
+	wins := map[string]int{"Cleo": 3, "Chris": 1, "Lloyd": 2, "Alice": 0, "Chloe": 2}
+	for name, store := range stores {
+		for i, player := range []string{"Cleo", "Chris", "Lloyd", "Alice", "Chloe"} {
+			assertNoError(t, store.AddPlayer(&Player{ID: i + 1, Name: player}))
+			for n := 0; n < wins[player]; n++ {
+				assertNoError(t, store.RecordWin(i+1))
+			}
+		}
+		if _, ok := StoreFeature[LeagueQuerier](AdaptPlayerStore(store)); ok != (name == "in SQL") {
+			t.Fatalf("%s: expected only the SQL store to query the league itself", name)
+		}
+	}
+
+	for _, tt := range []struct {
+		name  string
+		query LeagueQuery
+		want  []string
+		total int
+	}{
+		{"ranks the whole league by default", LeagueQuery{}, []string{"Cleo", "Lloyd", "Chloe", "Chris", "Alice"}, 5},
+		{"pages the ranking", LeagueQuery{Limit: 2, Offset: 1}, []string{"Lloyd", "Chloe"}, 5},
+		{"sorts by wins, ties by id", LeagueQuery{Sort: SortByWins}, []string{"Cleo", "Lloyd", "Chloe", "Chris", "Alice"}, 5},
+		{"sorts by wins ascending", LeagueQuery{Sort: SortByWins, Order: "asc", Limit: 2}, []string{"Alice", "Chris"}, 5},
+		{"sorts by name", LeagueQuery{Sort: SortByName, Limit: 3}, []string{"Alice", "Chloe", "Chris"}, 5},
+		{"sorts by id descending", LeagueQuery{Sort: SortByID, Order: "desc", Offset: 3}, []string{"Chris", "Cleo"}, 5},
+		{"filters by wins", LeagueQuery{Sort: SortByID, MinWins: 2}, []string{"Cleo", "Lloyd", "Chloe"}, 3},
+		{"filters by name", LeagueQuery{Sort: SortByName, Name: "CL"}, []string{"Cleo"}, 1},
+		{"filters the ranking", LeagueQuery{Name: "ch", Limit: 1}, []string{"Chloe"}, 2},
+		{"matches wildcards literally", LeagueQuery{Sort: SortByName, Name: "%"}, nil, 0},
+		{"answers past the end with no one", LeagueQuery{Sort: SortByID, Offset: 9}, nil, 5},
+	} {
+		for name, store := range stores {
+			t.Run(tt.name+" "+name, func(t *testing.T) {
+				page, err := QueryLeague(context.Background(), AdaptPlayerStore(store), DefaultRuleSet, tt.query)
+				assertNoError(t, err)
+				var got []string
+				for _, standing := range page.Standings {
+					got = append(got, standing.Name)
+				}
+				if !reflect.DeepEqual(got, tt.want) || page.Total != tt.total {
+					t.Errorf("got %v of %d want %v of %d", got, page.Total, tt.want, tt.total)
+				}
+			})
+		}
+	}
+
+	t.Run("scores a page sorted by anything but rank without ranking it", func(t *testing.T) {
+		page, err := QueryLeague(context.Background(), AdaptPlayerStore(stores["in SQL"]), DefaultRuleSet, LeagueQuery{Sort: SortByName, Limit: 1})
+		assertNoError(t, err)
+		if want := (Standing{ID: 4, Name: "Alice"}); !reflect.DeepEqual(page.Standings, Standings{want}) {
+			t.Errorf("got %+v want %+v", page.Standings, want)
+		}
+		page, err = QueryLeague(context.Background(), AdaptPlayerStore(stores["in SQL"]), DefaultRuleSet, LeagueQuery{Sort: SortByName, Name: "Cleo"})
+		assertNoError(t, err)
+		if got := page.Standings[0]; got.Points != 3 || got.Wins != 3 || got.Rank != 0 {
+			t.Errorf("expected Cleo's points without a rank, got %+v", got)
+		}
+	})
+
+	t.Run("folds the case of names beyond ASCII", func(t *testing.T) {
+		events, err := NewEventSourcedPlayerStore(filepath.Join(t.TempDir(), "events.jsonl"))
+		assertNoError(t, err)
+		for name, store := range map[string]PlayerStore{"in memory": events, "in SQL": createTestDatabase(t)} {
+			assertNoError(t, store.AddPlayer(&Player{ID: 1, Name: "Élodie"}))
+			assertNoError(t, store.AddPlayer(&Player{ID: 2, Name: "élan"}))
+			for _, tt := range []struct {
+				query LeagueQuery
+				want  []string
+			}{
+				{LeagueQuery{Name: "élo"}, []string{"Élodie"}},
+				{LeagueQuery{Name: "ÉLAN"}, []string{"élan"}},
+				{LeagueQuery{Sort: SortByName}, []string{"élan", "Élodie"}},
+			} {
+				page, err := QueryLeague(context.Background(), AdaptPlayerStore(store), DefaultRuleSet, tt.query)
+				assertNoError(t, err)
+				var got []string
+				for _, standing := range page.Standings {
+					got = append(got, standing.Name)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("%s: %+v got %v want %v", name, tt.query, got, tt.want)
+				}
+			}
+		}
+	})
+
+	t.Run("rejects queries it cannot run", func(t *testing.T) {
+		for _, query := range []LeagueQuery{{Limit: MaxLeagueLimit + 1}, {Sort: "points"}, {Order: "up"}, {MinWins: -1}} {
+			_, err := QueryLeague(context.Background(), AdaptPlayerStore(events), DefaultRuleSet, query)
+			assertErrorIs(t, err, ErrInvalidQuery)
+		}
+	})
+}
+
+func TestLeaguePages(t *testing.T) {
+	store := createTestDatabase(t)
+	for i, name := range []string{"Cleo", "Chris", "Lloyd"} {
+		assertNoError(t, store.AddPlayer(&Player{ID: i + 1, Name: name}))
+	}
+	server := NewPlayerServer(store)
+
+	for _, path := range []string{"/league/", "/api/v1/league"} {
+		t.Run("links "+path+" to the next page", func(t *testing.T) {
+			var names []string
+			next := path + "?limit=2&sort=name"
+			for pages := 0; next != ""; pages++ {
+				if pages == 3 {
+					t.Fatal("expected the pages to end")
+				}
+				response := httptest.NewRecorder()
+				server.ServeHTTP(response, newGameRequest(http.MethodGet, next, ""))
+				assertStatus(t, response.Code, http.StatusOK)
+				if got := response.Header().Get(totalCountHeader); got != strconv.Itoa(3) {
+					t.Errorf("got total %q want 3", got)
+				}
+				for _, player := range getLeagueFromResponse(t, response.Body) {
+					names = append(names, player.Name)
+				}
+				next = nextLink(response.Header().Values("Link"))
+			}
+			if want := []string{"Chris", "Cleo", "Lloyd"}; !reflect.DeepEqual(names, want) {
+				t.Errorf("got %v want %v", names, want)
+			}
+		})
+	}
+
+	t.Run("answers a bad query with the fields at fault", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest(http.MethodGet, "/league/?limit=500&sort=points", ""))
+		assertProblem(t, response, http.StatusBadRequest, CodeInvalidQuery)
+	})
+}
+
+// nextLink returns the target of the rel="next" link in links, or "".
+func nextLink(links []string) string {
+	for _, link := range links {
+		if target, ok := strings.CutSuffix(link, `>; rel="next"`); ok {
+			return strings.TrimPrefix(target, "<")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+package poker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// Stake is what a player put into a game and took out of it. Amounts are in
+// the smallest unit of the currency played for, cents for example.
+type Stake struct {
+	PlayerID int `json:"player_id"`
+	BuyIn    int `json:"buy_in"`
+	// Rebuys is the total paid for rebuys and add-ons.
+	Rebuys int `json:"rebuys"`
+	Payout int `json:"payout"`
+}
+
+// Earning is one player's line on the earnings leaderboard.
+type Earning struct {
+	ID        int     `json:"id"`
+	Name      string  `json:"name"`
+	Games     int     `json:"games"`
+	Invested  int     `json:"invested"`
+	TotalWon  int     `json:"total_won"`
+	NetProfit int     `json:"net_profit"`
+	ROI       float64 `json:"roi"`
+}
+
+// Earnings is the money leaderboard, ordered by net profit, most first, then
+// by id.
+type Earnings []Earning
+
+// MoneyStore tracks buy-ins, rebuys and payouts of the games in a GameStore.
+type MoneyStore interface {
+	// RecordMoney sets the stakes of the given participants of a game,
+	// replacing what was recorded for them before.
+	RecordMoney(ctx context.Context, gameID int, stakes []Stake) error
+	GetEarnings(ctx context.Context) (Earnings, error)
+}
+
+func (s Stake) check() error {
+	if s.BuyIn < 0 || s.Rebuys < 0 || s.Payout < 0 {
+		return fmt.Errorf("%w: amounts for player %d must not be negative", ErrInvalidGame, s.PlayerID)
+	}
+	return nil
+}
+
+// newEarning works out the profit and return on investment from the totals.
+// ROI is net profit over the amount invested, 0 for a player who has not put
+// any money in.
+func newEarning(id int, name string, games, invested, totalWon int) Earning {
+	earning := Earning{
+		ID:        id,
+		Name:      name,
+		Games:     games,
+		Invested:  invested,
+		TotalWon:  totalWon,
+		NetProfit: totalWon - invested,
+	}
+	if invested > 0 {
+		earning.ROI = float64(earning.NetProfit) / float64(invested)
+	}
+	return earning
+}
+
+func (e Earnings) sort() {
+	sort.SliceStable(e, func(i, j int) bool {
+		if e[i].NetProfit != e[j].NetProfit {
+			return e[i].NetProfit > e[j].NetProfit
+		}
+		return e[i].ID < e[j].ID
+	})
+}
+
+// PrintEarnings writes earnings as a table, with amounts in whole units and
+// cents.
+func PrintEarnings(out io.Writer, earnings Earnings) error {
+	table := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "Player\tGames\tInvested\tWon\tNet\tROI")
+	for _, earning := range earnings {
+		fmt.Fprintf(table, "%s\t%d\t%s\t%s\t%s\t%.1f%%\n", earning.Name, earning.Games,
+			formatAmount(earning.Invested), formatAmount(earning.TotalWon), formatAmount(earning.NetProfit), earning.ROI*100)
+	}
+	return table.Flush()
+}
+
+func formatAmount(cents int) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
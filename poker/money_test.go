@@ -0,0 +1,22 @@
+package poker
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrintEarnings(t *testing.T) {
+	earnings := Earnings{
+		newEarning(2, "Chris", 3, 6000, 9050),
+		newEarning(1, "Cleo", 2, 4000, 0),
+	}
+	buffer := &bytes.Buffer{}
+
+	assertNoError(t, PrintEarnings(buffer, earnings))
+
+	want := "" +
+		"Player  Games  Invested  Won    Net     ROI\n" +
+		"Chris   3      60.00     90.50  30.50   50.8%\n" +
+		"Cleo    2      40.00     0.00   -40.00  -100.0%\n"
+	assertResponseBody(t, buffer.String(), want)
+}
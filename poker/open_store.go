@@ -0,0 +1,50 @@
+package poker
+
+import "fmt"
+
+// StoreConfig picks the PlayerStore OpenPlayerStore opens and where it keeps
+// its data.
+type StoreConfig struct {
+	// Type is file, events, sqlite or postgres. Empty means file.
+	Type       string
+	FilePath   string
+	EventsPath string
+	SQLitePath string
+	DSN        string
+	// Backup keeps the previous league file of the file store, see
+	// WithBackup.
+	Backup bool
+}
+
+// OpenPlayerStore opens the store described by config. The returned func
+// closes it.
+func OpenPlayerStore(config StoreConfig) (PlayerStore, func(), error) {
+	switch config.Type {
+	case "", "file":
+		var options []FileSystemStoreOption
+		if config.Backup {
+			options = append(options, WithBackup())
+		}
+		return FileSystemPlayerStoreFromFile(config.FilePath, options...)
+	case "events":
+		store, err := NewEventSourcedPlayerStore(config.EventsPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, func() { store.Close() }, nil
+	case "sqlite":
+		store, err := NewSQLitePlayerStore(config.SQLitePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, func() { store.Close() }, nil
+	case "postgres":
+		store, err := NewDatabaseStore(config.DSN)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, func() { store.Close() }, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown store type %q", config.Type)
+	}
+}
@@ -0,0 +1,43 @@
+package poker
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenPlayerStore(t *testing.T) {
+	dir := t.TempDir()
+	config := StoreConfig{
+		FilePath:   filepath.Join(dir, "game.db.json"),
+		EventsPath: filepath.Join(dir, "game.events.jsonl"),
+		SQLitePath: filepath.Join(dir, "game.db"),
+	}
+
+	for _, storeType := range []string{"file", "events", "sqlite"} {
+		t.Run(storeType, func(t *testing.T) {
+			config.Type = storeType
+			store, closeStore, err := OpenPlayerStore(config)
+			assertNoError(t, err)
+			defer closeStore()
+
+			assertNoError(t, store.AddPlayer(&Player{Name: "Cleo"}))
+		})
+	}
+
+	t.Run("only database stores keep game history", func(t *testing.T) {
+		config.Type = "sqlite"
+		store, closeStore, err := OpenPlayerStore(config)
+		assertNoError(t, err)
+		defer closeStore()
+
+		if _, ok := AdaptPlayerStore(store).(GameStore); !ok {
+			t.Error("expected the sqlite store to be a GameStore")
+		}
+	})
+
+	t.Run("unknown store type", func(t *testing.T) {
+		config.Type = "mongo"
+		_, _, err := OpenPlayerStore(config)
+		assertError(t, err)
+	})
+}
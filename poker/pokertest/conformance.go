@@ -0,0 +1,602 @@
+// Package pokertest provides a test suite that any poker.PlayerStore
+// implementation can run to show it honours the PlayerStore contract.
+package pokertest
+
+import (
+	"application/poker"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// StoreFactory returns an empty store. It is called once per subtest and
+// should register any cleanup it needs with t.Cleanup.
+type StoreFactory func(t *testing.T) poker.PlayerStore
+
+// RunPlayerStoreConformance checks that the stores made by factory behave
+// the way every PlayerStore must:
+//
+//   - a new store has an empty league
+//   - AddPlayer keeps the player's id, name and wins, and rejects an empty
+//     name, a negative id, an id that is already taken or a name that is
+//     taken when compared case-insensitively
+//   - AddPlayer gives a player without an id (id 0) a new, unused id and
+//     writes it back to the player
+//   - RecordWin adds one win and fails for an unknown id
+//   - DeletePlayer removes the player and fails for an unknown id
+//   - GetPlayerScore is 0 for an unknown id, and the PlayerStoreV2 the store
+//     adapts to fails for one with poker.ErrPlayerNotFound
+//   - FindByName matches names ignoring case and fails with
+//     poker.ErrPlayerNotFound for an unknown name
+//   - GetLeague is ordered by wins, most first, then by id, and returns a
+//     copy the caller may modify
+//
+// Stores that implement poker.WinReverter are also checked to take back the
+// latest win, or a given one, exactly once and to keep reverted wins in the
+// history. Stores that implement poker.DeletedPlayerStore are checked to keep
+// deleted players out of sight, and their name taken, until they are
+// restored or purged, and never to give a purged player's id out again.
+// Stores that implement poker.PlayerRenamer are checked to rename players
+// under the same rules AddPlayer checks names with. Stores that
+// implement poker.Revisioner are checked to move their revision and the
+// revision of the players they change on with every change, and to leave a
+// player alone when the context of a change asks for another revision of
+// them, see poker.IfPlayerRevision.
+func RunPlayerStoreConformance(t *testing.T, factory StoreFactory) {
+	t.Helper()
+
+	t.Run("new store has an empty league", func(t *testing.T) {
+		store := factory(t)
+
+		if league := store.GetLeague(); len(league) != 0 {
+			t.Errorf("expected an empty league, got %v", league)
+		}
+	})
+
+	t.Run("added player keeps id, name and wins", func(t *testing.T) {
+		store := factory(t)
+
+		mustAdd(t, store, poker.Player{ID: 7, Name: "Cleo", Wins: 3})
+
+		assertLeague(t, store.GetLeague(), poker.League{{ID: 7, Name: "Cleo", Wins: 3}})
+		assertScore(t, store, 7, 3)
+	})
+
+	t.Run("rejects a player without a name", func(t *testing.T) {
+		store := factory(t)
+
+		if err := store.AddPlayer(&poker.Player{ID: 1}); err == nil {
+			t.Error("expected an error adding a player without a name")
+		}
+		assertLeague(t, store.GetLeague(), poker.League{})
+	})
+
+	t.Run("rejects a duplicate id", func(t *testing.T) {
+		store := factory(t)
+		mustAdd(t, store, poker.Player{ID: 1, Name: "Cleo", Wins: 2})
+
+		if err := store.AddPlayer(&poker.Player{ID: 1, Name: "Chris", Wins: 5}); err == nil {
+			t.Error("expected an error adding a second player with id 1")
+		}
+		assertLeague(t, store.GetLeague(), poker.League{{ID: 1, Name: "Cleo", Wins: 2}})
+	})
+
+	t.Run("rejects a duplicate name in any case", func(t *testing.T) {
+		store := factory(t)
+		mustAdd(t, store, poker.Player{ID: 1, Name: "Cleo"})
+
+		if err := store.AddPlayer(&poker.Player{ID: 2, Name: "CLEO"}); err == nil {
+			t.Error("expected an error adding a second player named Cleo")
+		}
+		assertLeague(t, store.GetLeague(), poker.League{{ID: 1, Name: "Cleo"}})
+	})
+
+	t.Run("rejects a negative id", func(t *testing.T) {
+		store := factory(t)
+
+		if err := store.AddPlayer(&poker.Player{ID: -1, Name: "Cleo"}); err == nil {
+			t.Error("expected an error adding a player with id -1")
+		}
+	})
+
+	t.Run("assigns ids to new players", func(t *testing.T) {
+		store := factory(t)
+		mustAdd(t, store, poker.Player{ID: 5, Name: "Lloyd"})
+
+		cleo := &poker.Player{Name: "Cleo"}
+		chris := &poker.Player{Name: "Chris"}
+		for _, player := range []*poker.Player{cleo, chris} {
+			if err := store.AddPlayer(player); err != nil {
+				t.Fatalf("could not add player %s, %v", player.Name, err)
+			}
+		}
+
+		if cleo.ID <= 0 || chris.ID <= 0 || cleo.ID == chris.ID || cleo.ID == 5 || chris.ID == 5 {
+			t.Fatalf("expected new unique ids, got %d and %d", cleo.ID, chris.ID)
+		}
+		assertScore(t, store, cleo.ID, 0)
+		if err := store.RecordWin(chris.ID); err != nil {
+			t.Errorf("could not record a win for the assigned id, %v", err)
+		}
+	})
+
+	t.Run("records a win", func(t *testing.T) {
+		store := factory(t)
+		mustAdd(t, store, poker.Player{ID: 1, Name: "Cleo", Wins: 2})
+
+		if err := store.RecordWin(1); err != nil {
+			t.Fatalf("could not record win, %v", err)
+		}
+
+		assertScore(t, store, 1, 3)
+	})
+
+	t.Run("rejects a win for an unknown id", func(t *testing.T) {
+		store := factory(t)
+		mustAdd(t, store, poker.Player{ID: 1, Name: "Cleo"})
+
+		if err := store.RecordWin(2); err == nil {
+			t.Error("expected an error recording a win for an unknown id")
+		}
+		assertLeague(t, store.GetLeague(), poker.League{{ID: 1, Name: "Cleo"}})
+	})
+
+	t.Run("deletes a player", func(t *testing.T) {
+		store := factory(t)
+		mustAdd(t, store, poker.Player{ID: 1, Name: "Cleo", Wins: 4})
+		mustAdd(t, store, poker.Player{ID: 2, Name: "Chris", Wins: 1})
+
+		if err := store.DeletePlayer(1); err != nil {
+			t.Fatalf("could not delete player, %v", err)
+		}
+
+		assertLeague(t, store.GetLeague(), poker.League{{ID: 2, Name: "Chris", Wins: 1}})
+		assertScore(t, store, 1, 0)
+	})
+
+	t.Run("rejects deleting an unknown id", func(t *testing.T) {
+		store := factory(t)
+
+		if err := store.DeletePlayer(1); err == nil {
+			t.Error("expected an error deleting an unknown id")
+		}
+	})
+
+	t.Run("score of an unknown id is 0", func(t *testing.T) {
+		store := factory(t)
+
+		assertScore(t, store, 1, 0)
+	})
+
+	t.Run("reports the score of an unknown id as not found", func(t *testing.T) {
+		store := factory(t)
+		mustAdd(t, store, poker.Player{ID: 1, Name: "Cleo"})
+		mustAdd(t, store, poker.Player{ID: 2, Name: "Chris", Wins: 3})
+		if err := store.DeletePlayer(2); err != nil {
+			t.Fatalf("could not delete player, %v", err)
+		}
+		v2 := poker.AdaptPlayerStore(store)
+
+		if wins, err := v2.GetPlayerScore(context.Background(), 1); err != nil || wins != 0 {
+			t.Errorf("got score %d, %v for a player without wins, want 0", wins, err)
+		}
+		for _, id := range []int{2, 3} {
+			if _, err := v2.GetPlayerScore(context.Background(), id); !errors.Is(err, poker.ErrPlayerNotFound) {
+				t.Errorf("got error %v for id %d want %v", err, id, poker.ErrPlayerNotFound)
+			}
+		}
+	})
+
+	t.Run("finds a player by name in any case", func(t *testing.T) {
+		store := factory(t)
+		mustAdd(t, store, poker.Player{ID: 1, Name: "Cleo", Wins: 2})
+		mustAdd(t, store, poker.Player{ID: 2, Name: "Chris"})
+
+		got, err := store.FindByName("cLEO")
+		if err != nil {
+			t.Fatalf("could not find player by name, %v", err)
+		}
+		if want := (poker.Player{ID: 1, Name: "Cleo", Wins: 2}); got != want {
+			t.Errorf("got %+v want %+v", got, want)
+		}
+	})
+
+	t.Run("rejects finding an unknown name", func(t *testing.T) {
+		store := factory(t)
+		mustAdd(t, store, poker.Player{ID: 1, Name: "Cleo"})
+
+		if _, err := store.FindByName("Chris"); !errors.Is(err, poker.ErrPlayerNotFound) {
+			t.Errorf("got error %v want %v", err, poker.ErrPlayerNotFound)
+		}
+	})
+
+	t.Run("league is ordered by wins then id", func(t *testing.T) {
+		store := factory(t)
+		mustAdd(t, store, poker.Player{ID: 3, Name: "Lloyd", Wins: 1})
+		mustAdd(t, store, poker.Player{ID: 2, Name: "Chris", Wins: 5})
+		mustAdd(t, store, poker.Player{ID: 4, Name: "Kate", Wins: 5})
+		mustAdd(t, store, poker.Player{ID: 1, Name: "Cleo", Wins: 1})
+
+		assertLeague(t, store.GetLeague(), poker.League{
+			{ID: 2, Name: "Chris", Wins: 5},
+			{ID: 4, Name: "Kate", Wins: 5},
+			{ID: 1, Name: "Cleo", Wins: 1},
+			{ID: 3, Name: "Lloyd", Wins: 1},
+		})
+	})
+
+	t.Run("league is a copy", func(t *testing.T) {
+		store := factory(t)
+		mustAdd(t, store, poker.Player{ID: 1, Name: "Cleo", Wins: 1})
+
+		league := store.GetLeague()
+		league[0].Wins = 100
+
+		assertScore(t, store, 1, 1)
+	})
+
+	t.Run("undoes the last win", func(t *testing.T) {
+		store, reverter := winReverter(t, factory)
+		mustAdd(t, store, poker.Player{ID: 1, Name: "Cleo"})
+		mustRecordWins(t, store, 1, 2)
+
+		undone, err := reverter.UndoLastWin(context.Background(), 1, poker.Reversion{By: "Chris", Reason: "wrong player"})
+		if err != nil {
+			t.Fatalf("could not undo the last win, %v", err)
+		}
+		assertScore(t, store, 1, 1)
+
+		wins, err := reverter.Wins(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("could not list wins, %v", err)
+		}
+		if len(wins) != 2 || wins[0].Reverted != nil || wins[1].ID != undone.ID || wins[1].Reverted == nil {
+			t.Fatalf("expected the second of two wins to be reverted, got %+v", wins)
+		}
+		if got := wins[1].Reverted; got.By != "Chris" || got.Reason != "wrong player" || got.At.IsZero() {
+			t.Errorf("expected who reverted the win, why and when, got %+v", got)
+		}
+	})
+
+	t.Run("revokes a win once", func(t *testing.T) {
+		store, reverter := winReverter(t, factory)
+		mustAdd(t, store, poker.Player{ID: 1, Name: "Cleo"})
+		mustRecordWins(t, store, 1, 2)
+		wins, err := reverter.Wins(context.Background(), 1)
+		if err != nil || len(wins) != 2 {
+			t.Fatalf("expected two wins, got %+v, %v", wins, err)
+		}
+
+		reversion := poker.Reversion{By: "Chris", Reason: "recorded twice"}
+		if _, err := reverter.RevokeWin(context.Background(), wins[0].ID, reversion); err != nil {
+			t.Fatalf("could not revoke win, %v", err)
+		}
+		if _, err := reverter.RevokeWin(context.Background(), wins[0].ID, reversion); !errors.Is(err, poker.ErrWinReverted) {
+			t.Errorf("got error %v want %v", err, poker.ErrWinReverted)
+		}
+		assertScore(t, store, 1, 1)
+
+		undone, err := reverter.UndoLastWin(context.Background(), 1, reversion)
+		if err != nil || undone.ID != wins[1].ID {
+			t.Fatalf("expected to undo win %d, got %+v, %v", wins[1].ID, undone, err)
+		}
+		if _, err := reverter.UndoLastWin(context.Background(), 1, reversion); !errors.Is(err, poker.ErrWinNotFound) {
+			t.Errorf("got error %v want %v", err, poker.ErrWinNotFound)
+		}
+		assertScore(t, store, 1, 0)
+	})
+
+	t.Run("rejects reverting a win it cannot find", func(t *testing.T) {
+		store, reverter := winReverter(t, factory)
+		mustAdd(t, store, poker.Player{ID: 1, Name: "Cleo"})
+		reversion := poker.Reversion{By: "Chris", Reason: "typo"}
+
+		if _, err := reverter.RevokeWin(context.Background(), 99, reversion); !errors.Is(err, poker.ErrWinNotFound) {
+			t.Errorf("got error %v want %v", err, poker.ErrWinNotFound)
+		}
+		if _, err := reverter.UndoLastWin(context.Background(), 2, reversion); !errors.Is(err, poker.ErrPlayerNotFound) {
+			t.Errorf("got error %v want %v", err, poker.ErrPlayerNotFound)
+		}
+	})
+
+	t.Run("rejects a reversion without a reason", func(t *testing.T) {
+		store, reverter := winReverter(t, factory)
+		mustAdd(t, store, poker.Player{ID: 1, Name: "Cleo"})
+		mustRecordWins(t, store, 1, 1)
+
+		_, err := reverter.UndoLastWin(context.Background(), 1, poker.Reversion{By: "Chris"})
+		if !errors.Is(err, poker.ErrInvalidReversion) {
+			t.Errorf("got error %v want %v", err, poker.ErrInvalidReversion)
+		}
+		assertScore(t, store, 1, 1)
+	})
+
+	t.Run("keeps a deleted player until restored", func(t *testing.T) {
+		store, deleted := deletedPlayerStore(t, factory)
+		mustAdd(t, store, poker.Player{ID: 1, Name: "Cleo", Wins: 4})
+		mustAdd(t, store, poker.Player{ID: 2, Name: "Chris", Wins: 1})
+		if err := store.DeletePlayer(1); err != nil {
+			t.Fatalf("could not delete player, %v", err)
+		}
+
+		found, err := deleted.DeletedPlayers(context.Background())
+		if err != nil {
+			t.Fatalf("could not list deleted players, %v", err)
+		}
+		if len(found) != 1 || found[0].ID != 1 || found[0].Wins != 4 || !found[0].Deleted() {
+			t.Fatalf("expected Cleo to be deleted with her wins, got %+v", found)
+		}
+		assertLeague(t, store.GetLeague(), poker.League{{ID: 2, Name: "Chris", Wins: 1}})
+		if _, err := store.FindByName("Cleo"); !errors.Is(err, poker.ErrPlayerNotFound) {
+			t.Errorf("got error %v want %v", err, poker.ErrPlayerNotFound)
+		}
+		if err := store.RecordWin(1); err == nil {
+			t.Error("expected an error recording a win for a deleted player")
+		}
+		if err := store.DeletePlayer(1); err == nil {
+			t.Error("expected an error deleting a player twice")
+		}
+		if err := store.AddPlayer(&poker.Player{Name: "cleo"}); !errors.Is(err, poker.ErrDuplicatePlayer) {
+			t.Errorf("got error %v want %v", err, poker.ErrDuplicatePlayer)
+		}
+
+		restored, err := deleted.RestorePlayer(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("could not restore player, %v", err)
+		}
+		if restored != (poker.Player{ID: 1, Name: "Cleo", Wins: 4}) {
+			t.Errorf("got restored player %+v", restored)
+		}
+		assertLeague(t, store.GetLeague(), poker.League{{ID: 1, Name: "Cleo", Wins: 4}, {ID: 2, Name: "Chris", Wins: 1}})
+	})
+
+	t.Run("rejects restoring a player that is not deleted", func(t *testing.T) {
+		store, deleted := deletedPlayerStore(t, factory)
+		mustAdd(t, store, poker.Player{ID: 1, Name: "Cleo"})
+
+		for _, id := range []int{1, 2} {
+			if _, err := deleted.RestorePlayer(context.Background(), id); !errors.Is(err, poker.ErrPlayerNotFound) {
+				t.Errorf("restoring %d: got error %v want %v", id, err, poker.ErrPlayerNotFound)
+			}
+		}
+	})
+
+	t.Run("purges players deleted before the cutoff", func(t *testing.T) {
+		store, deleted := deletedPlayerStore(t, factory)
+		mustAdd(t, store, poker.Player{ID: 1, Name: "Cleo", Wins: 2})
+		mustAdd(t, store, poker.Player{ID: 2, Name: "Chris"})
+		if err := store.DeletePlayer(1); err != nil {
+			t.Fatalf("could not delete player, %v", err)
+		}
+
+		if purged, err := deleted.PurgePlayers(context.Background(), time.Now().Add(-time.Hour)); err != nil || purged != 0 {
+			t.Errorf("expected nothing to purge yet, got %d, %v", purged, err)
+		}
+		if purged, err := deleted.PurgePlayers(context.Background(), time.Now().Add(time.Minute)); err != nil || purged != 1 {
+			t.Fatalf("expected 1 player purged, got %d, %v", purged, err)
+		}
+
+		found, err := deleted.DeletedPlayers(context.Background())
+		if err != nil || len(found) != 0 {
+			t.Errorf("expected no deleted players left, got %+v, %v", found, err)
+		}
+		if _, err := deleted.RestorePlayer(context.Background(), 1); !errors.Is(err, poker.ErrPlayerNotFound) {
+			t.Errorf("got error %v want %v", err, poker.ErrPlayerNotFound)
+		}
+		assertLeague(t, store.GetLeague(), poker.League{{ID: 2, Name: "Chris"}})
+	})
+
+	t.Run("does not give out the id of a purged player again", func(t *testing.T) {
+		store, deleted := deletedPlayerStore(t, factory)
+		mustAdd(t, store, poker.Player{ID: 1, Name: "Cleo"})
+		mustAdd(t, store, poker.Player{ID: 2, Name: "Chris"})
+		if err := store.DeletePlayer(2); err != nil {
+			t.Fatalf("could not delete player, %v", err)
+		}
+		if _, err := deleted.PurgePlayers(context.Background(), time.Now().Add(time.Minute)); err != nil {
+			t.Fatalf("could not purge players, %v", err)
+		}
+
+		lloyd := &poker.Player{Name: "Lloyd"}
+		if err := store.AddPlayer(lloyd); err != nil {
+			t.Fatalf("could not add player, %v", err)
+		}
+		if lloyd.ID == 1 || lloyd.ID == 2 {
+			t.Errorf("expected a new id, got %d", lloyd.ID)
+		}
+		if err := store.AddPlayer(&poker.Player{ID: 2, Name: "Chris"}); !errors.Is(err, poker.ErrDuplicatePlayer) {
+			t.Errorf("got error %v taking the id of a purged player, want %v", err, poker.ErrDuplicatePlayer)
+		}
+	})
+
+	t.Run("renames a player", func(t *testing.T) {
+		store, renamer := playerRenamer(t, factory)
+		mustAdd(t, store, poker.Player{ID: 1, Name: "Cleo", Wins: 2})
+
+		renamed, err := renamer.RenamePlayer(context.Background(), 1, "Cleopatra")
+		if err != nil {
+			t.Fatalf("could not rename player, %v", err)
+		}
+		if renamed != (poker.Player{ID: 1, Name: "Cleopatra", Wins: 2}) {
+			t.Errorf("got renamed player %+v", renamed)
+		}
+		if _, err := store.FindByName("Cleo"); !errors.Is(err, poker.ErrPlayerNotFound) {
+			t.Errorf("got error %v want %v", err, poker.ErrPlayerNotFound)
+		}
+		if _, err := renamer.RenamePlayer(context.Background(), 1, "cleopatra"); err != nil {
+			t.Errorf("expected a player to be able to change the case of their name, %v", err)
+		}
+		mustAdd(t, store, poker.Player{ID: 2, Name: "Cleo"})
+	})
+
+	t.Run("rejects renaming to a name that is taken or empty", func(t *testing.T) {
+		store, renamer := playerRenamer(t, factory)
+		mustAdd(t, store, poker.Player{ID: 1, Name: "Cleo"})
+		mustAdd(t, store, poker.Player{ID: 2, Name: "Chris"})
+
+		for _, tt := range []struct {
+			id   int
+			name string
+			want error
+		}{
+			{1, "CHRIS", poker.ErrDuplicatePlayer},
+			{1, "", poker.ErrInvalidPlayer},
+			{3, "Lloyd", poker.ErrPlayerNotFound},
+		} {
+			if _, err := renamer.RenamePlayer(context.Background(), tt.id, tt.name); !errors.Is(err, tt.want) {
+				t.Errorf("renaming %d to %q: got error %v want %v", tt.id, tt.name, err, tt.want)
+			}
+		}
+		assertLeague(t, store.GetLeague(), poker.League{{ID: 1, Name: "Cleo"}, {ID: 2, Name: "Chris"}})
+	})
+
+	t.Run("counts revisions of the store and its players", func(t *testing.T) {
+		store, revisions := revisioner(t, factory)
+		mustAdd(t, store, poker.Player{ID: 1, Name: "Cleo"})
+		mustAdd(t, store, poker.Player{ID: 2, Name: "Chris"})
+		before := mustRevision(t, revisions, 0)
+		chris := mustRevision(t, revisions, 2)
+
+		mustRecordWins(t, store, 1, 1)
+
+		after := mustRevision(t, revisions, 0)
+		if after <= before {
+			t.Errorf("expected the store revision to go up from %d, got %d", before, after)
+		}
+		if got := mustRevision(t, revisions, 1); got != after {
+			t.Errorf("got revision %d for the player who won, want the store revision %d", got, after)
+		}
+		if got := mustRevision(t, revisions, 2); got != chris {
+			t.Errorf("got revision %d for a player who did not change, want %d", got, chris)
+		}
+		if _, err := revisions.PlayerRevision(context.Background(), 3); !errors.Is(err, poker.ErrPlayerNotFound) {
+			t.Errorf("got error %v want %v", err, poker.ErrPlayerNotFound)
+		}
+	})
+
+	t.Run("only changes a player at the revision the context asks for", func(t *testing.T) {
+		store, revisions := revisioner(t, factory)
+		mustAdd(t, store, poker.Player{ID: 1, Name: "Cleo"})
+		mustAdd(t, store, poker.Player{ID: 2, Name: "Chris"})
+		v2 := poker.AdaptPlayerStore(store)
+		current := mustRevision(t, revisions, 1)
+		stale := poker.IfPlayerRevision(context.Background(), 1, current+1)
+
+		if err := v2.RecordWin(stale, 1); !errors.Is(err, poker.ErrRevisionMismatch) {
+			t.Errorf("got error %v want %v", err, poker.ErrRevisionMismatch)
+		}
+		if err := v2.DeletePlayer(stale, 1); !errors.Is(err, poker.ErrRevisionMismatch) {
+			t.Errorf("got error %v want %v", err, poker.ErrRevisionMismatch)
+		}
+		if renamer, ok := store.(poker.PlayerRenamer); ok {
+			if _, err := renamer.RenamePlayer(stale, 1, "Cleopatra"); !errors.Is(err, poker.ErrRevisionMismatch) {
+				t.Errorf("got error %v want %v", err, poker.ErrRevisionMismatch)
+			}
+		}
+		assertLeague(t, store.GetLeague(), poker.League{{ID: 1, Name: "Cleo"}, {ID: 2, Name: "Chris"}})
+
+		if err := v2.RecordWin(stale, 2); err != nil {
+			t.Errorf("expected a change to another player to go ahead, %v", err)
+		}
+		if err := v2.RecordWin(poker.IfPlayerRevision(context.Background(), 1, current), 1); err != nil {
+			t.Errorf("expected a change at the current revision to go ahead, %v", err)
+		}
+		assertScore(t, store, 1, 1)
+	})
+}
+
+// revisioner skips the test for stores that do not implement
+// poker.Revisioner.
+func revisioner(t *testing.T, factory StoreFactory) (poker.PlayerStore, poker.Revisioner) {
+	t.Helper()
+	store := factory(t)
+	revisions, ok := store.(poker.Revisioner)
+	if !ok {
+		t.Skip("store does not implement poker.Revisioner")
+	}
+	return store, revisions
+}
+
+// mustRevision returns the revision of player id, or of the store for id 0.
+func mustRevision(t testing.TB, revisions poker.Revisioner, id int) int {
+	t.Helper()
+	revision, err := revisions.Revision(context.Background())
+	if id != 0 {
+		revision, err = revisions.PlayerRevision(context.Background(), id)
+	}
+	if err != nil {
+		t.Fatalf("could not get revision, %v", err)
+	}
+	return revision
+}
+
+// playerRenamer skips the test for stores that do not implement
+// poker.PlayerRenamer.
+func playerRenamer(t *testing.T, factory StoreFactory) (poker.PlayerStore, poker.PlayerRenamer) {
+	t.Helper()
+	store := factory(t)
+	renamer, ok := store.(poker.PlayerRenamer)
+	if !ok {
+		t.Skip("store does not implement poker.PlayerRenamer")
+	}
+	return store, renamer
+}
+
+// deletedPlayerStore skips the test for stores that do not implement
+// poker.DeletedPlayerStore.
+func deletedPlayerStore(t *testing.T, factory StoreFactory) (poker.PlayerStore, poker.DeletedPlayerStore) {
+	t.Helper()
+	store := factory(t)
+	deleted, ok := store.(poker.DeletedPlayerStore)
+	if !ok {
+		t.Skip("store does not implement poker.DeletedPlayerStore")
+	}
+	return store, deleted
+}
+
+// winReverter skips the test for stores that do not implement
+// poker.WinReverter.
+func winReverter(t *testing.T, factory StoreFactory) (poker.PlayerStore, poker.WinReverter) {
+	t.Helper()
+	store := factory(t)
+	reverter, ok := store.(poker.WinReverter)
+	if !ok {
+		t.Skip("store does not implement poker.WinReverter")
+	}
+	return store, reverter
+}
+
+func mustRecordWins(t testing.TB, store poker.PlayerStore, id, wins int) {
+	t.Helper()
+	for i := 0; i < wins; i++ {
+		if err := store.RecordWin(id); err != nil {
+			t.Fatalf("could not record win, %v", err)
+		}
+	}
+}
+
+func mustAdd(t testing.TB, store poker.PlayerStore, player poker.Player) {
+	t.Helper()
+	if err := store.AddPlayer(&player); err != nil {
+		t.Fatalf("could not add player %+v, %v", player, err)
+	}
+}
+
+func assertScore(t testing.TB, store poker.PlayerStore, id, want int) {
+	t.Helper()
+	if got := store.GetPlayerScore(id); got != want {
+		t.Errorf("score for id %d: got %d want %d", id, got, want)
+	}
+}
+
+func assertLeague(t testing.TB, got, want poker.League) {
+	t.Helper()
+	if len(got) == 0 && len(want) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got league %v want %v", got, want)
+	}
+}
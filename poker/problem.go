@@ -0,0 +1,192 @@
+package poker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem is the body of every error PlayerServer answers with, an RFC 7807
+// problem detail. Code is stable, so clients can branch on it; Detail is
+// meant for people and may change. Errors lists the fields of a request that
+// did not validate, see Validate.
+type Problem struct {
+	Type   string       `json:"type"`
+	Title  string       `json:"title"`
+	Status int          `json:"status"`
+	Detail string       `json:"detail,omitempty"`
+	Code   string       `json:"code"`
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// Codes of the problems PlayerServer answers with.
+const (
+	CodeBadRequest       = "bad_request"
+	CodePayloadTooLarge  = "payload_too_large"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotImplemented   = "not_implemented"
+	CodeInternal         = "internal_error"
+
+	CodePlayerNotFound     = "player_not_found"
+	CodeDuplicatePlayer    = "duplicate_player"
+	CodeInvalidPlayer      = "invalid_player"
+	CodeGameNotFound       = "game_not_found"
+	CodeGameFinished       = "game_finished"
+	CodeInvalidGame        = "invalid_game"
+	CodeSeasonNotFound     = "season_not_found"
+	CodeSeasonClosed       = "season_closed"
+	CodeInvalidSeason      = "invalid_season"
+	CodeWinNotFound        = "win_not_found"
+	CodeWinReverted        = "win_reverted"
+	CodeInvalidReversion   = "invalid_reversion"
+	CodeInvalidQuery       = "invalid_query"
+	CodePreconditionFailed = "precondition_failed"
+	CodeRequestCanceled    = "request_canceled"
+	CodeTimeout            = "timeout"
+)
+
+// storeProblems maps the errors stores return to a status and a code. The
+// first one the error wraps wins.
+var storeProblems = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{ErrPlayerNotFound, http.StatusNotFound, CodePlayerNotFound},
+	{ErrGameNotFound, http.StatusNotFound, CodeGameNotFound},
+	{ErrSeasonNotFound, http.StatusNotFound, CodeSeasonNotFound},
+	{ErrWinNotFound, http.StatusNotFound, CodeWinNotFound},
+	{ErrDuplicatePlayer, http.StatusConflict, CodeDuplicatePlayer},
+	{ErrGameFinished, http.StatusConflict, CodeGameFinished},
+	{ErrSeasonClosed, http.StatusConflict, CodeSeasonClosed},
+	{ErrWinReverted, http.StatusConflict, CodeWinReverted},
+	{ErrInvalidPlayer, http.StatusBadRequest, CodeInvalidPlayer},
+	{ErrInvalidGame, http.StatusBadRequest, CodeInvalidGame},
+	{ErrInvalidSeason, http.StatusBadRequest, CodeInvalidSeason},
+	{ErrInvalidReversion, http.StatusBadRequest, CodeInvalidReversion},
+	{ErrInvalidQuery, http.StatusBadRequest, CodeInvalidQuery},
+	{ErrRevisionMismatch, http.StatusPreconditionFailed, CodePreconditionFailed},
+	{ErrNotSupported, http.StatusNotImplemented, CodeNotImplemented},
+	{context.Canceled, statusClientClosedRequest, CodeRequestCanceled},
+	{context.DeadlineExceeded, http.StatusGatewayTimeout, CodeTimeout},
+}
+
+// maxBodyBytes is the largest request body the server reads.
+const maxBodyBytes = 1 << 20
+
+// writeProblem answers with a problem. Its type is left as about:blank, as
+// Code tells problems apart.
+func writeProblem(w http.ResponseWriter, status int, code, detail string, fields ...FieldError) {
+	title := http.StatusText(status)
+	if status == statusClientClosedRequest {
+		title = "Client Closed Request"
+	}
+	// A Problem is only strings and a number, which always marshal.
+	data, _ := json.Marshal(Problem{Type: "about:blank", Title: title, Status: status, Detail: detail, Code: code, Errors: fields})
+	w.Header().Set("Content-Type", problemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	w.Write(append(data, '\n'))
+}
+
+// storeError answers with the problem that matches a store error, or a 500
+// for errors that are not the caller's doing. The fields of a
+// ValidationError are listed in the problem.
+func storeError(w http.ResponseWriter, err error) {
+	var fields []FieldError
+	var invalid *ValidationError
+	if errors.As(err, &invalid) {
+		fields = invalid.Fields
+	}
+	for _, problem := range storeProblems {
+		if errors.Is(err, problem.err) {
+			writeProblem(w, problem.status, problem.code, err.Error(), fields...)
+			return
+		}
+	}
+	internalError(w, err)
+}
+
+// internalError logs err, which may tell more about the server than clients
+// should know, and answers with a 500 that only points at the log entry.
+func internalError(w http.ResponseWriter, err error) {
+	id := w.Header().Get(requestIDHeader)
+	log.Printf("request %s failed, %v", id, err)
+	writeProblem(w, http.StatusInternalServerError, CodeInternal, fmt.Sprintf("the server failed to answer request %s, it has been logged", id))
+}
+
+func badRequest(w http.ResponseWriter, format string, args ...interface{}) {
+	writeProblem(w, http.StatusBadRequest, CodeBadRequest, fmt.Sprintf(format, args...))
+}
+
+// decodeJSON reads the body of r into v, strictly: fields v does not have,
+// anything after the JSON value and bodies over maxBodyBytes are rejected.
+// It answers with a problem and returns false when the body cannot be read.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(v)
+	if err == nil && decoder.Decode(&struct{}{}) != io.EOF {
+		err = errors.New("body must hold a single JSON value")
+	}
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		writeProblem(w, http.StatusRequestEntityTooLarge, CodePayloadTooLarge,
+			fmt.Sprintf("body must not be larger than %d bytes", tooLarge.Limit))
+		return false
+	case err != nil:
+		badRequest(w, "%v", err)
+		return false
+	}
+	return true
+}
+
+// validRequest decodes the body of r into v and validates it, answering
+// with the fields that are invalid and returning false when it is not.
+func validRequest(w http.ResponseWriter, r *http.Request, v interface{}, kind error) bool {
+	if !decodeJSON(w, r, v) {
+		return false
+	}
+	if err := Validate(v, kind); err != nil {
+		storeError(w, err)
+		return false
+	}
+	return true
+}
+
+// notSupported answers for an optional store feature the store lacks.
+func notSupported(w http.ResponseWriter, detail string) {
+	writeProblem(w, http.StatusNotImplemented, CodeNotImplemented, detail)
+}
+
+func notFound(w http.ResponseWriter, r *http.Request) {
+	writeProblem(w, http.StatusNotFound, CodeNotFound, fmt.Sprintf("no resource at %s", r.URL.Path))
+}
+
+// methodNotAllowed answers a request made with a method other than allowed.
+func methodNotAllowed(w http.ResponseWriter, r *http.Request, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeProblem(w, http.StatusMethodNotAllowed, CodeMethodNotAllowed,
+		fmt.Sprintf("%s is not allowed on %s", r.Method, r.URL.Path))
+}
+
+// allowMethod answers with 405 and returns false unless the request was made
+// with method.
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method != method {
+		methodNotAllowed(w, r, method)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package poker
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestProblems(t *testing.T) {
+	store := createTestDatabase(t)
+	server := NewPlayerServer(store)
+	serve := func(request *http.Request) *httptest.ResponseRecorder {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		return response
+	}
+	assertStatus(t, serve(newPlayerCreateRequest(1, "Cleo", 0)).Code, http.StatusCreated)
+
+	t.Run("answers a duplicate player with a conflict only", func(t *testing.T) {
+		response := serve(newPlayerCreateRequest(2, "cleo", 0))
+		assertProblem(t, response, http.StatusConflict, CodeDuplicatePlayer)
+		assertLocation(t, response, "")
+	})
+
+	t.Run("does not delete on a method that is not allowed", func(t *testing.T) {
+		response := serve(newGameRequest(http.MethodGet, "/delete/1", ""))
+		assertProblem(t, response, http.StatusMethodNotAllowed, CodeMethodNotAllowed)
+		if got := response.Header().Get("Allow"); got != http.MethodDelete {
+			t.Errorf("got Allow %q want %q", got, http.MethodDelete)
+		}
+		assertScoreEquals(t, store.GetPlayerScore(1), 0)
+		assertStatus(t, serve(newGetScoreRequest(1)).Code, http.StatusOK)
+	})
+
+	t.Run("answers ids that are not numbers with a bad request", func(t *testing.T) {
+		for _, path := range []string{"/info/abc", "/delete/abc", "/delete/1/2"} {
+			method := http.MethodGet
+			if path != "/info/abc" {
+				method = http.MethodDelete
+			}
+			assertProblem(t, serve(newGameRequest(method, path, "")), http.StatusBadRequest, CodeBadRequest)
+		}
+	})
+
+	t.Run("answers a missing player", func(t *testing.T) {
+		assertProblem(t, serve(newPostWinRequest(9)), http.StatusNotFound, CodePlayerNotFound)
+	})
+
+	t.Run("answers a feature the store lacks", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		NewPlayerServer(&StubPlayerStore{}).ServeHTTP(response, newGameRequest(http.MethodGet, "/games/", ""))
+		assertProblem(t, response, http.StatusNotImplemented, CodeNotImplemented)
+	})
+
+	t.Run("logs a failing store and answers without its error", func(t *testing.T) {
+		broken := createTestDatabase(t)
+		assertNoError(t, broken.db.Close())
+		logged := &bytes.Buffer{}
+		log.SetOutput(logged)
+		defer log.SetOutput(os.Stderr)
+
+		request := newGameRequest(http.MethodGet, "/api/v1/players/1", "")
+		request.Header.Set(requestIDHeader, "broken")
+		response := httptest.NewRecorder()
+		NewPlayerServer(broken).ServeHTTP(response, request)
+
+		body := response.Body.String()
+		assertProblem(t, response, http.StatusInternalServerError, CodeInternal)
+		if strings.Contains(body, "closed") || !strings.Contains(body, "broken") {
+			t.Errorf("expected a detail naming the request only, got %s", body)
+		}
+		if !strings.Contains(logged.String(), "broken") || !strings.Contains(logged.String(), "closed") {
+			t.Errorf("expected the error logged with the request id, got %q", logged)
+		}
+	})
+}
+
+func assertProblem(t testing.TB, response *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+	assertStatus(t, response.Code, status)
+	assertContentType(t, response, problemContentType)
+	var problem Problem
+	if err := json.NewDecoder(response.Body).Decode(&problem); err != nil {
+		t.Fatalf("unable to parse problem from response %q, %v", response.Body, err)
+	}
+	if problem.Status != status || problem.Code != code || problem.Title == "" {
+		t.Errorf("got problem %+v want status %d and code %q", problem, status, code)
+	}
+}
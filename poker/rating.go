@@ -0,0 +1,154 @@
+package poker
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Elo rates players from the finishing order of their games. Every game is
+// scored as a set of head-to-head matches between its participants: finishing
+// ahead of someone is a win against them, finishing level a draw. Players
+// without a position in a finished game are level with each other behind
+// everyone who was placed.
+type Elo struct {
+	// Initial is the rating of a player before their first game.
+	Initial float64
+	// K is the most a rating can move in one game.
+	K float64
+}
+
+// DefaultElo uses the usual chess values.
+var DefaultElo = Elo{Initial: 1500, K: 32}
+
+// RatingChange is what one game did to a player's rating.
+type RatingChange struct {
+	GameID   int       `json:"game_id"`
+	Date     time.Time `json:"date"`
+	Position int       `json:"position,omitempty"`
+	Change   float64   `json:"change"`
+	Rating   float64   `json:"rating"`
+}
+
+// Rating is one player's line on the ratings leaderboard.
+type Rating struct {
+	ID     int     `json:"id"`
+	Name   string  `json:"name"`
+	Rating float64 `json:"rating"`
+	Peak   float64 `json:"peak"`
+	Games  int     `json:"games"`
+}
+
+// Ratings are the players of a league with their rating history.
+type Ratings struct {
+	// Leaderboard is ordered by rating, highest first, then by id.
+	Leaderboard []Rating
+	// History holds every player's rating changes, oldest first.
+	History map[int][]RatingChange
+}
+
+// Rate replays games in the order they were played. Only the players of
+// league are rated, but everyone's games count for their opponents. Games
+// with fewer than two participants, like wins recorded without a game, do
+// not change ratings.
+func (e Elo) Rate(league League, games []GameRecord) Ratings {
+	played := append([]GameRecord(nil), games...)
+	sort.SliceStable(played, func(i, j int) bool {
+		if !played[i].Date.Equal(played[j].Date) {
+			return played[i].Date.Before(played[j].Date)
+		}
+		return played[i].ID < played[j].ID
+	})
+
+	ratings := map[int]float64{}
+	rating := func(id int) float64 {
+		if r, ok := ratings[id]; ok {
+			return r
+		}
+		return e.Initial
+	}
+
+	history := map[int][]RatingChange{}
+	for _, game := range played {
+		if game.WinnerID == 0 || len(game.Participants) < 2 {
+			continue
+		}
+		changes := e.changes(game, rating)
+		for _, participant := range game.Participants {
+			ratings[participant.PlayerID] = rating(participant.PlayerID) + changes[participant.PlayerID]
+			history[participant.PlayerID] = append(history[participant.PlayerID], RatingChange{
+				GameID:   game.ID,
+				Date:     game.Date,
+				Position: participant.Position,
+				Change:   round(changes[participant.PlayerID]),
+				Rating:   round(ratings[participant.PlayerID]),
+			})
+		}
+	}
+
+	result := Ratings{Leaderboard: make([]Rating, 0, len(league)), History: map[int][]RatingChange{}}
+	for _, player := range league {
+		r := Rating{ID: player.ID, Name: player.Name, Rating: round(rating(player.ID)), Peak: round(e.Initial)}
+		for _, change := range history[player.ID] {
+			r.Peak = math.Max(r.Peak, change.Rating)
+		}
+		r.Games = len(history[player.ID])
+		result.Leaderboard = append(result.Leaderboard, r)
+		result.History[player.ID] = append([]RatingChange{}, history[player.ID]...)
+	}
+	sort.SliceStable(result.Leaderboard, func(i, j int) bool {
+		a, b := result.Leaderboard[i], result.Leaderboard[j]
+		if a.Rating != b.Rating {
+			return a.Rating > b.Rating
+		}
+		return a.ID < b.ID
+	})
+	return result
+}
+
+// changes works out the rating change of every participant of game, using
+// the ratings from before it.
+func (e Elo) changes(game GameRecord, rating func(int) float64) map[int]float64 {
+	opponents := float64(len(game.Participants) - 1)
+	changes := make(map[int]float64, len(game.Participants))
+	for _, player := range game.Participants {
+		var score, expected float64
+		for _, opponent := range game.Participants {
+			if opponent.PlayerID == player.PlayerID {
+				continue
+			}
+			switch {
+			case player.place() < opponent.place():
+				score++
+			case player.place() == opponent.place():
+				score += 0.5
+			}
+			expected += 1 / (1 + math.Pow(10, (rating(opponent.PlayerID)-rating(player.PlayerID))/400))
+		}
+		changes[player.PlayerID] = e.K * (score - expected) / opponents
+	}
+	return changes
+}
+
+func round(rating float64) float64 {
+	return math.Round(rating*10) / 10
+}
+
+// RatePlayers rates the league of a store that keeps its game history.
+func RatePlayers(ctx context.Context, store PlayerStoreV2, elo Elo) (Ratings, error) {
+	games, ok := StoreFeature[GameStore](store)
+	if !ok {
+		return Ratings{}, fmt.Errorf("ratings are %w, it keeps no game history", ErrNotSupported)
+	}
+	league, err := store.GetLeague(ctx)
+	if err != nil {
+		return Ratings{}, err
+	}
+	played, err := games.ListGames(ctx, GameFilter{})
+	if err != nil {
+		return Ratings{}, err
+	}
+	return elo.Rate(league, played), nil
+}
@@ -0,0 +1,53 @@
+package poker
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// PlayerRating is a player's rating with the changes that led to it.
+type PlayerRating struct {
+	Rating
+	History []RatingChange `json:"history"`
+}
+
+// ratingsHandler serves the Elo ratings worked out from the game history:
+//
+//	GET /ratings/      the leaderboard
+//	GET /ratings/{id}  a player's rating and its timeline
+func (p *PlayerServer) ratingsHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	if p.games == nil {
+		notSupported(w, "ratings need a store that keeps game history")
+		return
+	}
+
+	ratings, err := RatePlayers(r.Context(), p.store, DefaultElo)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/ratings/"), "/")
+	if path == "" {
+		writeJSON(w, http.StatusOK, ratings.Leaderboard)
+		return
+	}
+
+	id, err := strconv.Atoi(path)
+	if err != nil {
+		badRequest(w, "must provide a valid id (int)")
+		return
+	}
+	for _, rating := range ratings.Leaderboard {
+		if rating.ID == id {
+			writeJSON(w, http.StatusOK, PlayerRating{Rating: rating, History: ratings.History[id]})
+			return
+		}
+	}
+	storeError(w, fmt.Errorf("%w: no player with id %d", ErrPlayerNotFound, id))
+}
@@ -0,0 +1,129 @@
+package poker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestElo(t *testing.T) {
+	league := League{{ID: 1, Name: "Cleo"}, {ID: 2, Name: "Chris"}, {ID: 3, Name: "Lloyd"}}
+	day := func(d int) time.Time { return time.Date(2024, time.March, d, 20, 0, 0, 0, time.UTC) }
+	game := func(id int, date time.Time, participants ...Participant) GameRecord {
+		return GameRecord{ID: id, Date: date, WinnerID: participants[0].PlayerID, Participants: participants}
+	}
+
+	t.Run("splits the points between head-to-head results", func(t *testing.T) {
+		ratings := DefaultElo.Rate(league, []GameRecord{
+			game(1, day(1), Participant{PlayerID: 2, Position: 1}, Participant{PlayerID: 1, Position: 2}, Participant{PlayerID: 3, Position: 3}),
+		})
+
+		want := []Rating{
+			{ID: 2, Name: "Chris", Rating: 1516, Peak: 1516, Games: 1},
+			{ID: 1, Name: "Cleo", Rating: 1500, Peak: 1500, Games: 1},
+			{ID: 3, Name: "Lloyd", Rating: 1484, Peak: 1500, Games: 1},
+		}
+		if !reflect.DeepEqual(ratings.Leaderboard, want) {
+			t.Errorf("got %+v want %+v", ratings.Leaderboard, want)
+		}
+	})
+
+	t.Run("unplaced players draw with each other", func(t *testing.T) {
+		ratings := DefaultElo.Rate(league, []GameRecord{
+			game(1, day(1), Participant{PlayerID: 1, Position: 1}, Participant{PlayerID: 2}, Participant{PlayerID: 3}),
+		})
+
+		for _, id := range []int{2, 3} {
+			history := ratings.History[id]
+			if len(history) != 1 || history[0].Change != -8 {
+				t.Errorf("expected player %d to lose 8 points, got %+v", id, history)
+			}
+		}
+	})
+
+	t.Run("replays games in date order and keeps the history", func(t *testing.T) {
+		ratings := DefaultElo.Rate(league, []GameRecord{
+			game(2, day(2), Participant{PlayerID: 2, Position: 1}, Participant{PlayerID: 1, Position: 2}),
+			game(1, day(1), Participant{PlayerID: 1, Position: 1}, Participant{PlayerID: 2, Position: 2}),
+			game(3, day(3), Participant{PlayerID: 1, Position: 1}),
+		})
+
+		history := ratings.History[1]
+		if len(history) != 2 || history[0].GameID != 1 || history[1].GameID != 2 {
+			t.Fatalf("expected games 1 and 2 in order, got %+v", history)
+		}
+		if history[0].Rating != 1516 || history[1].Change >= 0 {
+			t.Errorf("expected a win then a loss, got %+v", history)
+		}
+		// Beating a higher rated player is worth more than 16 points.
+		if got := ratings.History[2][1].Change; got <= 16 {
+			t.Errorf("expected the upset to be worth more than 16, got %v", got)
+		}
+	})
+}
+
+func TestRatingEndpoints(t *testing.T) {
+	store := createTestDatabase(t)
+	assertNoError(t, store.AddPlayer(&Player{ID: 1, Name: "Cleo"}))
+	assertNoError(t, store.AddPlayer(&Player{ID: 2, Name: "Chris"}))
+	server := NewPlayerServer(store)
+
+	created := httptest.NewRecorder()
+	server.ServeHTTP(created, newGameRequest(http.MethodPost, "/games/", `{"participants": [1, 2]}`))
+	server.ServeHTTP(httptest.NewRecorder(), newGameRequest(http.MethodPost, "/games/1/result", `{"finishing_order": [2, 1]}`))
+
+	t.Run("leaderboard", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest(http.MethodGet, "/ratings/", ""))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		var leaderboard []Rating
+		if err := json.NewDecoder(response.Body).Decode(&leaderboard); err != nil {
+			t.Fatalf("unable to parse ratings from response %q, %v", response.Body, err)
+		}
+		if len(leaderboard) != 2 || leaderboard[0].Name != "Chris" || leaderboard[0].Rating != 1516 {
+			t.Errorf("expected Chris on top with 1516, got %+v", leaderboard)
+		}
+	})
+
+	t.Run("player timeline", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest(http.MethodGet, "/ratings/1", ""))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		var rating PlayerRating
+		if err := json.NewDecoder(response.Body).Decode(&rating); err != nil {
+			t.Fatalf("unable to parse rating from response %q, %v", response.Body, err)
+		}
+		if rating.Rating.Rating != 1484 || len(rating.History) != 1 || rating.History[0].Change != -16 {
+			t.Errorf("expected one loss of 16 points, got %+v", rating)
+		}
+	})
+
+	t.Run("unknown player", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest(http.MethodGet, "/ratings/9", ""))
+		assertStatus(t, response.Code, http.StatusNotFound)
+	})
+
+	t.Run("rates the league behind an audit log", func(t *testing.T) {
+		audited := NewPlayerServer(store, WithAuditLog(NewFileAuditLog(filepath.Join(t.TempDir(), "audit.jsonl"))))
+		response := httptest.NewRecorder()
+		audited.ServeHTTP(response, newGameRequest(http.MethodGet, "/ratings/", ""))
+		assertStatus(t, response.Code, http.StatusOK)
+	})
+
+	t.Run("stores without game history answer 501", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		NewPlayerServer(&StubPlayerStore{}).ServeHTTP(response, newGameRequest(http.MethodGet, "/ratings/", ""))
+		assertStatus(t, response.Code, http.StatusNotImplemented)
+
+		_, err := RatePlayers(context.Background(), AdaptPlayerStore(&StubPlayerStore{}), DefaultElo)
+		assertErrorIs(t, err, ErrNotSupported)
+	})
+}
@@ -0,0 +1,37 @@
+package poker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// PlayerRenamer is implemented by stores that can change the name of a
+// player. The new name has to be free the way it has for AddPlayer.
+//
+// Errors wrap ErrPlayerNotFound, ErrInvalidPlayer and ErrDuplicatePlayer
+// where they apply.
+type PlayerRenamer interface {
+	RenamePlayer(ctx context.Context, id int, name string) (Player, error)
+}
+
+// checkRename reports why player id cannot be called name: an empty name, an
+// unknown player, or a name another player, deleted or not, already has.
+func (l League) checkRename(id int, name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: player name cannot be empty", ErrInvalidPlayer)
+	}
+	if l.findActive(id) == nil {
+		return fmt.Errorf("%w: no player with id %d", ErrPlayerNotFound, id)
+	}
+	for _, p := range l {
+		if p.ID != id && strings.EqualFold(p.Name, name) {
+			deleted := ""
+			if p.Deleted() {
+				deleted = ", deleted but not purged yet"
+			}
+			return fmt.Errorf("%w: player named %q already exists%s", ErrDuplicatePlayer, p.Name, deleted)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package poker
+
+import (
+	"context"
+	"fmt"
+)
+
+// Revisioner is implemented by stores that number their changes. Revision
+// is the revision of the whole store, which goes up with every change made
+// to it. PlayerRevision is the store revision a player was last changed at,
+// or 0 when they have not changed since the store started counting. Both
+// only ever go up, so clients can use them to tell whether what they read is
+// still current.
+//
+// Errors wrap ErrPlayerNotFound where it applies.
+type Revisioner interface {
+	Revision(ctx context.Context) (int, error)
+	PlayerRevision(ctx context.Context, id int) (int, error)
+}
+
+type playerRevisionKey struct{}
+
+type playerRevision struct {
+	id       int
+	revision int
+}
+
+// IfPlayerRevision returns a copy of ctx that only lets a Revisioner change
+// player id while they are still at revision. A store checks it together
+// with the change, under the same lock or in the same transaction, and fails
+// with ErrRevisionMismatch when the player has changed in the meantime.
+// Changes to other players are not affected.
+func IfPlayerRevision(ctx context.Context, id, revision int) context.Context {
+	return context.WithValue(ctx, playerRevisionKey{}, playerRevision{id: id, revision: revision})
+}
+
+// checkPlayerRevision fails with ErrRevisionMismatch when ctx only allows
+// changes to player id at another revision than current.
+func checkPlayerRevision(ctx context.Context, id, current int) error {
+	want, ok := ctx.Value(playerRevisionKey{}).(playerRevision)
+	if !ok || want.id != id || want.revision == current {
+		return nil
+	}
+	return fmt.Errorf("%w: player %d is at revision %d, not %d", ErrRevisionMismatch, id, current, want.revision)
+}
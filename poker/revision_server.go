@@ -0,0 +1,121 @@
+package poker
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// playerTag is the entity tag of a player at revision.
+func playerTag(revision int) string {
+	return `"` + strconv.Itoa(revision) + `"`
+}
+
+// leagueTag is the entity tag of the league at store revision. The same
+// league scores differently under other rules, so their name is part of it.
+func (p *PlayerServer) leagueTag(revision int) string {
+	return fmt.Sprintf(`"%d-%s"`, revision, url.PathEscape(p.scoring.Name))
+}
+
+// matchesTag reports whether tag is in header, a comma separated list of
+// entity tags or *. Weak tags, W/"3", only match when weak is set, the way
+// If-None-Match compares them and If-Match does not.
+func matchesTag(header, tag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified tags the answer to r with tag and answers 304 Not Modified
+// when the If-None-Match header of r already has it. Handlers read the
+// revision tag is made from before the data they serve, so a change made in
+// between makes the tag stale rather than the data.
+func notModified(w http.ResponseWriter, r *http.Request, tag string) bool {
+	w.Header().Set("ETag", tag)
+	if header := strings.Join(r.Header.Values("If-None-Match"), ","); header != "" && matchesTag(header, tag, true) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// ifMatch turns the If-Match header of r into a precondition on player id,
+// see IfPlayerRevision, so the store only makes the change while the player
+// is still at the revision the client last read. It answers itself when the
+// header is already not met, or cannot be met by a store without revisions.
+func (p *PlayerServer) ifMatch(w http.ResponseWriter, r *http.Request, id int) (*http.Request, bool) {
+	header := strings.Join(r.Header.Values("If-Match"), ",")
+	if header == "" {
+		return r, true
+	}
+	if p.revisions == nil {
+		writeProblem(w, http.StatusPreconditionFailed, CodePreconditionFailed, "this store does not keep revisions to match If-Match against")
+		return nil, false
+	}
+	current, err := p.revisions.PlayerRevision(r.Context(), id)
+	if err != nil {
+		storeError(w, err)
+		return nil, false
+	}
+	if !matchesTag(header, playerTag(current), false) {
+		w.Header().Set("ETag", playerTag(current))
+		storeError(w, fmt.Errorf("%w: player %d is at revision %d", ErrRevisionMismatch, id, current))
+		return nil, false
+	}
+	return r.WithContext(IfPlayerRevision(r.Context(), id, current)), true
+}
+
+// tagPlayer tags the answer to a change of player id with the revision it
+// left them at.
+func (p *PlayerServer) tagPlayer(w http.ResponseWriter, r *http.Request, id int) {
+	if p.revisions == nil {
+		return
+	}
+	if revision, err := p.revisions.PlayerRevision(r.Context(), id); err == nil {
+		w.Header().Set("ETag", playerTag(revision))
+	}
+}
+
+// storeNotModified tags the answer to r with the store's revision, as tag
+// makes it into an entity tag, and reports whether it has already answered
+// r: with 304 Not Modified, or with the error reading the revision. Stores
+// without revisions leave answers untagged.
+func (p *PlayerServer) storeNotModified(w http.ResponseWriter, r *http.Request, tag func(int) string) bool {
+	if p.revisions == nil {
+		return false
+	}
+	revision, err := p.revisions.Revision(r.Context())
+	if err != nil {
+		storeError(w, err)
+		return true
+	}
+	return notModified(w, r, tag(revision))
+}
+
+// playerNotModified is storeNotModified for the revision of player id.
+func (p *PlayerServer) playerNotModified(w http.ResponseWriter, r *http.Request, id int) bool {
+	if p.revisions == nil {
+		return false
+	}
+	revision, err := p.revisions.PlayerRevision(r.Context(), id)
+	if err != nil {
+		storeError(w, err)
+		return true
+	}
+	return notModified(w, r, playerTag(revision))
+}
@@ -0,0 +1,112 @@
+package poker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRevisions(t *testing.T) {
+	store := createTestDatabase(t)
+	assertNoError(t, store.AddPlayer(&Player{ID: 1, Name: "Cleo"}))
+	assertNoError(t, store.AddPlayer(&Player{ID: 2, Name: "Chris"}))
+	server := NewPlayerServer(store)
+	serve := func(method, path, body string, header ...string) *httptest.ResponseRecorder {
+		request := newGameRequest(method, path, body)
+		for i := 0; i+1 < len(header); i += 2 {
+			request.Header.Set(header[i], header[i+1])
+		}
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		return response
+	}
+
+	for _, path := range []string{"/league/", "/api/v1/league", "/api/v1/players", "/api/v1/players/1"} {
+		t.Run("answers "+path+" with 304 until it changes", func(t *testing.T) {
+			first := serve(http.MethodGet, path, "")
+			assertStatus(t, first.Code, http.StatusOK)
+			tag := first.Header().Get("ETag")
+			if tag == "" {
+				t.Fatal("expected an ETag")
+			}
+
+			cached := serve(http.MethodGet, path, "", "If-None-Match", `"other", W/`+tag)
+			assertStatus(t, cached.Code, http.StatusNotModified)
+			if cached.Body.Len() != 0 {
+				t.Errorf("expected no body with 304, got %q", cached.Body)
+			}
+
+			assertNoError(t, store.RecordWin(1))
+			changed := serve(http.MethodGet, path, "", "If-None-Match", tag)
+			assertStatus(t, changed.Code, http.StatusOK)
+			if changed.Header().Get("ETag") == tag {
+				t.Errorf("expected a new ETag after a win, got %s again", tag)
+			}
+		})
+	}
+
+	t.Run("tags the league with its scoring rules", func(t *testing.T) {
+		wins := serve(http.MethodGet, "/league/", "").Header().Get("ETag")
+		response := httptest.NewRecorder()
+		NewPlayerServer(store, WithScoring(ScoringPresets["top3"].RuleSet())).ServeHTTP(response, newGameRequest(http.MethodGet, "/league/", ""))
+		if tag := response.Header().Get("ETag"); tag == wins {
+			t.Errorf("expected another ETag under other rules, got %s for both", tag)
+		}
+	})
+
+	stale := serve(http.MethodGet, "/api/v1/players/1", "").Header().Get("ETag")
+	assertNoError(t, store.RecordWin(1))
+	current := serve(http.MethodGet, "/api/v1/players/1", "").Header().Get("ETag")
+	wins := store.GetPlayerScore(1)
+
+	for _, tt := range []struct {
+		method, path, body string
+	}{
+		{http.MethodPatch, "/api/v1/players/1", `{"name": "Cleopatra"}`},
+		{http.MethodDelete, "/api/v1/players/1", ""},
+		{http.MethodPost, "/api/v1/players/1/wins", ""},
+		{http.MethodPost, "/players/1/wins/undo", `{"by": "Lloyd", "reason": "miscounted"}`},
+		{http.MethodPatch, "/update/", `{"id": 1}`},
+		{http.MethodDelete, "/delete/1", ""},
+	} {
+		t.Run("refuses "+tt.method+" "+tt.path+" on a stale revision", func(t *testing.T) {
+			response := serve(tt.method, tt.path, tt.body, "If-Match", stale)
+			if got := response.Header().Get("ETag"); got != current {
+				t.Errorf("got ETag %s want the current %s", got, current)
+			}
+			assertProblem(t, response, http.StatusPreconditionFailed, CodePreconditionFailed)
+		})
+	}
+	player, err := store.V2().GetPlayer(context.Background(), 1)
+	assertNoError(t, err)
+	if player.Name != "Cleo" || player.Wins != wins {
+		t.Errorf("expected Cleo untouched with %d wins, got %+v", wins, player)
+	}
+
+	t.Run("makes a change on the current revision and tags the answer", func(t *testing.T) {
+		response := serve(http.MethodPatch, "/api/v1/players/1", `{"name": "Cleopatra"}`, "If-Match", current)
+		assertStatus(t, response.Code, http.StatusOK)
+		tag := response.Header().Get("ETag")
+		if tag == "" || tag == current {
+			t.Fatalf("expected a new ETag, got %q", tag)
+		}
+		assertStatus(t, serve(http.MethodGet, "/api/v1/players/1", "", "If-None-Match", tag).Code, http.StatusNotModified)
+		assertStatus(t, serve(http.MethodPost, "/api/v1/players/2/wins", "", "If-Match", "*").Code, http.StatusOK)
+	})
+
+	t.Run("cannot meet If-Match without revisions", func(t *testing.T) {
+		server := NewPlayerServer(&StubPlayerStore{})
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest(http.MethodGet, "/league/", ""))
+		if tag := response.Header().Get("ETag"); tag != "" {
+			t.Errorf("expected no ETag, got %s", tag)
+		}
+
+		request := newGameRequest(http.MethodDelete, "/api/v1/players/1", "")
+		request.Header.Set("If-Match", `"0"`)
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		assertProblem(t, response, http.StatusPreconditionFailed, CodePreconditionFailed)
+	})
+}
@@ -0,0 +1,304 @@
+package poker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// ScoringRule awards points to a participant of a finished game.
+type ScoringRule interface {
+	Points(game GameRecord, participant Participant) int
+	String() string
+}
+
+// PositionPoints awards points by finishing position: the first entry to the
+// winner, the second to the runner-up and so on. Positions past the end of
+// the list score nothing.
+type PositionPoints []int
+
+func (p PositionPoints) Points(game GameRecord, participant Participant) int {
+	if participant.Position < 1 || participant.Position > len(p) {
+		return 0
+	}
+	return p[participant.Position-1]
+}
+
+func (p PositionPoints) String() string {
+	points := make([]string, len(p))
+	for i, n := range p {
+		points[i] = fmt.Sprint(n)
+	}
+	return strings.Join(points, "/") + " points by finishing position"
+}
+
+// ParticipationPoints awards the same points to everyone who played.
+type ParticipationPoints int
+
+func (p ParticipationPoints) Points(game GameRecord, participant Participant) int {
+	return int(p)
+}
+
+func (p ParticipationPoints) String() string {
+	return fmt.Sprintf("%d points for playing", int(p))
+}
+
+// BountyPoints awards points for every player who finished below the
+// participant, or was not placed at all, as knock-outs are not recorded.
+type BountyPoints int
+
+func (b BountyPoints) Points(game GameRecord, participant Participant) int {
+	if participant.Position < 1 {
+		return 0
+	}
+	outlasted := 0
+	for _, other := range game.Participants {
+		if other.Position == 0 || other.Position > participant.Position {
+			outlasted++
+		}
+	}
+	return int(b) * outlasted
+}
+
+func (b BountyPoints) String() string {
+	return fmt.Sprintf("%d points for every player finished ahead of", int(b))
+}
+
+// RuleSet is the named set of rules a league is scored with. A player's
+// points are the sum of what every rule awards them in every finished game.
+type RuleSet struct {
+	Name  string
+	Rules []ScoringRule
+}
+
+// ScoringConfig is how a RuleSet is written down, in a config file for
+// example. Zero fields add no rule.
+type ScoringConfig struct {
+	Name           string `json:"name"`
+	PositionPoints []int  `json:"position_points,omitempty"`
+	Participation  int    `json:"participation,omitempty"`
+	Bounty         int    `json:"bounty,omitempty"`
+}
+
+// ScoringPresets are the rule sets LoadRuleSet knows by name.
+var ScoringPresets = map[string]ScoringConfig{
+	"wins":   {Name: "wins", PositionPoints: []int{1}},
+	"top3":   {Name: "top3", PositionPoints: []int{10, 6, 3}},
+	"league": {Name: "league", PositionPoints: []int{10, 6, 3}, Participation: 1},
+	"bounty": {Name: "bounty", PositionPoints: []int{5}, Bounty: 1},
+}
+
+// DefaultRuleSet scores a point per win, which ranks the league the same as
+// counting wins.
+var DefaultRuleSet = ScoringPresets["wins"].RuleSet()
+
+// RuleSet builds the rules c describes.
+func (c ScoringConfig) RuleSet() RuleSet {
+	rules := RuleSet{Name: c.Name}
+	if len(c.PositionPoints) > 0 {
+		rules.Rules = append(rules.Rules, PositionPoints(c.PositionPoints))
+	}
+	if c.Participation != 0 {
+		rules.Rules = append(rules.Rules, ParticipationPoints(c.Participation))
+	}
+	if c.Bounty != 0 {
+		rules.Rules = append(rules.Rules, BountyPoints(c.Bounty))
+	}
+	return rules
+}
+
+// LoadRuleSet returns the preset called nameOrPath, or else reads a
+// ScoringConfig from the JSON file at that path.
+func LoadRuleSet(nameOrPath string) (RuleSet, error) {
+	if preset, ok := ScoringPresets[nameOrPath]; ok {
+		return preset.RuleSet(), nil
+	}
+	data, err := os.ReadFile(nameOrPath)
+	if err != nil {
+		return RuleSet{}, fmt.Errorf("problem reading scoring rules %s, %v", nameOrPath, err)
+	}
+	var config ScoringConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return RuleSet{}, fmt.Errorf("problem parsing scoring rules %s, %v", nameOrPath, err)
+	}
+	if config.Name == "" {
+		config.Name = nameOrPath
+	}
+	return config.RuleSet(), nil
+}
+
+// Describe lists the rules, one per entry.
+func (r RuleSet) Describe() []string {
+	descriptions := make([]string, len(r.Rules))
+	for i, rule := range r.Rules {
+		descriptions[i] = rule.String()
+	}
+	return descriptions
+}
+
+func (r RuleSet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name        string   `json:"name"`
+		Rules       []string `json:"rules"`
+		TieBreakers []string `json:"tie_breakers"`
+	}{r.Name, r.Describe(), r.TieBreakers()})
+}
+
+// TieBreakers says how players with the same points are ordered, first
+// tie breaker first.
+func (r RuleSet) TieBreakers() []string {
+	return append([]string(nil), tieBreakers...)
+}
+
+// tieBreakers are the tie breakers of every RuleSet, see compareStandings.
+var tieBreakers = []string{
+	"more wins",
+	"more second places, then third places and so on",
+	"lower id",
+}
+
+// Standing is a player's place in a league ranked by points. Rank is 0 in
+// pages of the league sorted some other way, see QueryLeague.
+type Standing struct {
+	Rank   int    `json:"rank,omitempty"`
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Wins   int    `json:"wins"`
+	Points int    `json:"points"`
+	Games  int    `json:"games"`
+	// places counts the player's finishes, places[0] being their wins.
+	places []int
+}
+
+// Standings is a league ranked by points. Players that tie on everything
+// but their id share a rank.
+type Standings []Standing
+
+// Score ranks the players of league by the points they earned in games.
+// Players that are not in league are left out; wins are taken from the
+// league, so they match GetLeague.
+func (r RuleSet) Score(league League, games []GameRecord) Standings {
+	standings := make(Standings, len(league))
+	index := make(map[int]int, len(league))
+	for i, player := range league {
+		standings[i] = Standing{ID: player.ID, Name: player.Name, Wins: player.Wins}
+		index[player.ID] = i
+	}
+
+	for _, game := range games {
+		if game.WinnerID == 0 {
+			continue
+		}
+		for _, participant := range game.Participants {
+			i, ok := index[participant.PlayerID]
+			if !ok {
+				continue
+			}
+			standing := &standings[i]
+			standing.Games++
+			for _, rule := range r.Rules {
+				standing.Points += rule.Points(game, participant)
+			}
+			if participant.Position > 0 {
+				for len(standing.places) < participant.Position {
+					standing.places = append(standing.places, 0)
+				}
+				standing.places[participant.Position-1]++
+			}
+		}
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		if c := compareStandings(standings[i], standings[j]); c != 0 {
+			return c > 0
+		}
+		return standings[i].ID < standings[j].ID
+	})
+	for i := range standings {
+		standings[i].Rank = i + 1
+		if i > 0 && compareStandings(standings[i], standings[i-1]) == 0 {
+			standings[i].Rank = standings[i-1].Rank
+		}
+	}
+	return standings
+}
+
+// compareStandings is positive when a ranks above b on points and the tie
+// breakers that come before the id.
+func compareStandings(a, b Standing) int {
+	if a.Points != b.Points {
+		return a.Points - b.Points
+	}
+	if a.Wins != b.Wins {
+		return a.Wins - b.Wins
+	}
+	for place := 1; place < max(len(a.places), len(b.places)); place++ {
+		if c := placeCount(a, place) - placeCount(b, place); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func placeCount(s Standing, place int) int {
+	if place < len(s.places) {
+		return s.places[place]
+	}
+	return 0
+}
+
+// ScoreLeague ranks the league of store with rules. Wins that are not in the
+// store's game history, like the wins a player joined with or every win of a
+// store without one, score as games won alone.
+func ScoreLeague(ctx context.Context, store PlayerStoreV2, rules RuleSet) (Standings, error) {
+	league, err := store.GetLeague(ctx)
+	if err != nil {
+		return nil, err
+	}
+	games, err := leagueGames(ctx, store, league)
+	if err != nil {
+		return nil, err
+	}
+	return rules.Score(league, games), nil
+}
+
+// leagueGames returns the games the players of league are scored on: the
+// game history of store, if it has one, and a game won alone for every win
+// of a player the history does not account for.
+func leagueGames(ctx context.Context, store PlayerStoreV2, league League) ([]GameRecord, error) {
+	var games []GameRecord
+	if history, ok := StoreFeature[GameStore](store); ok {
+		var err error
+		if games, err = history.ListGames(ctx, GameFilter{}); err != nil {
+			return nil, err
+		}
+	}
+	won := map[int]int{}
+	for _, game := range games {
+		won[game.WinnerID]++
+	}
+	for _, player := range league {
+		for i := won[player.ID]; i < player.Wins; i++ {
+			games = append(games, GameRecord{
+				WinnerID:     player.ID,
+				Participants: []Participant{{PlayerID: player.ID, Name: player.Name, Position: 1}},
+			})
+		}
+	}
+	return games, nil
+}
+
+// PrintStandings writes standings as a ranked table with points.
+func PrintStandings(out io.Writer, standings Standings) error {
+	table := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "Rank\tPlayer\tPoints\tWins\tGames")
+	for _, standing := range standings {
+		fmt.Fprintf(table, "%d\t%s\t%d\t%d\t%d\n", standing.Rank, standing.Name, standing.Points, standing.Wins, standing.Games)
+	}
+	return table.Flush()
+}
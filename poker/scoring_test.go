@@ -0,0 +1,177 @@
+package poker
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScoringRules(t *testing.T) {
+	game := GameRecord{WinnerID: 1, Participants: []Participant{
+		{PlayerID: 1, Position: 1},
+		{PlayerID: 2, Position: 2},
+		{PlayerID: 3, Position: 3},
+		{PlayerID: 4},
+	}}
+
+	tests := []struct {
+		name string
+		rule ScoringRule
+		want []int
+	}{
+		{"position points", PositionPoints{10, 6, 3}, []int{10, 6, 3, 0}},
+		{"position points past the list", PositionPoints{5}, []int{5, 0, 0, 0}},
+		{"participation", ParticipationPoints(2), []int{2, 2, 2, 2}},
+		{"bounty", BountyPoints(1), []int{3, 2, 1, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, participant := range game.Participants {
+				if got := tt.rule.Points(game, participant); got != tt.want[i] {
+					t.Errorf("player %d: got %d points want %d", participant.PlayerID, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRuleSetScore(t *testing.T) {
+	league := League{
+		{ID: 1, Name: "Cleo", Wins: 1},
+		{ID: 2, Name: "Chris", Wins: 1},
+		{ID: 3, Name: "Lloyd", Wins: 0},
+	}
+	finished := func(order ...int) GameRecord {
+		game := GameRecord{WinnerID: order[0]}
+		for i, id := range order {
+			game.Participants = append(game.Participants, Participant{PlayerID: id, Position: i + 1})
+		}
+		return game
+	}
+	games := []GameRecord{
+		finished(1, 3, 2),
+		finished(2, 3, 1),
+		{Participants: []Participant{{PlayerID: 3}, {PlayerID: 1}}}, // not finished
+	}
+
+	t.Run("ranks by points and shares ranks on full ties", func(t *testing.T) {
+		standings := ScoringPresets["top3"].RuleSet().Score(league, games)
+
+		assertStandings(t, standings, []Standing{
+			{Rank: 1, ID: 1, Name: "Cleo", Wins: 1, Points: 13, Games: 2},
+			{Rank: 1, ID: 2, Name: "Chris", Wins: 1, Points: 13, Games: 2},
+			{Rank: 3, ID: 3, Name: "Lloyd", Wins: 0, Points: 12, Games: 2},
+		})
+	})
+
+	t.Run("breaks ties on points by wins", func(t *testing.T) {
+		rules := RuleSet{Name: "test", Rules: []ScoringRule{PositionPoints{2, 1}}}
+		standings := rules.Score(league, games)
+
+		assertStandings(t, standings, []Standing{
+			{Rank: 1, ID: 1, Name: "Cleo", Wins: 1, Points: 2, Games: 2},
+			{Rank: 1, ID: 2, Name: "Chris", Wins: 1, Points: 2, Games: 2},
+			{Rank: 3, ID: 3, Name: "Lloyd", Wins: 0, Points: 2, Games: 2},
+		})
+	})
+
+	t.Run("breaks ties on wins by second places", func(t *testing.T) {
+		standings := DefaultRuleSet.Score(league, []GameRecord{finished(1, 2, 3), finished(2, 3, 1)})
+
+		assertStandings(t, standings, []Standing{
+			{Rank: 1, ID: 2, Name: "Chris", Wins: 1, Points: 1, Games: 2},
+			{Rank: 2, ID: 1, Name: "Cleo", Wins: 1, Points: 1, Games: 2},
+			{Rank: 3, ID: 3, Name: "Lloyd", Wins: 0, Points: 0, Games: 2},
+		})
+	})
+}
+
+func TestScoreLeague(t *testing.T) {
+	t.Run("scores wins when there is no game history", func(t *testing.T) {
+		store := &StubPlayerStore{League: []Player{{ID: 2, Name: "Chris", Wins: 3}, {ID: 1, Name: "Cleo", Wins: 1}}}
+
+		standings, err := ScoreLeague(context.Background(), AdaptPlayerStore(store), ScoringPresets["top3"].RuleSet())
+		assertNoError(t, err)
+
+		assertStandings(t, standings, []Standing{
+			{Rank: 1, ID: 2, Name: "Chris", Wins: 3, Points: 30, Games: 3},
+			{Rank: 2, ID: 1, Name: "Cleo", Wins: 1, Points: 10, Games: 1},
+		})
+	})
+
+	t.Run("scores the wins a player joined with next to the game history", func(t *testing.T) {
+		store := createTestDatabase(t)
+		assertNoError(t, store.AddPlayer(&Player{ID: 1, Name: "Cleo"}))
+		assertNoError(t, store.AddPlayer(&Player{ID: 2, Name: "Chris", Wins: 2}))
+		game := &GameRecord{Participants: []Participant{{PlayerID: 1}, {PlayerID: 2}}}
+		assertNoError(t, store.CreateGame(context.Background(), game))
+		assertNoError(t, store.RecordResult(context.Background(), game.ID, []int{1, 2}))
+
+		standings, err := ScoreLeague(context.Background(), store.V2(), ScoringPresets["top3"].RuleSet())
+		assertNoError(t, err)
+
+		assertStandings(t, standings, []Standing{
+			{Rank: 1, ID: 2, Name: "Chris", Wins: 2, Points: 26, Games: 3},
+			{Rank: 2, ID: 1, Name: "Cleo", Wins: 1, Points: 10, Games: 1},
+		})
+	})
+}
+
+func TestLoadRuleSet(t *testing.T) {
+	t.Run("preset", func(t *testing.T) {
+		rules, err := LoadRuleSet("top3")
+		assertNoError(t, err)
+		if rules.Name != "top3" || len(rules.Rules) != 1 {
+			t.Errorf("got %+v", rules)
+		}
+	})
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "rules.json")
+		assertNoError(t, os.WriteFile(path, []byte(`{"name": "home", "position_points": [3, 1], "participation": 1}`), 0666))
+
+		rules, err := LoadRuleSet(path)
+		assertNoError(t, err)
+		want := []string{"3/1 points by finishing position", "1 points for playing"}
+		if got := rules.Describe(); rules.Name != "home" || len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+			t.Errorf("got %s %v want home %v", rules.Name, got, want)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		_, err := LoadRuleSet(filepath.Join(t.TempDir(), "rules.json"))
+		assertError(t, err)
+	})
+}
+
+func TestPrintStandings(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	standings := Standings{
+		{Rank: 1, ID: 3, Name: "Lloyd", Wins: 1, Points: 22, Games: 3},
+		{Rank: 2, ID: 1, Name: "Cleo", Wins: 1, Points: 19, Games: 3},
+	}
+
+	assertNoError(t, PrintStandings(buffer, standings))
+
+	want := "" +
+		"Rank  Player  Points  Wins  Games\n" +
+		"1     Lloyd   22      1     3\n" +
+		"2     Cleo    19      1     3\n"
+	assertResponseBody(t, buffer.String(), want)
+}
+
+func assertStandings(t testing.TB, got Standings, want []Standing) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %+v want %+v", got, want)
+	}
+	for i := range want {
+		got[i].places = nil
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("standing %d: got %+v want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package poker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Season is a stretch of time the league is played over. Standings count the
+// wins of games played from StartsAt up to, but not including, EndsAt. An
+// open season has no EndsAt and runs until it is closed.
+//
+// Closing a season keeps its standings as they were, so results recorded
+// later for games in its range do not change it. A season with an EndsAt is
+// closed by itself the first time it is read after it ends. Seasons do not
+// overlap.
+type Season struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	StartsAt  time.Time  `json:"starts_at"`
+	EndsAt    *time.Time `json:"ends_at"`
+	ClosedAt  *time.Time `json:"closed_at"`
+	Standings League     `json:"standings,omitempty"`
+}
+
+// SeasonStore keeps seasons and their standings. All-time standings are the
+// league of the PlayerStore.
+//
+// Errors wrap ErrSeasonNotFound, ErrSeasonClosed and ErrInvalidSeason where
+// they apply.
+type SeasonStore interface {
+	// CreateSeason stores a new season, filling in season.ID. A zero
+	// StartsAt means now. A season that overlaps another is invalid.
+	CreateSeason(ctx context.Context, season *Season) error
+	// CloseSeason ends the season now, unless it has an earlier EndsAt,
+	// and keeps its standings.
+	CloseSeason(ctx context.Context, id int) (Season, error)
+	// GetSeason returns the season with its standings. It and
+	// ListSeasons close the seasons whose EndsAt has passed first.
+	GetSeason(ctx context.Context, id int) (Season, error)
+	// ListSeasons returns all seasons, latest start first, without
+	// standings.
+	ListSeasons(ctx context.Context) ([]Season, error)
+}
+
+// SeasonTable is a season with the all-time league next to its standings.
+type SeasonTable struct {
+	Season
+	AllTime League `json:"all_time"`
+}
+
+// Closed reports whether the season's standings are final.
+func (s Season) Closed() bool {
+	return s.ClosedAt != nil
+}
+
+func (s Season) check() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return fmt.Errorf("%w: season name cannot be empty", ErrInvalidSeason)
+	}
+	if s.EndsAt != nil && !s.EndsAt.After(s.StartsAt) {
+		return fmt.Errorf("%w: season must end after it starts", ErrInvalidSeason)
+	}
+	return nil
+}
+
+// PrintLeague writes league as a ranked table.
+func PrintLeague(out io.Writer, league League) error {
+	table := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "Rank\tPlayer\tWins")
+	for i, player := range league {
+		fmt.Fprintf(table, "%d\t%s\t%d\n", i+1, player.Name, player.Wins)
+	}
+	return table.Flush()
+}
+
+// PrintSeasonTable writes the standings of a season as a ranked table, with
+// the all-time wins of each player next to their wins in the season.
+func PrintSeasonTable(out io.Writer, table SeasonTable) error {
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "Rank\tPlayer\t%s\tAll-time\n", table.Name)
+	for i, player := range table.Standings {
+		allTime := "-"
+		if total := table.AllTime.Find(player.ID); total != nil {
+			allTime = strconv.Itoa(total.Wins)
+		}
+		fmt.Fprintf(writer, "%d\t%s\t%d\t%s\n", i+1, player.Name, player.Wins, allTime)
+	}
+	return writer.Flush()
+}
+
+// PrintSeasons writes one line per season with its dates and whether it is
+// still open.
+func PrintSeasons(out io.Writer, seasons []Season) error {
+	table := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tSeason\tStarts\tEnds\tStatus")
+	for _, season := range seasons {
+		ends, status := "-", "open"
+		if season.EndsAt != nil {
+			ends = season.EndsAt.Format(time.DateOnly)
+		}
+		if season.Closed() {
+			status = "closed"
+		}
+		fmt.Fprintf(table, "%d\t%s\t%s\t%s\t%s\n", season.ID, season.Name, season.StartsAt.Format(time.DateOnly), ends, status)
+	}
+	return table.Flush()
+}
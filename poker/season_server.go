@@ -0,0 +1,107 @@
+package poker
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewSeason is the body of a request that creates a season.
+type NewSeason struct {
+	Name     string     `json:"name"`
+	StartsAt time.Time  `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+}
+
+// seasonsHandler serves the seasons:
+//
+//	GET  /seasons/            list seasons
+//	POST /seasons/            create a season from a NewSeason
+//	GET  /seasons/{id}        one season with its standings
+//	POST /seasons/{id}/close  close a season and keep its standings
+//
+// All-time standings are served by /league/.
+func (p *PlayerServer) seasonsHandler(w http.ResponseWriter, r *http.Request) {
+	if p.seasons == nil {
+		notSupported(w, "seasons need the sqlite or postgres store")
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/seasons/"), "/"), "/")
+	if parts[0] == "" {
+		switch r.Method {
+		case http.MethodGet:
+			p.listSeasons(w, r)
+		case http.MethodPost:
+			p.createSeason(w, r)
+		default:
+			methodNotAllowed(w, r, http.MethodGet, http.MethodPost)
+		}
+		return
+	}
+
+	id, err := strconv.Atoi(parts[0])
+	if err != nil {
+		badRequest(w, "must provide a valid season id (int)")
+		return
+	}
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		p.getSeason(w, r, id)
+	case len(parts) == 2 && parts[1] == "close" && r.Method == http.MethodPost:
+		p.closeSeason(w, r, id)
+	case len(parts) == 1:
+		methodNotAllowed(w, r, http.MethodGet)
+	case len(parts) == 2 && parts[1] == "close":
+		methodNotAllowed(w, r, http.MethodPost)
+	default:
+		notFound(w, r)
+	}
+}
+
+func (p *PlayerServer) listSeasons(w http.ResponseWriter, r *http.Request) {
+	seasons, err := p.seasons.ListSeasons(r.Context())
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, seasons)
+}
+
+func (p *PlayerServer) createSeason(w http.ResponseWriter, r *http.Request) {
+	var request NewSeason
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	season := Season{Name: request.Name, StartsAt: request.StartsAt, EndsAt: request.EndsAt}
+	if err := p.seasons.CreateSeason(r.Context(), &season); err != nil {
+		storeError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/seasons/"+strconv.Itoa(season.ID))
+	writeJSON(w, http.StatusCreated, season)
+}
+
+func (p *PlayerServer) getSeason(w http.ResponseWriter, r *http.Request, id int) {
+	season, err := p.seasons.GetSeason(r.Context(), id)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	allTime, err := p.store.GetLeague(r.Context())
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, SeasonTable{Season: season, AllTime: allTime})
+}
+
+func (p *PlayerServer) closeSeason(w http.ResponseWriter, r *http.Request, id int) {
+	season, err := p.seasons.CloseSeason(r.Context(), id)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, season)
+}
@@ -0,0 +1,67 @@
+package poker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSeasonEndpoints(t *testing.T) {
+	store := createTestDatabase(t)
+	assertNoError(t, store.AddPlayer(&Player{ID: 1, Name: "Cleo"}))
+	server := NewPlayerServer(store)
+
+	created := httptest.NewRecorder()
+	server.ServeHTTP(created, newGameRequest(http.MethodPost, "/seasons/", `{"name": "Spring", "starts_at": "2024-03-01T00:00:00Z"}`))
+	assertStatus(t, created.Code, http.StatusCreated)
+	assertLocation(t, created, "/seasons/1")
+
+	t.Run("closes a season", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest(http.MethodPost, "/seasons/1/close", ""))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		var season Season
+		if err := json.NewDecoder(response.Body).Decode(&season); err != nil {
+			t.Fatalf("unable to parse season from response %q, %v", response.Body, err)
+		}
+		if !season.Closed() {
+			t.Errorf("expected the season to be closed, got %+v", season)
+		}
+	})
+
+	t.Run("serves a season next to the all-time league", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest(http.MethodGet, "/seasons/1", ""))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		var table SeasonTable
+		if err := json.NewDecoder(response.Body).Decode(&table); err != nil {
+			t.Fatalf("unable to parse season from response %q, %v", response.Body, err)
+		}
+		if table.Name != "Spring" || len(table.AllTime) != 1 || table.AllTime[0].Name != "Cleo" {
+			t.Errorf("expected Spring with Cleo all-time, got %+v", table)
+		}
+	})
+
+	tests := []struct {
+		name           string
+		request        *http.Request
+		expectedStatus int
+	}{
+		{"list", newGameRequest(http.MethodGet, "/seasons/", ""), http.StatusOK},
+		{"get", newGameRequest(http.MethodGet, "/seasons/1", ""), http.StatusOK},
+		{"missing season", newGameRequest(http.MethodGet, "/seasons/9", ""), http.StatusNotFound},
+		{"close twice", newGameRequest(http.MethodPost, "/seasons/1/close", ""), http.StatusConflict},
+		{"no name", newGameRequest(http.MethodPost, "/seasons/", `{"name": ""}`), http.StatusBadRequest},
+		{"wrong method", newGameRequest(http.MethodPut, "/seasons/1", ""), http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, tt.request)
+			assertStatus(t, response.Code, tt.expectedStatus)
+		})
+	}
+}
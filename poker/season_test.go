@@ -0,0 +1,52 @@
+package poker
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestPrintLeague(t *testing.T) {
+	buffer := &bytes.Buffer{}
+
+	assertNoError(t, PrintLeague(buffer, League{{ID: 2, Name: "Chris", Wins: 12}, {ID: 1, Name: "Cleo", Wins: 3}}))
+
+	want := "" +
+		"Rank  Player  Wins\n" +
+		"1     Chris   12\n" +
+		"2     Cleo    3\n"
+	assertResponseBody(t, buffer.String(), want)
+}
+
+func TestPrintSeasonTable(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	table := SeasonTable{
+		Season:  Season{Name: "Spring", Standings: League{{ID: 1, Name: "Cleo", Wins: 3}, {ID: 3, Name: "Lloyd", Wins: 1}}},
+		AllTime: League{{ID: 2, Name: "Chris", Wins: 12}, {ID: 1, Name: "Cleo", Wins: 5}},
+	}
+
+	assertNoError(t, PrintSeasonTable(buffer, table))
+
+	want := "" +
+		"Rank  Player  Spring  All-time\n" +
+		"1     Cleo    3       5\n" +
+		"2     Lloyd   1       -\n"
+	assertResponseBody(t, buffer.String(), want)
+}
+
+func TestPrintSeasons(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	ends := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	seasons := []Season{
+		{ID: 2, Name: "Summer", StartsAt: ends},
+		{ID: 1, Name: "Spring", StartsAt: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), EndsAt: &ends, ClosedAt: &ends},
+	}
+
+	assertNoError(t, PrintSeasons(buffer, seasons))
+
+	want := "" +
+		"ID  Season  Starts      Ends        Status\n" +
+		"2   Summer  2024-06-01  -           open\n" +
+		"1   Spring  2024-03-01  2024-06-01  closed\n"
+	assertResponseBody(t, buffer.String(), want)
+}
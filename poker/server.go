@@ -1,12 +1,13 @@
 package poker
 
 import (
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/gorilla/mux"
 )
 
 type PlayerStore interface {
@@ -15,65 +16,161 @@ type PlayerStore interface {
 	GetLeague() League
 	AddPlayer(player *Player) error
 	DeletePlayer(id int) error
+	FindByName(name string) (Player, error)
 }
 
 type Winner struct {
-	ID   int    `json:"id"`
+	ID   int    `json:"id" validate:"min=1,max=2147483647"`
 	Name string `json:"name"`
 }
 
 type Resource struct {
+	ID        int    `json:"id"`
 	Name      string `json:"name"`
 	Wins      int    `json:"wins"`
 	CreatedAt string `json:"created_at"`
 }
 
+// Player is a member of the league. DeletePlayer only marks a player as
+// deleted by setting DeletedAt; stores hide deleted players until they are
+// restored or purged, see DeletedPlayerStore.
 type Player struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
-	Wins int    `json:"wins"`
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Wins      int        `json:"wins"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+func (p Player) Deleted() bool {
+	return p.DeletedAt != nil
 }
 
 type PlayerServer struct {
-	store PlayerStore
+	store     PlayerStoreV2
+	scoring   RuleSet
+	games     GameStore
+	money     MoneyStore
+	seasons   SeasonStore
+	wins      WinReverter
+	deleted   DeletedPlayerStore
+	renamer   PlayerRenamer
+	revisions Revisioner
+	audit     AuditLog
 	http.Handler
 }
 
 const jsonContentType = "application/json"
 
-func NewPlayerServer(store PlayerStore) *PlayerServer {
+// statusClientClosedRequest is the nginx convention for a request the client
+// gave up on before it was answered.
+const statusClientClosedRequest = 499
+
+// ServerOption configures a PlayerServer.
+type ServerOption func(*PlayerServer)
+
+// WithScoring ranks /league/ by the points rules awards instead of by wins.
+func WithScoring(rules RuleSet) ServerOption {
+	return func(p *PlayerServer) {
+		p.scoring = rules
+	}
+}
+
+// WithAuditLog records every change made through the server in log and
+// serves the log to admins on /audit.
+func WithAuditLog(log AuditLog) ServerOption {
+	return func(p *PlayerServer) {
+		p.audit = log
+	}
+}
+
+func NewPlayerServer(store PlayerStore, options ...ServerOption) *PlayerServer {
+	return NewPlayerServerV2(AdaptPlayerStore(store), options...)
+}
+
+func NewPlayerServerV2(store PlayerStoreV2, options ...ServerOption) *PlayerServer {
 	p := new(PlayerServer)
 
 	p.store = store
+	p.scoring = DefaultRuleSet
+	for _, option := range options {
+		option(p)
+	}
+	p.games, _ = StoreFeature[GameStore](store)
+	p.money, _ = StoreFeature[MoneyStore](store)
+	p.seasons, _ = StoreFeature[SeasonStore](store)
+	p.wins, _ = StoreFeature[WinReverter](store)
+	p.deleted, _ = StoreFeature[DeletedPlayerStore](store)
+	p.renamer, _ = StoreFeature[PlayerRenamer](store)
+	p.revisions, _ = StoreFeature[Revisioner](store)
+	if p.audit != nil {
+		p.store = NewAuditedStore(store, p.audit)
+		if p.deleted != nil {
+			p.deleted = AuditDeleted(p.deleted, p.audit)
+		}
+		if p.renamer != nil {
+			p.renamer = AuditRenames(store, p.renamer, p.audit)
+		}
+		if p.games != nil {
+			p.games = AuditGames(store, p.games, p.audit)
+		}
+		if p.money != nil {
+			p.money = AuditMoney(p.money, p.audit)
+		}
+		if p.seasons != nil {
+			p.seasons = AuditSeasons(p.seasons, p.audit)
+		}
+		if p.wins != nil {
+			p.wins = AuditWins(store, p.wins, p.audit)
+		}
+	}
 
 	router := http.NewServeMux()
-	router.Handle("/league/", http.HandlerFunc(p.leagueHandler))
-	router.Handle("/update/", http.HandlerFunc(p.updateHandler))
-	router.Handle("/create/", http.HandlerFunc(p.createHandler))
-	router.Handle("/info/", http.HandlerFunc(p.infoHandler))
-	router.Handle("/delete/", http.HandlerFunc(p.deleteHandler))
+	router.Handle("/league/", deprecated("/league/", "/league", p.leagueHandler))
+	router.Handle("/league/rules", http.HandlerFunc(p.rulesHandler))
+	router.Handle("/update/", deprecated("/update/", "/players", p.updateHandler))
+	router.Handle("/create/", deprecated("/create/", "/players", p.createHandler))
+	router.Handle("/info/", deprecated("/info/", "/players", p.infoHandler))
+	router.Handle("/delete/", deprecated("/delete/", "/players", p.deleteHandler))
+	router.Handle("/games/", http.HandlerFunc(p.gamesHandler))
+	router.Handle("/earnings/", http.HandlerFunc(p.earningsHandler))
+	router.Handle("/seasons/", http.HandlerFunc(p.seasonsHandler))
+	router.Handle("/ratings/", http.HandlerFunc(p.ratingsHandler))
+	router.Handle("/players/", http.HandlerFunc(p.playersHandler))
+	router.Handle("/wins/", http.HandlerFunc(p.winsHandler))
+	router.Handle("/deleted/", http.HandlerFunc(p.deletedHandler))
+	router.Handle("/audit", http.HandlerFunc(p.auditHandler))
+	router.Handle("/audit/export", http.HandlerFunc(p.auditExportHandler))
 
-	p.Handler = router
+	api := mux.NewRouter()
+	p.apiRoutes(api)
+	api.PathPrefix("/").Handler(router)
+
+	p.Handler = RequestContextHandler(api, SourceREST)
 
 	return p
 }
 
 // DELETE
 func (p *PlayerServer) deleteHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodDelete {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+	if !allowMethod(w, r, http.MethodDelete) {
+		return
 	}
 	parts := strings.Split(r.URL.Path, "/")
 	if len(parts) != 3 {
-		http.Error(w, "Invalid path", http.StatusBadRequest)
+		badRequest(w, "invalid path %s", r.URL.Path)
 		return
 	}
 	id, err := strconv.Atoi(parts[2])
 	if err != nil {
-		http.Error(w, "must provide a valid id (int)", http.StatusBadRequest)
+		badRequest(w, "must provide a valid id (int), got %q", parts[2])
+		return
 	}
-	if err := p.store.DeletePlayer(id); err != nil {
-		http.Error(w, "Invalid username id provided", http.StatusNotFound)
+	r, ok := p.ifMatch(w, r, id)
+	if !ok {
+		return
+	}
+	if err := p.store.DeletePlayer(r.Context(), id); err != nil {
+		storeError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -81,81 +178,123 @@ func (p *PlayerServer) deleteHandler(w http.ResponseWriter, r *http.Request) {
 
 // POST
 func (p *PlayerServer) createHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+	if !allowMethod(w, r, http.MethodPost) {
 		return
 	}
-	var player Player
-	if err := json.NewDecoder(r.Body).Decode(&player); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	var input PlayerInput
+	if !validRequest(w, r, &input, ErrInvalidPlayer) {
 		return
 	}
-	if err := p.store.AddPlayer(&player); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	player := input.Player()
+	if err := p.store.AddPlayer(r.Context(), &player); err != nil {
+		storeError(w, err)
+		return
 	}
 	resource := Resource{
+		ID:        player.ID,
 		Name:      player.Name,
 		Wins:      player.Wins,
 		CreatedAt: time.Now().Format("2006-01-02 15:04:05"),
 	}
-	w.Header().Set("Location", "/info/"+player.Name)
-	w.Header().Set("Content-Type", jsonContentType)
-	w.WriteHeader(http.StatusCreated)
+	w.Header().Set("Location", "/info/"+strconv.Itoa(player.ID))
+	writeJSON(w, http.StatusCreated, resource)
+}
 
-	if err := json.NewEncoder(w).Encode(resource); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+// GET
+func (p *PlayerServer) leagueHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
 	}
+	p.serveLeague(w, r)
+}
+
+// scoringRulesHeader names the rule set a league response was ranked with.
+// /league/rules describes it.
+const scoringRulesHeader = "X-Scoring-Rules"
+
+// totalCountHeader is how many players match a league query over all its
+// pages.
+const totalCountHeader = "X-Total-Count"
 
+// serveLeague answers with the page of the league the parameters of r ask
+// for, see ParseLeagueQuery. The body stays a list of standings; the total
+// and a Link to the next page, when there is one, are in the headers.
+func (p *PlayerServer) serveLeague(w http.ResponseWriter, r *http.Request) {
+	query, err := ParseLeagueQuery(r.URL.Query())
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	if p.storeNotModified(w, r, p.leagueTag) {
+		return
+	}
+	page, err := QueryLeague(r.Context(), p.store, p.scoring, query)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	w.Header().Set(scoringRulesHeader, p.scoring.Name)
+	w.Header().Set(totalCountHeader, strconv.Itoa(page.Total))
+	if next, ok := page.Next(); ok {
+		w.Header().Add("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, next.Values().Encode()))
+	}
+	writeJSON(w, http.StatusOK, page.Standings)
 }
 
 // GET
-func (p *PlayerServer) leagueHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+func (p *PlayerServer) rulesHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
 		return
 	}
-	w.Header().Set("content-type", jsonContentType)
-	if err := json.NewEncoder(w).Encode(p.store.GetLeague()); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
+	writeJSON(w, http.StatusOK, p.scoring)
 }
 
 // PATCH
 func (p *PlayerServer) updateHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPatch {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+	if !allowMethod(w, r, http.MethodPatch) {
 		return
 	}
 	var winner Winner
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&winner); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if !validRequest(w, r, &winner, ErrInvalidPlayer) {
 		return
 	}
-	p.processWin(w, winner.ID)
+	p.processWin(w, r, winner.ID)
 }
 
 // GET
 func (p *PlayerServer) infoHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+	if !allowMethod(w, r, http.MethodGet) {
 		return
 	}
-	playerID, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/info/"))
+	value := strings.TrimPrefix(r.URL.Path, "/info/")
+	playerID, err := strconv.Atoi(value)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		badRequest(w, "must provide a valid id (int), got %q", value)
+		return
 	}
-	if _, err := fmt.Fprintf(w, "The player with id: %d has %d wins", playerID, p.store.GetPlayerScore(playerID)); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	wins, err := p.store.GetPlayerScore(r.Context(), playerID)
+	if err != nil {
+		storeError(w, err)
+		return
 	}
+	fmt.Fprintf(w, "The player with id: %d has %d wins", playerID, wins)
 }
 
-func (p *PlayerServer) processWin(w http.ResponseWriter, playerID int) {
-	if err := p.store.RecordWin(playerID); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+func (p *PlayerServer) processWin(w http.ResponseWriter, r *http.Request, playerID int) {
+	r, ok := p.ifMatch(w, r, playerID)
+	if !ok {
+		return
 	}
-	w.WriteHeader(http.StatusOK)
-	if _, err := fmt.Fprintf(w, "The player with id: %d has %d wins now", playerID, p.store.GetPlayerScore(playerID)); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := p.store.RecordWin(r.Context(), playerID); err != nil {
+		storeError(w, err)
+		return
+	}
+	wins, err := p.store.GetPlayerScore(r.Context(), playerID)
+	if err != nil {
+		storeError(w, err)
+		return
 	}
+	p.tagPlayer(w, r, playerID)
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "The player with id: %d has %d wins now", playerID, wins)
 }
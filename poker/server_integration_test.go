@@ -14,8 +14,11 @@ func TestRecordingWinsAndRetrievingThem(t *testing.T) {
 	assertNoError(t, err)
 
 	server := NewPlayerServer(store)
-	id := 0
-	server.ServeHTTP(httptest.NewRecorder(), newPlayerCreateRequest(id, "Test", 3))
+	created := httptest.NewRecorder()
+	server.ServeHTTP(created, newNamedPlayerCreateRequest("Test", 3))
+	assertStatus(t, created.Code, http.StatusCreated)
+	assertLocation(t, created, "/info/1")
+	id := 1
 
 	server.ServeHTTP(httptest.NewRecorder(), newPostWinRequest(id))
 	server.ServeHTTP(httptest.NewRecorder(), newPostWinRequest(id))
@@ -26,7 +29,7 @@ func TestRecordingWinsAndRetrievingThem(t *testing.T) {
 		server.ServeHTTP(response, newGetScoreRequest(id))
 		assertStatus(t, response.Code, http.StatusOK)
 
-		assertResponseBody(t, response.Body.String(), "The player with id: 0 has 6 wins")
+		assertResponseBody(t, response.Body.String(), "The player with id: 1 has 6 wins")
 	})
 
 	t.Run("get League", func(t *testing.T) {
@@ -36,8 +39,36 @@ func TestRecordingWinsAndRetrievingThem(t *testing.T) {
 
 		got := getLeagueFromResponse(t, response.Body)
 		want := []Player{
-			{0, "Test", 6},
+			{1, "Test", 6, nil},
 		}
 		assertLeague(t, got, want)
 	})
 }
+
+func TestStoreErrorsMapToStatusCodes(t *testing.T) {
+	database, cleanDatabase := createTempFile(t, `[
+		{"id": 1, "name": "Cleo", "wins": 10}]`)
+	defer cleanDatabase()
+	store, err := NewFileSystemPlayerStore(database)
+	assertNoError(t, err)
+	server := NewPlayerServer(store)
+
+	tests := []struct {
+		name           string
+		request        *http.Request
+		expectedStatus int
+	}{
+		{"duplicate id", newPlayerCreateRequest(1, "Chris", 0), http.StatusConflict},
+		{"duplicate name", newNamedPlayerCreateRequest("cleo", 0), http.StatusConflict},
+		{"player without a name", newPlayerCreateRequest(2, "", 0), http.StatusBadRequest},
+		{"win for a missing player", newPostWinRequest(2), http.StatusNotFound},
+		{"delete a missing player", newDeleteRequest(2), http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, tt.request)
+			assertStatus(t, response.Code, tt.expectedStatus)
+		})
+	}
+}
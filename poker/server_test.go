@@ -1,10 +1,13 @@
 package poker
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -28,7 +31,6 @@ func TestGetPlayers(t *testing.T) {
 	}{
 		{"returns player with ID:1 score", 1, http.StatusOK, "The player with id: 1 has 20 wins"},
 		{"returns player with ID:2 score", 2, http.StatusOK, "The player with id: 2 has 10 wins"},
-		{"returns 0 on missing players", 3, http.StatusOK, "The player with id: 3 has 0 wins"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -39,6 +41,12 @@ func TestGetPlayers(t *testing.T) {
 			assertResponseBody(t, response.Body.String(), tt.expectedBody)
 		})
 	}
+
+	t.Run("returns 404 on missing players", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGetScoreRequest(3))
+		assertProblem(t, response, http.StatusNotFound, CodePlayerNotFound)
+	})
 }
 
 func TestScoreWins(t *testing.T) {
@@ -81,13 +89,13 @@ func TestLeague(t *testing.T) {
 		expectedLeague []Player
 	}{
 		{"returns the league table as JSON", []Player{
-			{1, "Test1", 32},
-			{2, "Test2", 20},
-			{3, "Test3", 14},
+			{1, "Test1", 32, nil},
+			{2, "Test2", 20, nil},
+			{3, "Test3", 14, nil},
 		}, http.StatusOK, []Player{
-			{1, "Test1", 32},
-			{2, "Test2", 20},
-			{3, "Test3", 14}}},
+			{1, "Test1", 32, nil},
+			{2, "Test2", 20, nil},
+			{3, "Test3", 14, nil}}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -105,6 +113,53 @@ func TestLeague(t *testing.T) {
 	}
 }
 
+func TestLeagueScoring(t *testing.T) {
+	store := createTestDatabase(t)
+	assertNoError(t, store.AddPlayer(&Player{ID: 1, Name: "Cleo"}))
+	assertNoError(t, store.AddPlayer(&Player{ID: 2, Name: "Chris"}))
+	assertNoError(t, store.AddPlayer(&Player{ID: 3, Name: "Lloyd"}))
+	for _, order := range [][]int{{1, 3, 2}, {2, 3, 1}, {3, 1, 2}} {
+		game := &GameRecord{Participants: []Participant{{PlayerID: 1}, {PlayerID: 2}, {PlayerID: 3}}}
+		assertNoError(t, store.CreateGame(context.Background(), game))
+		assertNoError(t, store.RecordResult(context.Background(), game.ID, order))
+	}
+	server := NewPlayerServer(store, WithScoring(ScoringPresets["top3"].RuleSet()))
+	audited := NewPlayerServer(store, WithScoring(ScoringPresets["top3"].RuleSet()),
+		WithAuditLog(NewFileAuditLog(filepath.Join(t.TempDir(), "audit.jsonl"))))
+
+	for name, server := range map[string]*PlayerServer{"": server, " behind an audit log": audited} {
+		t.Run("ranks the league by points"+name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newLeagueRequest())
+			assertStatus(t, response.Code, http.StatusOK)
+
+			if got := response.Header().Get(scoringRulesHeader); got != "top3" {
+				t.Errorf("got rules %q want top3", got)
+			}
+			var standings Standings
+			if err := json.NewDecoder(response.Body).Decode(&standings); err != nil {
+				t.Fatalf("unable to parse standings from response %q, %v", response.Body, err)
+			}
+			// Lloyd: 6 + 6 + 10, Cleo: 10 + 3 + 6, Chris: 3 + 10 + 3.
+			var got []string
+			for _, standing := range standings {
+				got = append(got, fmt.Sprintf("%s %d", standing.Name, standing.Points))
+			}
+			if want := []string{"Lloyd 22", "Cleo 19", "Chris 16"}; !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v want %v", got, want)
+			}
+		})
+	}
+
+	t.Run("describes the rules", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest(http.MethodGet, "/league/rules", ""))
+		assertStatus(t, response.Code, http.StatusOK)
+		assertResponseBody(t, response.Body.String(),
+			`{"name":"top3","rules":["10/6/3 points by finishing position"],"tie_breakers":["more wins","more second places, then third places and so on","lower id"]}`+"\n")
+	})
+}
+
 func assertContentType(t testing.TB, response *httptest.ResponseRecorder, want string) {
 	t.Helper()
 	if response.Header().Get("content-type") != want {
@@ -159,9 +214,27 @@ func newPlayerCreateRequest(id int, name string, wins int) *http.Request {
 	return req
 }
 
+func newNamedPlayerCreateRequest(name string, wins int) *http.Request {
+	body := fmt.Sprintf(`{"name": "%s", "wins": %v}`, name, wins)
+	req, _ := http.NewRequest(http.MethodPost, "/create/", strings.NewReader(body))
+	return req
+}
+
+func assertLocation(t testing.TB, response *httptest.ResponseRecorder, want string) {
+	t.Helper()
+	if got := response.Header().Get("Location"); got != want {
+		t.Errorf("got Location %q want %q", got, want)
+	}
+}
+
 func assertResponseBody(t testing.TB, got, want string) {
 	t.Helper()
 	if got != want {
 		t.Errorf("response body is wrong, got %q want %q", got, want)
 	}
 }
+
+func newDeleteRequest(id int) *http.Request {
+	req, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("/delete/%d", id), nil)
+	return req
+}
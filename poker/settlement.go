@@ -0,0 +1,187 @@
+package poker
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Transfer is one payment of a settlement: From pays Amount to To.
+type Transfer struct {
+	From     int    `json:"from"`
+	FromName string `json:"from_name"`
+	To       int    `json:"to"`
+	ToName   string `json:"to_name"`
+	Amount   int    `json:"amount"`
+}
+
+// Settlement is how the players of a game square up after it.
+type Settlement struct {
+	GameID    int        `json:"game_id"`
+	Transfers []Transfer `json:"transfers"`
+}
+
+// maxExactSettle is the most players with money to move that Settle finds
+// the fewest transfers for. The search grows with 2^n, so bigger tables get
+// a greedy plan that may use a few transfers more.
+const maxExactSettle = 16
+
+// SettleGame works out who owes whom after game from the stakes of its
+// participants. Every player who lost money pays someone who won some, and
+// the plan uses as few transfers as possible.
+func SettleGame(game GameRecord) (Settlement, error) {
+	balances := make(map[int]int, len(game.Participants))
+	names := make(map[int]string, len(game.Participants))
+	for _, participant := range game.Participants {
+		balances[participant.PlayerID] = participant.Payout - participant.BuyIn - participant.Rebuys
+		names[participant.PlayerID] = participant.Name
+	}
+
+	transfers, err := Settle(balances)
+	if err != nil {
+		return Settlement{}, fmt.Errorf("%w: game %d cannot be settled, %v", ErrInvalidGame, game.ID, err)
+	}
+	for i := range transfers {
+		transfers[i].FromName = names[transfers[i].From]
+		transfers[i].ToName = names[transfers[i].To]
+	}
+	return Settlement{GameID: game.ID, Transfers: transfers}, nil
+}
+
+// Settle turns the balances of players, what each won (positive) or lost
+// (negative), into transfers from losers to winners. The balances have to add
+// up to zero.
+//
+// Every group of players whose balances add up to zero can settle among
+// themselves with one transfer fewer than there are players in it, so the
+// fewest transfers come from splitting the players into as many such groups
+// as possible.
+func Settle(balances map[int]int) ([]Transfer, error) {
+	var ids []int
+	total := 0
+	for id, balance := range balances {
+		total += balance
+		if balance != 0 {
+			ids = append(ids, id)
+		}
+	}
+	if total != 0 {
+		return nil, fmt.Errorf("payouts and buy-ins differ by %d", total)
+	}
+	sort.Ints(ids)
+
+	transfers := []Transfer{}
+	for _, group := range zeroSumGroups(ids, balances) {
+		transfers = append(transfers, settleGroup(group, balances)...)
+	}
+	return transfers, nil
+}
+
+// zeroSumGroups splits ids into as many groups with balances adding up to
+// zero as it can find.
+func zeroSumGroups(ids []int, balances map[int]int) [][]int {
+	n := len(ids)
+	if n > maxExactSettle {
+		return [][]int{ids}
+	}
+
+	// sums[mask] is the balance of the players in mask and groups[mask] the
+	// most zero-sum groups those players split into. Taking players out one
+	// at a time, every mask on the way that sums to zero closes a group.
+	sums := make([]int, 1<<n)
+	groups := make([]int, 1<<n)
+	removed := make([]int, 1<<n)
+	for mask := 1; mask < 1<<n; mask++ {
+		for i := 0; i < n; i++ {
+			if mask&(1<<i) != 0 {
+				sums[mask] = sums[mask&^(1<<i)] + balances[ids[i]]
+				break
+			}
+		}
+		for i := 0; i < n; i++ {
+			if mask&(1<<i) != 0 && (groups[mask&^(1<<i)] > groups[mask] || removed[mask] == 0) {
+				groups[mask], removed[mask] = groups[mask&^(1<<i)], 1<<i
+			}
+		}
+		if sums[mask] == 0 {
+			groups[mask]++
+		}
+	}
+
+	var result [][]int
+	var group []int
+	for mask := 1<<n - 1; mask != 0; {
+		if sums[mask] == 0 && len(group) > 0 {
+			result = append(result, group)
+			group = nil
+		}
+		bit := removed[mask]
+		for i := 0; i < n; i++ {
+			if bit == 1<<i {
+				group = append(group, ids[i])
+			}
+		}
+		mask &^= bit
+	}
+	if len(group) > 0 {
+		result = append(result, group)
+	}
+	return result
+}
+
+// settleGroup pays off a group whose balances add up to zero, always matching
+// the biggest debt with the biggest credit. It needs at most one transfer
+// fewer than there are players in the group.
+func settleGroup(ids []int, balances map[int]int) []Transfer {
+	type account struct{ id, amount int }
+	var debtors, creditors []account
+	for _, id := range ids {
+		if balances[id] < 0 {
+			debtors = append(debtors, account{id, -balances[id]})
+		} else if balances[id] > 0 {
+			creditors = append(creditors, account{id, balances[id]})
+		}
+	}
+	byAmount := func(accounts []account) {
+		sort.SliceStable(accounts, func(i, j int) bool {
+			if accounts[i].amount != accounts[j].amount {
+				return accounts[i].amount > accounts[j].amount
+			}
+			return accounts[i].id < accounts[j].id
+		})
+	}
+	byAmount(debtors)
+	byAmount(creditors)
+
+	var transfers []Transfer
+	for len(debtors) > 0 && len(creditors) > 0 {
+		debtor, creditor := &debtors[0], &creditors[0]
+		amount := min(debtor.amount, creditor.amount)
+		transfers = append(transfers, Transfer{From: debtor.id, To: creditor.id, Amount: amount})
+		debtor.amount -= amount
+		creditor.amount -= amount
+		if debtor.amount == 0 {
+			debtors = debtors[1:]
+		}
+		if creditor.amount == 0 {
+			creditors = creditors[1:]
+		}
+		byAmount(debtors)
+		byAmount(creditors)
+	}
+	return transfers
+}
+
+// PrintSettlement writes the transfers of settlement one per line.
+func PrintSettlement(out io.Writer, settlement Settlement) error {
+	if len(settlement.Transfers) == 0 {
+		_, err := fmt.Fprintf(out, "Game %d is square, nobody owes anything\n", settlement.GameID)
+		return err
+	}
+	for _, transfer := range settlement.Transfers {
+		if _, err := fmt.Fprintf(out, "%s pays %s %s\n", transfer.FromName, transfer.ToName, formatAmount(transfer.Amount)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package poker
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSettle(t *testing.T) {
+	tests := []struct {
+		name          string
+		balances      map[int]int
+		wantTransfers int
+	}{
+		{"nobody won or lost", map[int]int{1: 0, 2: 0}, 0},
+		{"one loser pays one winner", map[int]int{1: -10, 2: 10}, 1},
+		{"one loser pays two winners", map[int]int{1: -10, 2: 6, 3: 4}, 2},
+		{"pairs settle among themselves", map[int]int{1: -7, 2: 7, 3: -3, 4: 3}, 2},
+		// Matching the biggest debt with the biggest credit across the table
+		// takes five transfers here.
+		{"beats the greedy plan", map[int]int{1: -9, 2: 7, 3: -2, 4: 5, 5: 6, 6: -7}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transfers, err := Settle(tt.balances)
+			assertNoError(t, err)
+
+			if len(transfers) != tt.wantTransfers {
+				t.Errorf("got %d transfers want %d: %+v", len(transfers), tt.wantTransfers, transfers)
+			}
+			assertSettles(t, tt.balances, transfers)
+		})
+	}
+
+	t.Run("large tables still settle", func(t *testing.T) {
+		balances := map[int]int{}
+		for id := 1; id <= maxExactSettle+4; id++ {
+			balances[id] = id * 100
+			balances[-id] = -id * 100
+		}
+		transfers, err := Settle(balances)
+		assertNoError(t, err)
+		assertSettles(t, balances, transfers)
+	})
+
+	t.Run("rejects balances that do not add up", func(t *testing.T) {
+		_, err := Settle(map[int]int{1: -10, 2: 5})
+		assertError(t, err)
+	})
+}
+
+func TestSettleGame(t *testing.T) {
+	game := GameRecord{ID: 3, Participants: []Participant{
+		{PlayerID: 1, Name: "Cleo", BuyIn: 2000, Rebuys: 1000},
+		{PlayerID: 2, Name: "Chris", BuyIn: 2000, Payout: 5000},
+	}}
+
+	settlement, err := SettleGame(game)
+	assertNoError(t, err)
+
+	want := Settlement{GameID: 3, Transfers: []Transfer{{From: 1, FromName: "Cleo", To: 2, ToName: "Chris", Amount: 3000}}}
+	if !reflect.DeepEqual(settlement, want) {
+		t.Errorf("got %+v want %+v", settlement, want)
+	}
+
+	game.Participants[1].Payout = 4000
+	if _, err := SettleGame(game); !errors.Is(err, ErrInvalidGame) {
+		t.Errorf("got error %v want %v", err, ErrInvalidGame)
+	}
+}
+
+func TestPrintSettlement(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	settlement := Settlement{GameID: 3, Transfers: []Transfer{
+		{From: 1, FromName: "Cleo", To: 2, ToName: "Chris", Amount: 3000},
+		{From: 3, FromName: "Lloyd", To: 2, ToName: "Chris", Amount: 1050},
+	}}
+
+	assertNoError(t, PrintSettlement(buffer, settlement))
+	assertResponseBody(t, buffer.String(), "Cleo pays Chris 30.00\nLloyd pays Chris 10.50\n")
+
+	buffer.Reset()
+	assertNoError(t, PrintSettlement(buffer, Settlement{GameID: 4}))
+	assertResponseBody(t, buffer.String(), "Game 4 is square, nobody owes anything\n")
+}
+
+func assertSettles(t testing.TB, balances map[int]int, transfers []Transfer) {
+	t.Helper()
+	left := make(map[int]int, len(balances))
+	for id, balance := range balances {
+		left[id] = balance
+	}
+	for _, transfer := range transfers {
+		if transfer.Amount <= 0 {
+			t.Errorf("transfer %+v does not move money", transfer)
+		}
+		left[transfer.From] += transfer.Amount
+		left[transfer.To] -= transfer.Amount
+	}
+	for id, balance := range left {
+		if balance != 0 {
+			t.Errorf("player %d is left with %d after %+v", id, balance, transfers)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package poker
+
+import (
+	"application/migrations"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"modernc.org/sqlite"
+)
+
+// SQLitePlayerStore keeps the league in a single SQLite file. It shares its
+// queries with DatabaseStore and its schema with the Postgres migrations.
+type SQLitePlayerStore struct {
+	*DatabaseStore
+}
+
+// sqlitePragmas make writes wait for each other instead of failing with
+// SQLITE_BUSY, and turn on foreign keys which SQLite leaves off by default.
+const sqlitePragmas = "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate"
+
+// SQLite's own LOWER only folds ASCII letters, so "Élodie" would neither
+// match "élo" nor sort next to "élan", nor be taken by "ÉLODIE". The league
+// queries, FindByName, the duplicate name checks and the unique index on
+// players fold names with LOWER, so it is replaced by one that folds them
+// the way strings.ToLower does for the stores that keep players in memory.
+func init() {
+	sqlite.MustRegisterDeterministicScalarFunction("lower", 1, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		switch arg := args[0].(type) {
+		case string:
+			return strings.ToLower(arg), nil
+		case []byte:
+			return strings.ToLower(string(arg)), nil
+		}
+		return args[0], nil
+	})
+}
+
+func NewSQLitePlayerStore(path string) (*SQLitePlayerStore, error) {
+	db, err := sqlx.Open("sqlite", "file:"+path+sqlitePragmas)
+	if err != nil {
+		return nil, fmt.Errorf("problem opening %s, %v", path, err)
+	}
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if _, err := migrator.Up(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("problem migrating %s, %v", path, err)
+	}
+
+	return &SQLitePlayerStore{NewDatabaseStoreFromDB(db)}, nil
+}
@@ -0,0 +1,70 @@
+package poker
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLitePlayerStore(t *testing.T) {
+
+	t.Run("keeps the league between reopens", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "game.db")
+
+		store, err := NewSQLitePlayerStore(path)
+		assertNoError(t, err)
+		assertNoError(t, store.AddPlayer(&Player{1, "Cleo", 1, nil}))
+		assertNoError(t, store.RecordWin(1))
+		assertNoError(t, store.Close())
+
+		store, err = NewSQLitePlayerStore(path)
+		assertNoError(t, err)
+		defer store.Close()
+
+		assertLeague(t, store.GetLeague(), []Player{{1, "Cleo", 2, nil}})
+	})
+
+	t.Run("deleting and purging a player keeps their results", func(t *testing.T) {
+		store, err := NewSQLitePlayerStore(filepath.Join(t.TempDir(), "game.db"))
+		assertNoError(t, err)
+		defer store.Close()
+
+		assertNoError(t, store.AddPlayer(&Player{1, "Cleo", 0, nil}))
+		for i := 0; i < 3; i++ {
+			assertNoError(t, store.RecordWin(1))
+		}
+		assertNoError(t, store.DeletePlayer(1))
+
+		var results int
+		assertNoError(t, store.db.Get(&results, "SELECT COUNT(*) FROM game_results"))
+		if results != 3 {
+			t.Errorf("expected results to be kept, %d left", results)
+		}
+
+		purged, err := store.PurgePlayers(context.Background(), time.Now().Add(time.Minute))
+		assertNoError(t, err)
+		if purged != 1 {
+			t.Errorf("expected 1 player purged, got %d", purged)
+		}
+		assertNoError(t, store.db.Get(&results, "SELECT COUNT(*) FROM game_results WHERE winner_id = 1"))
+		if results != 3 {
+			t.Errorf("expected results to be kept, %d left", results)
+		}
+		var name string
+		assertNoError(t, store.db.Get(&name, "SELECT username FROM players WHERE id = 1"))
+		if name != "#purged-1" {
+			t.Errorf("expected the purged player's name dropped, got %q", name)
+		}
+
+		deleted, err := store.DeletedPlayers(context.Background())
+		assertNoError(t, err)
+		if len(deleted) != 0 {
+			t.Errorf("expected purged players gone from the deleted ones, got %+v", deleted)
+		}
+		_, err = store.RestorePlayer(context.Background(), 1)
+		assertErrorIs(t, err, ErrPlayerNotFound)
+		assertErrorIs(t, store.AddPlayer(&Player{ID: 1, Name: "Chris"}), ErrDuplicatePlayer)
+		assertNoError(t, store.AddPlayer(&Player{ID: 2, Name: "Cleo"}))
+	})
+}
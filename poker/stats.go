@@ -0,0 +1,139 @@
+package poker
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// PlayerStats sums up the finished games a player took part in. Wins come
+// from the same games, so they can differ from Player.Wins for stores that
+// carried wins over without a game.
+type PlayerStats struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	GamesPlayed int    `json:"games_played"`
+	Wins        int    `json:"wins"`
+	// WinRate is the percentage of games won.
+	WinRate float64 `json:"win_rate"`
+	// CurrentStreak counts the games won in a row up to the latest one.
+	CurrentStreak int `json:"current_streak"`
+	LongestStreak int `json:"longest_streak"`
+	// AveragePosition only counts the games the player was placed in, and
+	// is 0 when there are none.
+	AveragePosition float64      `json:"average_position"`
+	HeadToHead      []HeadToHead `json:"head_to_head"`
+}
+
+// HeadToHead is a player's record against one opponent over the games they
+// both played. Finishing ahead is a win, finishing level, as unplaced
+// players do, a draw.
+type HeadToHead struct {
+	OpponentID   int    `json:"opponent_id"`
+	OpponentName string `json:"opponent_name"`
+	Games        int    `json:"games"`
+	Wins         int    `json:"wins"`
+	Losses       int    `json:"losses"`
+	Draws        int    `json:"draws"`
+}
+
+// ComputeStats works out the stats of player from games. Games the player
+// did not take part in and games without a result are skipped, so games can
+// be the whole history. Head-to-head records are ordered by opponent id.
+func ComputeStats(player Player, games []GameRecord) PlayerStats {
+	played := make([]GameRecord, 0, len(games))
+	for _, game := range games {
+		if game.WinnerID != 0 && game.participant(player.ID) != nil {
+			played = append(played, game)
+		}
+	}
+	sort.SliceStable(played, func(i, j int) bool {
+		if !played[i].Date.Equal(played[j].Date) {
+			return played[i].Date.Before(played[j].Date)
+		}
+		return played[i].ID < played[j].ID
+	})
+
+	stats := PlayerStats{ID: player.ID, Name: player.Name, GamesPlayed: len(played), HeadToHead: []HeadToHead{}}
+	records := map[int]*HeadToHead{}
+	placed, positions := 0, 0
+	for _, game := range played {
+		if game.WinnerID == player.ID {
+			stats.Wins++
+			stats.CurrentStreak++
+			stats.LongestStreak = max(stats.LongestStreak, stats.CurrentStreak)
+		} else {
+			stats.CurrentStreak = 0
+		}
+
+		me := game.participant(player.ID)
+		if me.Position > 0 {
+			placed++
+			positions += me.Position
+		}
+		for _, opponent := range game.Participants {
+			if opponent.PlayerID == player.ID {
+				continue
+			}
+			record, ok := records[opponent.PlayerID]
+			if !ok {
+				record = &HeadToHead{OpponentID: opponent.PlayerID}
+				records[opponent.PlayerID] = record
+			}
+			record.OpponentName = opponent.Name
+			record.Games++
+			switch {
+			case me.place() < opponent.place():
+				record.Wins++
+			case me.place() > opponent.place():
+				record.Losses++
+			default:
+				record.Draws++
+			}
+		}
+	}
+
+	if stats.GamesPlayed > 0 {
+		stats.WinRate = round(100 * float64(stats.Wins) / float64(stats.GamesPlayed))
+	}
+	if placed > 0 {
+		stats.AveragePosition = math.Round(100*float64(positions)/float64(placed)) / 100
+	}
+	for _, record := range records {
+		stats.HeadToHead = append(stats.HeadToHead, *record)
+	}
+	sort.Slice(stats.HeadToHead, func(i, j int) bool {
+		return stats.HeadToHead[i].OpponentID < stats.HeadToHead[j].OpponentID
+	})
+	return stats
+}
+
+// Against returns the head-to-head record against opponent, which is empty
+// when the two never played each other.
+func (s PlayerStats) Against(opponent int) HeadToHead {
+	for _, record := range s.HeadToHead {
+		if record.OpponentID == opponent {
+			return record
+		}
+	}
+	return HeadToHead{OpponentID: opponent}
+}
+
+// GetPlayerStats loads the stats of a player from a store that keeps its
+// game history.
+func GetPlayerStats(ctx context.Context, store PlayerStoreV2, id int) (PlayerStats, error) {
+	games, ok := StoreFeature[GameStore](store)
+	if !ok {
+		return PlayerStats{}, fmt.Errorf("player stats are %w, it keeps no game history", ErrNotSupported)
+	}
+	player, err := store.GetPlayer(ctx, id)
+	if err != nil {
+		return PlayerStats{}, err
+	}
+	played, err := games.ListGames(ctx, GameFilter{PlayerID: id})
+	if err != nil {
+		return PlayerStats{}, err
+	}
+	return ComputeStats(player, played), nil
+}
@@ -0,0 +1,61 @@
+package poker
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// playersHandler serves the per player views:
+//
+//	GET  /players/{id}/stats      the player's PlayerStats, ?opponent={id}
+//	                              narrows the head-to-head down to one opponent
+//	GET  /players/{id}/wins       the player's wins, reverted ones included
+//	POST /players/{id}/wins/undo  revert the player's last win, see Reversion
+func (p *PlayerServer) playersHandler(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/players/"), "/"), "/")
+	view := strings.Join(parts[1:], "/")
+	if view != "stats" && view != "wins" && view != "wins/undo" {
+		notFound(w, r)
+		return
+	}
+	id, err := strconv.Atoi(parts[0])
+	if err != nil {
+		badRequest(w, "must provide a valid id (int)")
+		return
+	}
+
+	switch {
+	case view == "stats" && r.Method == http.MethodGet:
+		p.getPlayerStats(w, r, id)
+	case view == "wins" && r.Method == http.MethodGet:
+		p.listWins(w, r, id)
+	case view == "wins/undo" && r.Method == http.MethodPost:
+		p.undoLastWin(w, r, id)
+	case view == "wins/undo":
+		methodNotAllowed(w, r, http.MethodPost)
+	default:
+		methodNotAllowed(w, r, http.MethodGet)
+	}
+}
+
+func (p *PlayerServer) getPlayerStats(w http.ResponseWriter, r *http.Request, id int) {
+	if p.games == nil {
+		notSupported(w, "player stats need a store that keeps game history")
+		return
+	}
+	stats, err := GetPlayerStats(r.Context(), p.store, id)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	if value := r.URL.Query().Get("opponent"); value != "" {
+		opponent, err := strconv.Atoi(value)
+		if err != nil {
+			badRequest(w, "opponent must be a valid id (int)")
+			return
+		}
+		stats.HeadToHead = []HeadToHead{stats.Against(opponent)}
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
@@ -0,0 +1,152 @@
+package poker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestComputeStats(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, time.April, d, 20, 0, 0, 0, time.UTC) }
+	cleo := Player{ID: 1, Name: "Cleo", Wins: 3}
+	games := []GameRecord{
+		{ID: 4, Date: day(4), WinnerID: 1, Participants: []Participant{
+			{PlayerID: 1, Name: "Cleo", Position: 1}, {PlayerID: 2, Name: "Chris"},
+		}},
+		{ID: 1, Date: day(1), WinnerID: 1, Participants: []Participant{
+			{PlayerID: 1, Name: "Cleo", Position: 1}, {PlayerID: 2, Name: "Chris", Position: 2}, {PlayerID: 3, Name: "Lloyd", Position: 3},
+		}},
+		{ID: 2, Date: day(2), WinnerID: 1, Participants: []Participant{
+			{PlayerID: 1, Name: "Cleo", Position: 1}, {PlayerID: 3, Name: "Lloyd", Position: 2},
+		}},
+		{ID: 3, Date: day(3), WinnerID: 2, Participants: []Participant{
+			{PlayerID: 2, Name: "Chris", Position: 1}, {PlayerID: 3, Name: "Lloyd", Position: 2}, {PlayerID: 1, Name: "Cleo", Position: 3},
+		}},
+		{ID: 5, Date: day(5), Participants: []Participant{
+			{PlayerID: 1, Name: "Cleo"}, {PlayerID: 2, Name: "Chris"},
+		}},
+		{ID: 6, Date: day(6), WinnerID: 2, Participants: []Participant{
+			{PlayerID: 2, Name: "Chris", Position: 1}, {PlayerID: 3, Name: "Lloyd", Position: 2},
+		}},
+	}
+
+	got := ComputeStats(cleo, games)
+	want := PlayerStats{
+		ID:              1,
+		Name:            "Cleo",
+		GamesPlayed:     4,
+		Wins:            3,
+		WinRate:         75,
+		CurrentStreak:   1,
+		LongestStreak:   2,
+		AveragePosition: 1.5,
+		HeadToHead: []HeadToHead{
+			{OpponentID: 2, OpponentName: "Chris", Games: 3, Wins: 2, Losses: 1},
+			{OpponentID: 3, OpponentName: "Lloyd", Games: 3, Wins: 2, Losses: 1},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v want %+v", got, want)
+	}
+
+	t.Run("unplaced players draw", func(t *testing.T) {
+		record := ComputeStats(Player{ID: 2, Name: "Chris"}, games[:1]).Against(3)
+		if record != (HeadToHead{OpponentID: 3}) {
+			t.Errorf("expected no games against Lloyd, got %+v", record)
+		}
+		record = ComputeStats(Player{ID: 2, Name: "Chris"}, []GameRecord{{ID: 7, Date: day(7), WinnerID: 1, Participants: []Participant{
+			{PlayerID: 1, Position: 1}, {PlayerID: 2, Name: "Chris"}, {PlayerID: 3, Name: "Lloyd"},
+		}}}).Against(3)
+		if record.Draws != 1 || record.Games != 1 {
+			t.Errorf("expected a draw against Lloyd, got %+v", record)
+		}
+	})
+
+	t.Run("no games", func(t *testing.T) {
+		got := ComputeStats(cleo, nil)
+		if got.GamesPlayed != 0 || got.WinRate != 0 || got.AveragePosition != 0 || got.HeadToHead == nil {
+			t.Errorf("expected empty stats, got %+v", got)
+		}
+	})
+}
+
+func TestPlayerStatsEndpoint(t *testing.T) {
+	store := createTestDatabase(t)
+	assertNoError(t, store.AddPlayer(&Player{ID: 1, Name: "Cleo"}))
+	assertNoError(t, store.AddPlayer(&Player{ID: 2, Name: "Chris"}))
+	assertNoError(t, store.AddPlayer(&Player{ID: 3, Name: "Lloyd"}))
+	server := NewPlayerServer(store)
+
+	server.ServeHTTP(httptest.NewRecorder(), newGameRequest(http.MethodPost, "/games/", `{"participants": [1, 2, 3]}`))
+	server.ServeHTTP(httptest.NewRecorder(), newGameRequest(http.MethodPost, "/games/1/result", `{"finishing_order": [2, 1, 3]}`))
+	assertNoError(t, store.RecordWin(1))
+
+	t.Run("stats", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest(http.MethodGet, "/players/1/stats", ""))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		stats := getStatsFromResponse(t, response)
+		if stats.GamesPlayed != 2 || stats.Wins != 1 || stats.CurrentStreak != 1 || stats.AveragePosition != 1.5 {
+			t.Errorf("unexpected stats %+v", stats)
+		}
+		if len(stats.HeadToHead) != 2 {
+			t.Errorf("expected records against two opponents, got %+v", stats.HeadToHead)
+		}
+	})
+
+	t.Run("stats behind an audit log", func(t *testing.T) {
+		audited := NewPlayerServer(store, WithAuditLog(NewFileAuditLog(filepath.Join(t.TempDir(), "audit.jsonl"))))
+		response := httptest.NewRecorder()
+		audited.ServeHTTP(response, newGameRequest(http.MethodGet, "/players/1/stats", ""))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		if stats := getStatsFromResponse(t, response); stats.GamesPlayed != 2 {
+			t.Errorf("expected 2 games played, got %+v", stats)
+		}
+	})
+
+	t.Run("head-to-head against one opponent", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest(http.MethodGet, "/players/1/stats?opponent=2", ""))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		stats := getStatsFromResponse(t, response)
+		want := []HeadToHead{{OpponentID: 2, OpponentName: "Chris", Games: 1, Losses: 1}}
+		if !reflect.DeepEqual(stats.HeadToHead, want) {
+			t.Errorf("got %+v want %+v", stats.HeadToHead, want)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for path, status := range map[string]int{
+			"/players/9/stats":            http.StatusNotFound,
+			"/players/x/stats":            http.StatusBadRequest,
+			"/players/1/stats?opponent=x": http.StatusBadRequest,
+			"/players/1":                  http.StatusNotFound,
+		} {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newGameRequest(http.MethodGet, path, ""))
+			if response.Code != status {
+				t.Errorf("%s: got status %d want %d", path, response.Code, status)
+			}
+		}
+
+		response := httptest.NewRecorder()
+		NewPlayerServer(&StubPlayerStore{}).ServeHTTP(response, newGameRequest(http.MethodGet, "/players/1/stats", ""))
+		assertStatus(t, response.Code, http.StatusNotImplemented)
+	})
+}
+
+func getStatsFromResponse(t testing.TB, response *httptest.ResponseRecorder) PlayerStats {
+	t.Helper()
+	var stats PlayerStats
+	if err := json.NewDecoder(response.Body).Decode(&stats); err != nil {
+		t.Fatalf("unable to parse stats from response %q, %v", response.Body, err)
+	}
+	return stats
+}
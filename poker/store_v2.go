@@ -0,0 +1,158 @@
+package poker
+
+import (
+	"context"
+	"fmt"
+)
+
+// PlayerStoreV2 is PlayerStore with a context on every call and an error on
+// every result, so callers can cancel work and tell a missing player from a
+// failing store. Errors wrap ErrPlayerNotFound, ErrDuplicatePlayer and
+// ErrInvalidPlayer where they apply.
+type PlayerStoreV2 interface {
+	GetPlayer(ctx context.Context, id int) (Player, error)
+	GetPlayerScore(ctx context.Context, id int) (int, error)
+	RecordWin(ctx context.Context, id int) error
+	GetLeague(ctx context.Context) (League, error)
+	AddPlayer(ctx context.Context, player *Player) error
+	DeletePlayer(ctx context.Context, id int) error
+	FindByName(ctx context.Context, name string) (Player, error)
+}
+
+// AdaptPlayerStore returns store as a PlayerStoreV2. Stores with a V2 method
+// provide their own implementation; any other store is wrapped so that each
+// call checks the context before it runs.
+func AdaptPlayerStore(store PlayerStore) PlayerStoreV2 {
+	if native, ok := store.(interface{ V2() PlayerStoreV2 }); ok {
+		return native.V2()
+	}
+	return legacyStore{store}
+}
+
+// StoreFeature finds an optional store interface, like GameStore or
+// WinReverter, on store or on the store underneath it: the PlayerStore that
+// AdaptPlayerStore wrapped, or the store a decorator like AuditedStore
+// unwraps to.
+func StoreFeature[T any](store PlayerStoreV2) (T, bool) {
+	if feature, ok := store.(T); ok {
+		return feature, true
+	}
+	switch wrapper := store.(type) {
+	case legacyStore:
+		feature, ok := wrapper.store.(T)
+		return feature, ok
+	case interface{ Unwrap() PlayerStoreV2 }:
+		return StoreFeature[T](wrapper.Unwrap())
+	}
+	var none T
+	return none, false
+}
+
+// BindContext returns store as a PlayerStore whose calls all run with ctx.
+// It lets code written against PlayerStore, like the CLI, pass an actor and
+// a source on to an AuditedStore.
+func BindContext(ctx context.Context, store PlayerStoreV2) PlayerStore {
+	return boundStore{ctx: ctx, store: store}
+}
+
+type boundStore struct {
+	ctx   context.Context
+	store PlayerStoreV2
+}
+
+func (b boundStore) V2() PlayerStoreV2 {
+	return b.store
+}
+
+func (b boundStore) GetPlayerScore(id int) int {
+	wins, _ := b.store.GetPlayerScore(b.ctx, id)
+	return wins
+}
+
+func (b boundStore) RecordWin(id int) error {
+	return b.store.RecordWin(b.ctx, id)
+}
+
+func (b boundStore) GetLeague() League {
+	league, _ := b.store.GetLeague(b.ctx)
+	return league
+}
+
+func (b boundStore) AddPlayer(player *Player) error {
+	return b.store.AddPlayer(b.ctx, player)
+}
+
+func (b boundStore) DeletePlayer(id int) error {
+	return b.store.DeletePlayer(b.ctx, id)
+}
+
+func (b boundStore) FindByName(name string) (Player, error) {
+	return b.store.FindByName(b.ctx, name)
+}
+
+type legacyStore struct {
+	store PlayerStore
+}
+
+func (l legacyStore) GetPlayer(ctx context.Context, id int) (Player, error) {
+	if err := ctx.Err(); err != nil {
+		return Player{}, err
+	}
+	player := l.store.GetLeague().Find(id)
+	if player == nil {
+		return Player{}, fmt.Errorf("%w: no player with id %d", ErrPlayerNotFound, id)
+	}
+	return *player, nil
+}
+
+// GetPlayerScore looks the player up first, with the store's Find method if
+// it has one and in the league otherwise, as the PlayerStore it wraps returns
+// 0 for a missing player and one without wins alike.
+func (l legacyStore) GetPlayerScore(ctx context.Context, id int) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	found := l.store.GetLeague().Find(id) != nil
+	if finder, ok := l.store.(interface{ Find(id int) bool }); ok {
+		found = finder.Find(id)
+	}
+	if !found {
+		return 0, fmt.Errorf("%w: no player with id %d", ErrPlayerNotFound, id)
+	}
+	return l.store.GetPlayerScore(id), nil
+}
+
+func (l legacyStore) RecordWin(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return l.store.RecordWin(id)
+}
+
+func (l legacyStore) GetLeague(ctx context.Context) (League, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return l.store.GetLeague(), nil
+}
+
+func (l legacyStore) AddPlayer(ctx context.Context, player *Player) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return l.store.AddPlayer(player)
+}
+
+func (l legacyStore) DeletePlayer(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return l.store.DeletePlayer(id)
+}
+
+func (l legacyStore) FindByName(ctx context.Context, name string) (Player, error) {
+	if err := ctx.Err(); err != nil {
+		return Player{}, err
+	}
+	return l.store.FindByName(name)
+}
@@ -0,0 +1,76 @@
+package poker
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAdaptPlayerStore(t *testing.T) {
+
+	t.Run("uses the database store's own implementation", func(t *testing.T) {
+		store := createTestDatabase(t)
+
+		if _, ok := AdaptPlayerStore(store).(databaseStoreV2); !ok {
+			t.Errorf("expected the native database store, got %T", AdaptPlayerStore(store))
+		}
+	})
+
+	t.Run("does not call the store once the context is cancelled", func(t *testing.T) {
+		store := &StubPlayerStore{}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := AdaptPlayerStore(store).RecordWin(ctx, 1)
+
+		assertErrorIs(t, err, context.Canceled)
+		if len(store.WinCalls) != 0 {
+			t.Errorf("expected no calls to RecordWin, got %d", len(store.WinCalls))
+		}
+	})
+
+	t.Run("reports a missing player", func(t *testing.T) {
+		store := &StubPlayerStore{League: []Player{{1, "Cleo", 3, nil}}}
+
+		_, err := AdaptPlayerStore(store).GetPlayer(context.Background(), 2)
+
+		assertErrorIs(t, err, ErrPlayerNotFound)
+	})
+}
+
+func TestDatabaseStoreV2(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("score of a missing player is an error", func(t *testing.T) {
+		store := createTestDatabase(t).V2()
+
+		_, err := store.GetPlayerScore(ctx, 1)
+
+		assertErrorIs(t, err, ErrPlayerNotFound)
+	})
+
+	t.Run("duplicate player is an error", func(t *testing.T) {
+		store := createTestDatabase(t).V2()
+		assertNoError(t, store.AddPlayer(ctx, &Player{1, "Cleo", 0, nil}))
+
+		err := store.AddPlayer(ctx, &Player{1, "Chris", 0, nil})
+
+		assertErrorIs(t, err, ErrDuplicatePlayer)
+	})
+
+	t.Run("read errors are returned", func(t *testing.T) {
+		store := createTestDatabase(t)
+		store.Close()
+
+		_, err := store.V2().GetLeague(ctx)
+
+		assertError(t, err)
+	})
+}
+
+func assertErrorIs(t testing.TB, got, want error) {
+	t.Helper()
+	if !errors.Is(got, want) {
+		t.Errorf("got error %v, want %v", got, want)
+	}
+}
@@ -1,20 +1,95 @@
 package poker
 
 import (
-	"io"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
 )
 
+// tape replaces the whole file at path on every Write. The data goes to a
+// temporary file in the same directory, is synced and then renamed over the
+// original, so a crash leaves either the old or the new contents behind and
+// never a truncated file. With backup set the previous contents are kept in
+// path + ".bak".
 type tape struct {
-	file *os.File
+	path   string
+	backup bool
 }
 
 func (t *tape) Write(p []byte) (n int, err error) {
-	if err := t.file.Truncate(0); err != nil {
-		return 0, err
+	if t.backup {
+		if err := t.backupCurrent(); err != nil {
+			return 0, err
+		}
 	}
-	if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+	if err := writeFileAtomic(t.path, p); err != nil {
 		return 0, err
 	}
-	return t.file.Write(p)
+	return len(p), nil
+}
+
+func (t *tape) backupCurrent() error {
+	data, err := os.ReadFile(t.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("problem reading %s for backup, %v", t.path, err)
+	}
+	return writeFileAtomic(t.path+".bak", data)
+}
+
+func writeFileAtomic(path string, data []byte) (err error) {
+	dir, name := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+
+	perm := fs.FileMode(0666)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+name+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("problem creating temporary file for %s, %v", path, err)
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return fmt.Errorf("problem writing %s, %v", tmp.Name(), err)
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		return fmt.Errorf("problem setting permissions on %s, %v", tmp.Name(), err)
+	}
+	if err = tmp.Sync(); err != nil {
+		return fmt.Errorf("problem syncing %s, %v", tmp.Name(), err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("problem closing %s, %v", tmp.Name(), err)
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("problem replacing %s, %v", path, err)
+	}
+	return syncDir(dir)
+}
+
+// syncDir makes a rename in dir durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil && !errors.Is(err, os.ErrInvalid) {
+		return fmt.Errorf("problem syncing directory %s, %v", dir, err)
+	}
+	return nil
 }
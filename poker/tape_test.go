@@ -1,29 +1,48 @@
 package poker
 
 import (
-	"io"
+	"os"
 	"testing"
 )
 
 func TestTape_Write(t *testing.T) {
-	file, clean := createTempFile(t, "12345")
-	defer clean()
 
-	tape := &tape{file}
+	t.Run("replaces the file contents", func(t *testing.T) {
+		file, clean := createTempFile(t, "12345")
+		defer clean()
 
-	if _, err := tape.Write([]byte("abc")); err != nil {
-		t.Fatal(err)
-	}
+		tape := &tape{path: file.Name()}
 
-	if _, err := file.Seek(0, io.SeekStart); err != nil {
-		t.Fatal(err)
-	}
-	newFileContents, _ := io.ReadAll(file)
+		if _, err := tape.Write([]byte("abc")); err != nil {
+			t.Fatal(err)
+		}
+
+		assertFileContents(t, file.Name(), "abc")
+	})
+
+	t.Run("keeps the previous contents as a backup", func(t *testing.T) {
+		file, clean := createTempFile(t, "12345")
+		defer clean()
+		defer os.Remove(file.Name() + ".bak")
 
-	got := string(newFileContents)
-	want := "abc"
+		tape := &tape{path: file.Name(), backup: true}
 
-	if got != want {
+		if _, err := tape.Write([]byte("abc")); err != nil {
+			t.Fatal(err)
+		}
+
+		assertFileContents(t, file.Name(), "abc")
+		assertFileContents(t, file.Name()+".bak", "12345")
+	})
+}
+
+func assertFileContents(t testing.TB, path, want string) {
+	t.Helper()
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(contents); got != want {
 		t.Errorf("got %q want %q", got, want)
 	}
 }
@@ -6,6 +6,10 @@ import (
 	"time"
 )
 
+// StubPlayerStore is a PlayerStore for tests that keeps its players in League
+// and the scores of players it has no names for in Scores. It records the
+// ids it was asked to record wins for in WinCalls. Otherwise it behaves like
+// any other store, see pokertest.RunPlayerStoreConformance.
 type StubPlayerStore struct {
 	Scores   map[int]int
 	WinCalls []int
@@ -14,31 +18,84 @@ type StubPlayerStore struct {
 
 func (s *StubPlayerStore) Find(id int) bool {
 	_, ok := s.Scores[id]
-	return ok
+	return ok || League(s.League).Find(id) != nil
 }
 
 func (s *StubPlayerStore) DeletePlayer(id int) error {
+	if !s.Find(id) {
+		return fmt.Errorf("%w: no player with id %d", ErrPlayerNotFound, id)
+	}
 	delete(s.Scores, id)
+	for i, player := range s.League {
+		if player.ID == id {
+			s.League = append(s.League[:i:i], s.League[i+1:]...)
+			break
+		}
+	}
 	return nil
 }
 
 func (s *StubPlayerStore) GetPlayerScore(id int) int {
-	score := s.Scores[id]
-	return score
+	if score, ok := s.Scores[id]; ok {
+		return score
+	}
+	if player := League(s.League).Find(id); player != nil {
+		return player.Wins
+	}
+	return 0
 }
 
 func (s *StubPlayerStore) RecordWin(id int) error {
 	s.WinCalls = append(s.WinCalls, id)
+	if !s.Find(id) {
+		return fmt.Errorf("%w: no player with id %d", ErrPlayerNotFound, id)
+	}
+	if _, ok := s.Scores[id]; ok {
+		s.Scores[id]++
+	}
+	if player := League(s.League).Find(id); player != nil {
+		player.Wins++
+	}
 	return nil
 }
 
 func (s *StubPlayerStore) AddPlayer(player *Player) error {
+	if player.Name == "" {
+		return fmt.Errorf("%w: player name cannot be empty", ErrInvalidPlayer)
+	}
+	if err := League(s.League).checkNew(*player); err != nil {
+		return err
+	}
+	if _, ok := s.Scores[player.ID]; ok {
+		return fmt.Errorf("%w: player with id %d already exists", ErrDuplicatePlayer, player.ID)
+	}
+	if player.ID == 0 {
+		player.ID = League(s.League).nextID()
+		for id := range s.Scores {
+			player.ID = max(player.ID, id+1)
+		}
+	}
+	if s.Scores == nil {
+		s.Scores = map[int]int{}
+	}
 	s.Scores[player.ID] = player.Wins
+	s.League = append(s.League, Player{ID: player.ID, Name: player.Name, Wins: player.Wins})
 	return nil
 }
 
+func (s *StubPlayerStore) FindByName(name string) (Player, error) {
+	player := League(s.League).FindByName(name)
+	if player == nil {
+		return Player{}, fmt.Errorf("%w: no player named %q", ErrPlayerNotFound, name)
+	}
+	return *player, nil
+}
+
+// GetLeague returns a copy of League ordered by wins, then by id.
 func (s *StubPlayerStore) GetLeague() League {
-	return s.League
+	league := League(s.League).copy()
+	league.sortByWins()
+	return league
 }
 
 func AssertPlayerWin(t testing.TB, store *StubPlayerStore, winnerID int) {
@@ -1,7 +1,7 @@
 package poker
 
 import (
-	"errors"
+	"fmt"
 	"time"
 )
 
@@ -28,9 +28,9 @@ func (p *TexasHoldem) Start(numberOfPlayers int) {
 	}
 }
 
-func (p *TexasHoldem) Finish(id int) error {
-	if err := p.store.RecordWin(id); err != nil {
-		return errors.New("invalid player name provided")
+func (p *TexasHoldem) Finish(winnerID int) error {
+	if err := p.store.RecordWin(winnerID); err != nil {
+		return fmt.Errorf("problem recording win for player %d, %w", winnerID, err)
 	}
 	return nil
 }
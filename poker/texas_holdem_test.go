@@ -51,7 +51,7 @@ func TestGame_Start(t *testing.T) {
 }
 
 func TestGame_Finish(t *testing.T) {
-	store := &poker.StubPlayerStore{}
+	store := &poker.StubPlayerStore{Scores: map[int]int{1: 0}}
 	game := poker.NewTexasHoldem(dummyBlindAlerter, store)
 	winner := 1
 
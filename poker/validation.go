@@ -0,0 +1,191 @@
+package poker
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxPlayerID is the largest id a player can have, the largest value the
+// INTEGER id column of the SQL stores holds.
+const MaxPlayerID = math.MaxInt32
+
+// MaxNameLength is how many characters a player's name may have.
+const MaxNameLength = 64
+
+// PlayerInput is what clients send to add a player. ID is left out, or 0,
+// to have the store pick one; Wins is a starting count of wins. The bounds
+// in its tags are MaxPlayerID and MaxNameLength.
+type PlayerInput struct {
+	ID   int    `json:"id" validate:"min=0,max=2147483647"`
+	Name string `json:"name" validate:"normalize,required,max=64,charset=name"`
+	Wins int    `json:"wins" validate:"min=0"`
+}
+
+func (in PlayerInput) Player() Player {
+	return Player{ID: in.ID, Name: in.Name, Wins: in.Wins}
+}
+
+// FieldError tells what is wrong with one field of a request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists the fields of a request that break their rules. It
+// wraps the error of the kind of thing that was invalid, ErrInvalidPlayer
+// for example, so it is answered like a store would answer it.
+type ValidationError struct {
+	Err    error
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Field + " " + field.Message
+	}
+	return fmt.Sprintf("%v: %s", e.Err, strings.Join(messages, ", "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Validate checks v, a pointer to a struct, against the rules in the
+// validate tags of its fields and returns a *ValidationError wrapping kind
+// that lists every field breaking one. Fields are named by their json tag.
+// The rules are, in the order they are given:
+//
+//	normalize     NFC normalize a string, trim it and collapse runs of spaces
+//	required      a string must not be empty
+//	min=N, max=N  bounds on a number, or on the characters in a string
+//	charset=name  letters, digits, spaces and ' - . _ only
+//	oneof=A B C   a string must be one of the words listed
+//
+// Nil pointer fields are skipped, so optional fields of a patch are only
+// checked when they are given.
+func Validate(v interface{}, kind error) error {
+	value := reflect.ValueOf(v).Elem()
+	var fields []FieldError
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		rules, ok := field.Tag.Lookup("validate")
+		if !ok {
+			continue
+		}
+		target := value.Field(i)
+		if target.Kind() == reflect.Pointer {
+			if target.IsNil() {
+				continue
+			}
+			target = target.Elem()
+		}
+		if message := checkRules(target, rules); message != "" {
+			fields = append(fields, FieldError{Field: jsonName(field), Message: message})
+		}
+	}
+	if len(fields) > 0 {
+		return &ValidationError{Err: kind, Fields: fields}
+	}
+	return nil
+}
+
+// ValidateName normalizes a player's name and checks it against the rules
+// PlayerInput has for names.
+func ValidateName(name string) (string, error) {
+	input := PlayerInput{Name: name}
+	err := Validate(&input, ErrInvalidPlayer)
+	return input.Name, err
+}
+
+// checkRules applies rules to value and returns what is wrong with it, or ""
+// when nothing is. It panics on rules it does not know, as those are bugs in
+// a tag rather than bad input.
+func checkRules(value reflect.Value, rules string) string {
+	for _, rule := range strings.Split(rules, ",") {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "normalize":
+			value.SetString(NormalizeName(value.String()))
+		case "required":
+			if value.String() == "" {
+				return "must not be empty"
+			}
+		case "min", "max":
+			bound, err := strconv.Atoi(arg)
+			if err != nil {
+				panic(fmt.Sprintf("validate: bad bound in rule %q", rule))
+			}
+			if message := checkBound(value, name, bound); message != "" {
+				return message
+			}
+		case "charset":
+			if arg != "name" {
+				panic(fmt.Sprintf("validate: unknown charset in rule %q", rule))
+			}
+			if r, ok := firstInvalidNameRune(value.String()); ok {
+				return fmt.Sprintf("must not contain %q, only letters, digits, spaces and ' - . _", r)
+			}
+		case "oneof":
+			if words := strings.Fields(arg); !slices.Contains(words, value.String()) {
+				return "must be one of " + strings.Join(words, ", ")
+			}
+		default:
+			panic(fmt.Sprintf("validate: unknown rule %q", rule))
+		}
+	}
+	return ""
+}
+
+func checkBound(value reflect.Value, rule string, bound int) string {
+	size, unit := 0, ""
+	switch value.Kind() {
+	case reflect.Int, reflect.Int64:
+		size = int(value.Int())
+	case reflect.String:
+		size, unit = utf8.RuneCountInString(value.String()), " characters"
+	default:
+		panic(fmt.Sprintf("validate: %s does not apply to %s", rule, value.Kind()))
+	}
+	if rule == "min" && size < bound {
+		return fmt.Sprintf("must be at least %d%s", bound, unit)
+	}
+	if rule == "max" && size > bound {
+		return fmt.Sprintf("must be at most %d%s", bound, unit)
+	}
+	return ""
+}
+
+// NormalizeName puts a name in NFC, so names that look the same compare the
+// same, trims it and collapses runs of white space into one space.
+func NormalizeName(name string) string {
+	return strings.Join(strings.Fields(norm.NFC.String(name)), " ")
+}
+
+func firstInvalidNameRune(name string) (rune, bool) {
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r), unicode.IsMark(r), unicode.IsDigit(r), r == ' ':
+		case strings.ContainsRune("'-._", r):
+		default:
+			return r, true
+		}
+	}
+	return 0, false
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,121 @@
+package poker
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	t.Run("normalizes names", func(t *testing.T) {
+		for _, tt := range []struct {
+			name, want string
+		}{
+			{"  Cleo  ", "Cleo"},
+			{"Mary \t Ann", "Mary Ann"},
+			{"Zoe\u0301", "Zo\u00e9"},
+		} {
+			got, err := ValidateName(tt.name)
+			assertNoError(t, err)
+			if got != tt.want {
+				t.Errorf("got %q want %q", got, tt.want)
+			}
+		}
+	})
+
+	t.Run("lists every field that breaks a rule", func(t *testing.T) {
+		input := PlayerInput{ID: -1, Name: "   ", Wins: -2}
+		err := Validate(&input, ErrInvalidPlayer)
+		assertErrorIs(t, err, ErrInvalidPlayer)
+		var invalid *ValidationError
+		if !errors.As(err, &invalid) {
+			t.Fatalf("expected a validation error, got %v", err)
+		}
+		want := []FieldError{
+			{"id", "must be at least 0"},
+			{"name", "must not be empty"},
+			{"wins", "must be at least 0"},
+		}
+		if !reflect.DeepEqual(invalid.Fields, want) {
+			t.Errorf("got %+v want %+v", invalid.Fields, want)
+		}
+	})
+
+	t.Run("checks the charset and length of names", func(t *testing.T) {
+		for _, name := range []string{"Cleo<script>", "Cleo\x00", strings.Repeat("a", MaxNameLength+1)} {
+			if _, err := ValidateName(name); !errors.Is(err, ErrInvalidPlayer) {
+				t.Errorf("expected %q to be invalid, got %v", name, err)
+			}
+		}
+		for _, name := range []string{"O'Neil", "Jean-Luc", "Zoë", "李雷", strings.Repeat("a", MaxNameLength)} {
+			if _, err := ValidateName(name); err != nil {
+				t.Errorf("expected %q to be valid, got %v", name, err)
+			}
+		}
+	})
+
+	t.Run("skips patch fields that are left out", func(t *testing.T) {
+		assertNoError(t, Validate(&PlayerPatch{}, ErrInvalidPlayer))
+		name := " "
+		assertErrorIs(t, Validate(&PlayerPatch{Name: &name}, ErrInvalidPlayer), ErrInvalidPlayer)
+	})
+}
+
+func TestStrictRequests(t *testing.T) {
+	server := NewPlayerServer(createTestDatabase(t))
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest(method, path, body))
+		return response
+	}
+
+	t.Run("rejects fields players do not have", func(t *testing.T) {
+		response := serve(http.MethodPost, "/api/v1/players", `{"name": "Cleo", "deleted_at": "2024-01-01T00:00:00Z"}`)
+		assertProblem(t, response, http.StatusBadRequest, CodeBadRequest)
+	})
+
+	t.Run("rejects anything after the body", func(t *testing.T) {
+		response := serve(http.MethodPost, "/create/", `{"name": "Cleo"} {"name": "Chris"}`)
+		assertProblem(t, response, http.StatusBadRequest, CodeBadRequest)
+	})
+
+	t.Run("rejects bodies that are too large", func(t *testing.T) {
+		body := `{"name": "` + strings.Repeat("a", maxBodyBytes) + `"}`
+		response := serve(http.MethodPost, "/api/v1/players", body)
+		assertProblem(t, response, http.StatusRequestEntityTooLarge, CodePayloadTooLarge)
+	})
+
+	t.Run("answers invalid players with the fields at fault", func(t *testing.T) {
+		response := serve(http.MethodPost, "/create/", `{"id": 2147483648, "name": "Cleo", "wins": -1}`)
+		assertProblem(t, response, http.StatusBadRequest, CodeInvalidPlayer)
+
+		response = serve(http.MethodPost, "/create/", `{"name": "Cleo", "wins": -1}`)
+		var problem Problem
+		decodeBody(t, response, &problem)
+		if want := []FieldError{{"wins", "must be at least 0"}}; !reflect.DeepEqual(problem.Errors, want) {
+			t.Errorf("got fields %+v want %+v", problem.Errors, want)
+		}
+	})
+
+	t.Run("stores normalized names", func(t *testing.T) {
+		response := serve(http.MethodPost, "/api/v1/players", `{"name": "  Zoe\u0301 "}`)
+		assertStatus(t, response.Code, http.StatusCreated)
+		var player Player
+		decodeBody(t, response, &player)
+		if player.Name != "Zo\u00e9" {
+			t.Errorf("got name %q want %q", player.Name, "Zo\u00e9")
+		}
+		assertStatus(t, serve(http.MethodPatch, "/api/v1/players/1", `{"name": "Zoe!"}`).Code, http.StatusBadRequest)
+	})
+}
+
+func decodeBody(t testing.TB, response *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.NewDecoder(response.Body).Decode(v); err != nil {
+		t.Fatalf("unable to parse response %q, %v", response.Body, err)
+	}
+}
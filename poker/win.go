@@ -0,0 +1,91 @@
+package poker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Win is one win recorded for a player. A reverted win no longer counts
+// towards the league but stays in the player's history.
+type Win struct {
+	ID       int `json:"id"`
+	PlayerID int `json:"player_id"`
+	// GameID is the game the win was recorded for, if the store keeps
+	// games.
+	GameID   int        `json:"game_id,omitempty"`
+	At       time.Time  `json:"at"`
+	Reverted *Reversion `json:"reverted,omitempty"`
+}
+
+// Reversion records who took a win back and why. The store sets At.
+type Reversion struct {
+	At     time.Time `json:"at"`
+	By     string    `json:"by" validate:"normalize,required,max=64"`
+	Reason string    `json:"reason" validate:"required,max=500"`
+}
+
+// WinReverter is implemented by stores that keep a history of the wins they
+// record, so a win recorded against the wrong player can be taken back.
+// Wins a store was given as a starting count, like Player.Wins on
+// AddPlayer, are not in the history and cannot be reverted.
+//
+// Errors wrap ErrPlayerNotFound, ErrWinNotFound, ErrWinReverted and
+// ErrInvalidReversion where they apply.
+type WinReverter interface {
+	// UndoLastWin reverts the most recently recorded win of a player that
+	// has not been reverted yet.
+	UndoLastWin(ctx context.Context, playerID int, reversion Reversion) (Win, error)
+	// RevokeWin reverts the win with the given id.
+	RevokeWin(ctx context.Context, winID int, reversion Reversion) (Win, error)
+	// Wins lists a player's wins oldest first, reverted ones included.
+	Wins(ctx context.Context, playerID int) ([]Win, error)
+}
+
+func (r Reversion) check() error {
+	if strings.TrimSpace(r.By) == "" {
+		return fmt.Errorf("%w: say who is reverting the win", ErrInvalidReversion)
+	}
+	if strings.TrimSpace(r.Reason) == "" {
+		return fmt.Errorf("%w: give a reason for reverting the win", ErrInvalidReversion)
+	}
+	return nil
+}
+
+// lastWin returns the latest win in wins that still counts.
+func lastWin(wins []Win) (Win, bool) {
+	for i := len(wins) - 1; i >= 0; i-- {
+		if wins[i].Reverted == nil {
+			return wins[i], true
+		}
+	}
+	return Win{}, false
+}
+
+// revert marks win as reverted, failing if it already is.
+func (w Win) revert(reversion Reversion) (Win, error) {
+	if w.Reverted != nil {
+		return Win{}, fmt.Errorf("%w: win %d was reverted by %s", ErrWinReverted, w.ID, w.Reverted.By)
+	}
+	reversion.At = time.Now().UTC()
+	w.Reverted = &reversion
+	return w, nil
+}
+
+// PrintWins writes a player's wins as a table, reverted ones with who took
+// them back and why.
+func PrintWins(out io.Writer, wins []Win) error {
+	table := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tWon\tStatus")
+	for _, win := range wins {
+		status := "counts"
+		if win.Reverted != nil {
+			status = fmt.Sprintf("reverted by %s on %s: %s", win.Reverted.By, win.Reverted.At.Format(time.DateOnly), win.Reverted.Reason)
+		}
+		fmt.Fprintf(table, "%d\t%s\t%s\n", win.ID, win.At.Format(time.DateOnly), status)
+	}
+	return table.Flush()
+}
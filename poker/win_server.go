@@ -0,0 +1,75 @@
+package poker
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func (p *PlayerServer) listWins(w http.ResponseWriter, r *http.Request, playerID int) {
+	if p.wins == nil {
+		notSupported(w, "win history is not supported by this store")
+		return
+	}
+	wins, err := p.wins.Wins(r.Context(), playerID)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, wins)
+}
+
+func (p *PlayerServer) undoLastWin(w http.ResponseWriter, r *http.Request, playerID int) {
+	if p.wins == nil {
+		notSupported(w, "reverting wins is not supported by this store")
+		return
+	}
+	var reversion Reversion
+	if !validRequest(w, r, &reversion, ErrInvalidReversion) {
+		return
+	}
+	r, ok := p.ifMatch(w, r, playerID)
+	if !ok {
+		return
+	}
+	win, err := p.wins.UndoLastWin(r.Context(), playerID, reversion)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	p.tagPlayer(w, r, playerID)
+	writeJSON(w, http.StatusOK, win)
+}
+
+// winsHandler serves single wins:
+//
+//	POST /wins/{id}/revoke  revert a win, see Reversion
+func (p *PlayerServer) winsHandler(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/wins/"), "/"), "/")
+	if len(parts) != 2 || parts[1] != "revoke" {
+		notFound(w, r)
+		return
+	}
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	if p.wins == nil {
+		notSupported(w, "reverting wins is not supported by this store")
+		return
+	}
+	id, err := strconv.Atoi(parts[0])
+	if err != nil {
+		badRequest(w, "must provide a valid win id (int)")
+		return
+	}
+	var reversion Reversion
+	if !validRequest(w, r, &reversion, ErrInvalidReversion) {
+		return
+	}
+	win, err := p.wins.RevokeWin(r.Context(), id, reversion)
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, win)
+}
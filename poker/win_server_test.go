@@ -0,0 +1,96 @@
+package poker
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWinEndpoints(t *testing.T) {
+	store := createTestDatabase(t)
+	assertNoError(t, store.AddPlayer(&Player{ID: 1, Name: "Cleo"}))
+	assertNoError(t, store.AddPlayer(&Player{ID: 2, Name: "Chris"}))
+	server := NewPlayerServer(store)
+
+	server.ServeHTTP(httptest.NewRecorder(), newGameRequest(http.MethodPost, "/games/", `{"participants": [1, 2]}`))
+	server.ServeHTTP(httptest.NewRecorder(), newGameRequest(http.MethodPost, "/games/1/result", `{"finishing_order": [2, 1]}`))
+	assertNoError(t, store.RecordWin(2))
+
+	t.Run("undo the last win", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest(http.MethodPost, "/players/2/wins/undo", `{"by": "Cleo", "reason": "meant me"}`))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		win := getWinFromResponse(t, response)
+		if win.GameID != 2 || win.Reverted == nil || win.Reverted.By != "Cleo" {
+			t.Errorf("expected the win of game 2 to be reverted by Cleo, got %+v", win)
+		}
+		if got := store.GetPlayerScore(2); got != 1 {
+			t.Errorf("got %d wins want 1", got)
+		}
+	})
+
+	t.Run("revoke a game result", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest(http.MethodPost, "/wins/1/revoke", `{"by": "Cleo", "reason": "wrong order"}`))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		// The game is open again, so the corrected result can go in.
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest(http.MethodPost, "/games/1/result", `{"finishing_order": [1, 2]}`))
+		assertStatus(t, response.Code, http.StatusOK)
+		if got := store.GetPlayerScore(1); got != 1 {
+			t.Errorf("got %d wins want 1", got)
+		}
+	})
+
+	t.Run("history keeps reverted wins", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newGameRequest(http.MethodGet, "/players/2/wins", ""))
+		assertStatus(t, response.Code, http.StatusOK)
+
+		var wins []Win
+		if err := json.NewDecoder(response.Body).Decode(&wins); err != nil {
+			t.Fatalf("unable to parse wins from response %q, %v", response.Body, err)
+		}
+		if len(wins) != 2 || wins[0].Reverted == nil || wins[1].Reverted == nil {
+			t.Errorf("expected two reverted wins, got %+v", wins)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		cases := []struct {
+			method, path, body string
+			status             int
+		}{
+			{http.MethodPost, "/wins/1/revoke", `{"by": "Cleo", "reason": "again"}`, http.StatusConflict},
+			{http.MethodPost, "/wins/99/revoke", `{"by": "Cleo", "reason": "typo"}`, http.StatusNotFound},
+			{http.MethodPost, "/players/2/wins/undo", `{"by": "Cleo", "reason": "no wins left"}`, http.StatusNotFound},
+			{http.MethodPost, "/players/1/wins/undo", `{"by": "Cleo"}`, http.StatusBadRequest},
+			{http.MethodPost, "/wins/x/revoke", `{}`, http.StatusBadRequest},
+			{http.MethodGet, "/wins/1/revoke", "", http.StatusMethodNotAllowed},
+			{http.MethodGet, "/players/9/wins", "", http.StatusNotFound},
+		}
+		for _, c := range cases {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newGameRequest(c.method, c.path, c.body))
+			if response.Code != c.status {
+				t.Errorf("%s %s: got status %d want %d", c.method, c.path, response.Code, c.status)
+			}
+		}
+
+		response := httptest.NewRecorder()
+		NewPlayerServer(&StubPlayerStore{}).ServeHTTP(response, newGameRequest(http.MethodGet, "/players/1/wins", ""))
+		assertStatus(t, response.Code, http.StatusNotImplemented)
+	})
+}
+
+func getWinFromResponse(t testing.TB, response *httptest.ResponseRecorder) Win {
+	t.Helper()
+	var win Win
+	if err := json.NewDecoder(response.Body).Decode(&win); err != nil {
+		t.Fatalf("unable to parse win from response %q, %v", response.Body, err)
+	}
+	return win
+}